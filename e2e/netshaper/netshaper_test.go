@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/celestiaorg/knuu/pkg/instance"
 	"github.com/celestiaorg/knuu/pkg/sidecars/netshaper"
 )
 
@@ -395,6 +396,80 @@ func (s *Suite) TestNetShaperJitter() {
 	}
 }
 
+func (s *Suite) TestNetShaperLatencyTo() {
+	const (
+		namePrefix       = "ntshp-latto"
+		numOfPingPackets = 50
+		gopingPort       = 8001
+		packetTimeout    = 1 * time.Second
+		targetLatency    = 300 * time.Millisecond
+		tolerancePercent = 40
+	)
+	ctx := context.Background()
+
+	mother, err := s.Knuu.NewInstance(namePrefix + "mother")
+	s.Require().NoError(err)
+
+	err = mother.Build().SetImage(ctx, gopingImage)
+	s.Require().NoError(err)
+
+	s.Require().NoError(mother.Network().AddPortTCP(gopingPort))
+	s.Require().NoError(mother.Build().Commit(ctx))
+
+	err = mother.Build().SetEnvironmentVariable("SERVE_ADDR", fmt.Sprintf("0.0.0.0:%d", gopingPort))
+	s.Require().NoError(err)
+
+	target, err := mother.CloneWithName(namePrefix + "target")
+	s.Require().NoError(err)
+
+	btSidecar := netshaper.New()
+	s.Require().NoError(target.Sidecars().Add(ctx, btSidecar))
+
+	far, err := mother.CloneWithName(namePrefix + "far")
+	s.Require().NoError(err)
+	near, err := mother.CloneWithName(namePrefix + "near")
+	s.Require().NoError(err)
+
+	s.Require().NoError(target.Execution().Start(ctx))
+	s.Require().NoError(btSidecar.WaitForStart(ctx))
+	s.Require().NoError(far.Execution().Start(ctx))
+	s.Require().NoError(near.Execution().Start(ctx))
+
+	// only the responses of the target to the far executor are delayed
+	s.Require().NoError(btSidecar.SetLatencyTo(ctx, far, targetLatency.Milliseconds(), 0))
+
+	targetIP, err := target.Network().GetEphemeralIP(ctx)
+	s.Require().NoError(err)
+	targetAddress := fmt.Sprintf("%s:%d", targetIP, gopingPort)
+
+	ping := func(executor *instance.Instance) time.Duration {
+		output, err := executor.Execution().ExecuteCommand(ctx,
+			"goping", "ping", "-q",
+			"-c", fmt.Sprint(numOfPingPackets),
+			"-t", (packetTimeout + targetLatency).String(),
+			"-m", "latency",
+			targetAddress)
+		s.Require().NoError(err)
+
+		gotLatency, err := time.ParseDuration(output)
+		s.Require().NoError(err)
+		return gotLatency
+	}
+
+	s.T().Log("Starting latency test. It takes a while.")
+	farLatency := ping(far)
+	deviationPercent := math.Abs(float64(farLatency-targetLatency)/float64(targetLatency)) * 100
+	s.Assert().LessOrEqual(deviationPercent, float64(tolerancePercent), "Deviation is too high")
+
+	nearLatency := ping(near)
+	s.Assert().Less(nearLatency, targetLatency/2, "Latency to the other destinations should not be shaped")
+
+	s.T().Logf("Latency expected: %v \tfar: %v \tnear: %v", targetLatency, farLatency, nearLatency)
+
+	s.Require().NoError(btSidecar.ClearDestinations(ctx))
+	s.Assert().Empty(btSidecar.DestinationProfiles())
+}
+
 func formatBandwidth(bandwidth float64) string {
 	units := []string{"bps", "Kbps", "Mbps", "Gbps"}
 	if bandwidth < 0 {
@@ -4,7 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
+	"io"
 )
 
 type Builder interface {
@@ -17,6 +17,19 @@ type BuilderOptions struct {
 	Args         []ArgInterface
 	Destination  string
 	Cache        *CacheOptions
+	Registry     *Registry // Registry of the destination, nil means the default registry
+	// RegistryCredentials are the credentials of other registries, e.g. to pull private base images
+	RegistryCredentials []*Registry
+	// Dockerfile is the path of the Dockerfile relative to the build context, empty means the Dockerfile at its root
+	Dockerfile string
+	// Target is the stage of a multi-stage Dockerfile to build, empty means the last stage
+	Target string
+	// Platforms the image is built for, e.g. linux/arm64
+	// Several platforms produce a manifest list if the builder supports it, empty means the default of the builder
+	Platforms []string
+	// Output receives the output of the build while it runs, if set
+	// The complete output is still returned by Build once it is done
+	Output io.Writer
 }
 
 type CacheOptions struct {
@@ -25,7 +38,14 @@ type CacheOptions struct {
 	Repo    string
 }
 
+// Default returns the cache options for the given build context, the cache is pushed to the default registry
 func (c *CacheOptions) Default(buildContext string) (*CacheOptions, error) {
+	return c.DefaultWithRegistry(buildContext, DefaultRegistry())
+}
+
+// DefaultWithRegistry returns the cache options for the given build context, the cache is pushed to the given registry
+// A nil registry is the default registry
+func (c *CacheOptions) DefaultWithRegistry(buildContext string, registry *Registry) (*CacheOptions, error) {
 	if buildContext == "" {
 		return nil, ErrBuildContextEmpty
 	}
@@ -38,13 +58,20 @@ func (c *CacheOptions) Default(buildContext string) (*CacheOptions, error) {
 	return &CacheOptions{
 		Enabled: true,
 		Dir:     "",
-		// ttl.sh with the hash of build context is used as the cache repo
+		// the registry with the hash of build context is used as the cache repo
 		// Kaniko adds a string tag to the image name, so we don't need to add it here
-		Repo: fmt.Sprintf("ttl.sh/%s:24h", ctxHash),
+		Repo: registry.ImageName(ctxHash),
 	}, nil
 }
 
+// DefaultImageName returns the name of the image built from the given build context in the default registry
 func DefaultImageName(buildContext string) (string, error) {
+	return DefaultImageNameWithRegistry(buildContext, DefaultRegistry())
+}
+
+// DefaultImageNameWithRegistry returns the name of the image built from the given build context in the given registry
+// A nil registry is the default registry
+func DefaultImageNameWithRegistry(buildContext string, registry *Registry) (string, error) {
 	if buildContext == "" {
 		return "", ErrBuildContextEmpty
 	}
@@ -54,7 +81,7 @@ func DefaultImageName(buildContext string) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("ttl.sh/%s:24h", ctxHash), nil
+	return registry.ImageName(ctxHash), nil
 }
 
 func hashString(s string) (string, error) {
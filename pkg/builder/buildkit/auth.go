@@ -0,0 +1,54 @@
+package buildkit
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/session/auth"
+	"google.golang.org/grpc"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+const (
+	dockerHubHost         = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+// authProvider shares the credentials of the registries with buildkitd over the build session.
+// Only the credentials are implemented, buildkitd falls back to them for the token requests.
+type authProvider struct {
+	auth.UnimplementedAuthServer
+	credentials map[string]*builder.RegistryAuth
+}
+
+// newAuthProvider returns an auth provider for the registries with credentials, or nil if none has credentials
+func newAuthProvider(registries ...*builder.Registry) *authProvider {
+	credentials := map[string]*builder.RegistryAuth{}
+	for _, r := range registries {
+		if r == nil || r.Auth == nil {
+			continue
+		}
+		host := r.Host
+		if host == dockerHubHost {
+			host = dockerHubRegistryHost
+		}
+		credentials[host] = r.Auth
+	}
+	if len(credentials) == 0 {
+		return nil
+	}
+	return &authProvider{credentials: credentials}
+}
+
+func (ap *authProvider) Register(server *grpc.Server) {
+	auth.RegisterAuthServer(server, ap)
+}
+
+func (ap *authProvider) Credentials(_ context.Context, req *auth.CredentialsRequest) (*auth.CredentialsResponse, error) {
+	creds, ok := ap.credentials[req.Host]
+	if !ok {
+		// anonymous access
+		return &auth.CredentialsResponse{}, nil
+	}
+	return &auth.CredentialsResponse{Username: creds.Username, Secret: creds.Password}, nil
+}
@@ -0,0 +1,246 @@
+// Package buildkit implements a builder that talks directly to a BuildKit daemon,
+// so images can be built without a Docker daemon or a cluster.
+// Any buildkitd works, including a rootless one or `buildctl`-compatible daemons
+// started from the moby/buildkit image (e.g. docker-container://buildkitd).
+package buildkit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/sirupsen/logrus"
+	"github.com/tonistiigi/fsutil"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+const (
+	// DefaultAddress is the default socket of buildkitd
+	DefaultAddress = "unix:///run/buildkit/buildkitd.sock"
+	// AddressEnv is the environment variable used by buildctl to set the address of buildkitd
+	AddressEnv = "BUILDKIT_HOST"
+
+	dockerfileFrontend = "dockerfile.v0"
+	contextLocalName   = "context"
+	dockerfileName     = "dockerfile"
+	buildArgPrefix     = "build-arg:"
+	httpsProtocol      = "https://"
+	gitSuffix          = ".git"
+	cacheTypeRegistry  = "registry"
+	cacheTypeLocal     = "local"
+	insecureAttr       = "registry.insecure"
+	platformAttr       = "platform"
+	filenameAttr       = "filename"
+	targetAttr         = "target"
+)
+
+type BuildKit struct {
+	// Address of the buildkitd daemon, e.g. unix:///run/buildkit/buildkitd.sock or tcp://buildkitd:1234
+	// If empty, BUILDKIT_HOST is used and then DefaultAddress
+	Address string
+	// Platform to build the image for, e.g. linux/amd64, the Platforms of the builder options take precedence
+	// If both are empty, the platform of the buildkit worker is used
+	Platform string
+	// Logger is used for the debug logs of the builds, the standard logger of logrus if nil
+	Logger *logrus.Logger
+}
+
+var _ builder.Builder = &BuildKit{}
+
+func (b *BuildKit) Build(ctx context.Context, opts *builder.BuilderOptions) (logs string, err error) {
+	solveOpt, err := b.prepareSolveOpt(opts)
+	if err != nil {
+		return "", ErrPreparingSolveOptions.Wrap(err)
+	}
+
+	address := b.address()
+	c, err := bkclient.New(ctx, address)
+	if err != nil {
+		return "", ErrConnectingToBuildKit.WithParams(address).Wrap(err)
+	}
+	defer c.Close()
+
+	logger := b.logger().WithField("destination", opts.Destination)
+	logger.Debug("building image with buildkit")
+
+	var (
+		buf    bytes.Buffer
+		wg     sync.WaitGroup
+		status = make(chan *bkclient.SolveStatus)
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for s := range status {
+			for _, l := range s.Logs {
+				buf.Write(l.Data)
+				if opts.Output != nil {
+					_, _ = opts.Output.Write(l.Data)
+				}
+			}
+		}
+	}()
+
+	// Solve closes the status channel when it returns
+	_, err = c.Solve(ctx, nil, *solveOpt, status)
+	wg.Wait()
+	if err != nil {
+		return buf.String(), ErrBuildFailed.WithParams(opts.Destination).Wrap(err)
+	}
+
+	logger.Debug("built and pushed image with buildkit")
+	return buf.String(), nil
+}
+
+func (b *BuildKit) logger() *logrus.Logger {
+	if b.Logger != nil {
+		return b.Logger
+	}
+	return logrus.StandardLogger()
+}
+
+func (b *BuildKit) address() string {
+	if b.Address != "" {
+		return b.Address
+	}
+	if addr := os.Getenv(AddressEnv); addr != "" {
+		return addr
+	}
+	return DefaultAddress
+}
+
+func (b *BuildKit) prepareSolveOpt(opts *builder.BuilderOptions) (*bkclient.SolveOpt, error) {
+	frontendAttrs := map[string]string{}
+	switch {
+	case len(opts.Platforms) != 0:
+		if err := builder.ValidatePlatforms(opts.Platforms); err != nil {
+			return nil, err
+		}
+		// several platforms are exported as a manifest list
+		frontendAttrs[platformAttr] = strings.Join(opts.Platforms, ",")
+	case b.Platform != "":
+		frontendAttrs[platformAttr] = b.Platform
+	}
+
+	solveOpt := &bkclient.SolveOpt{
+		Frontend:      dockerfileFrontend,
+		FrontendAttrs: frontendAttrs,
+		Exports: []bkclient.ExportEntry{
+			{
+				Type: bkclient.ExporterImage,
+				Attrs: map[string]string{
+					"name": opts.Destination,
+					"push": "true",
+				},
+			},
+		},
+	}
+
+	insecure := opts.Registry != nil && opts.Registry.Insecure
+	if insecure {
+		solveOpt.Exports[0].Attrs[insecureAttr] = "true"
+	}
+
+	registries := append([]*builder.Registry{opts.Registry}, opts.RegistryCredentials...)
+	if ap := newAuthProvider(registries...); ap != nil {
+		solveOpt.Session = append(solveOpt.Session, ap)
+	}
+
+	switch {
+	case builder.IsDirContext(opts.BuildContext):
+		dir := builder.GetDirFromBuildContext(opts.BuildContext)
+		contextFS, err := fsutil.NewFS(dir)
+		if err != nil {
+			return nil, ErrOpeningContextDir.WithParams(dir).Wrap(err)
+		}
+		dockerfileFS := contextFS
+		if dockerfileDir := filepath.Dir(opts.Dockerfile); opts.Dockerfile != "" && dockerfileDir != "." {
+			dockerfileFS, err = fsutil.NewFS(filepath.Join(dir, dockerfileDir))
+			if err != nil {
+				return nil, ErrOpeningContextDir.WithParams(dockerfileDir).Wrap(err)
+			}
+		}
+		solveOpt.LocalMounts = map[string]fsutil.FS{
+			contextLocalName: contextFS,
+			dockerfileName:   dockerfileFS,
+		}
+	case builder.IsGitContext(opts.BuildContext):
+		frontendAttrs[contextLocalName] = gitContextURL(opts.BuildContext)
+	default:
+		return nil, ErrUnsupportedBuildContext.WithParams(opts.BuildContext)
+	}
+
+	if opts.Dockerfile != "" {
+		// the dockerfile local mount is the directory of the Dockerfile, a git context holds the full path
+		filename := opts.Dockerfile
+		if builder.IsDirContext(opts.BuildContext) {
+			filename = filepath.Base(opts.Dockerfile)
+		}
+		frontendAttrs[filenameAttr] = filename
+	}
+	if opts.Target != "" {
+		frontendAttrs[targetAttr] = opts.Target
+	}
+
+	for _, a := range opts.Args {
+		if _, ok := a.(*builder.BuildArg); !ok {
+			return nil, ErrUnsupportedArg.WithParams(a.GetKey())
+		}
+		key, value, ok := strings.Cut(a.GetValue(), "=")
+		if !ok || key == "" {
+			return nil, ErrInvalidBuildArg.WithParams(a.GetValue())
+		}
+		frontendAttrs[buildArgPrefix+key] = value
+	}
+
+	if opts.Cache != nil && opts.Cache.Enabled {
+		if opts.Cache.Repo != "" {
+			importAttrs := map[string]string{"ref": opts.Cache.Repo}
+			exportAttrs := map[string]string{"ref": opts.Cache.Repo, "mode": "max"}
+			if insecure {
+				importAttrs[insecureAttr] = "true"
+				exportAttrs[insecureAttr] = "true"
+			}
+			solveOpt.CacheImports = append(solveOpt.CacheImports, bkclient.CacheOptionsEntry{
+				Type:  cacheTypeRegistry,
+				Attrs: importAttrs,
+			})
+			solveOpt.CacheExports = append(solveOpt.CacheExports, bkclient.CacheOptionsEntry{
+				Type:  cacheTypeRegistry,
+				Attrs: exportAttrs,
+			})
+		}
+		if opts.Cache.Dir != "" {
+			solveOpt.CacheImports = append(solveOpt.CacheImports, bkclient.CacheOptionsEntry{
+				Type:  cacheTypeLocal,
+				Attrs: map[string]string{"src": opts.Cache.Dir},
+			})
+			solveOpt.CacheExports = append(solveOpt.CacheExports, bkclient.CacheOptionsEntry{
+				Type:  cacheTypeLocal,
+				Attrs: map[string]string{"dest": opts.Cache.Dir, "mode": "max"},
+			})
+		}
+	}
+
+	return solveOpt, nil
+}
+
+// gitContextURL converts a knuu git build context (git://[user[:pass]@]repo[#ref]),
+// which follows the kaniko pattern, to the git URL expected by the dockerfile frontend
+func gitContextURL(buildContext string) string {
+	repo, ref, hasRef := strings.Cut(strings.TrimPrefix(buildContext, "git://"), "#")
+	url := httpsProtocol + repo + gitSuffix
+	if hasRef {
+		// kaniko allows a branch and a commit, the commit is more specific
+		if idx := strings.LastIndex(ref, "#"); idx >= 0 {
+			ref = ref[idx+1:]
+		}
+		url += "#" + ref
+	}
+	return url
+}
@@ -0,0 +1,17 @@
+package buildkit
+
+import (
+	"github.com/celestiaorg/knuu/pkg/errors"
+)
+
+type Error = errors.Error
+
+var (
+	ErrConnectingToBuildKit    = errors.New("ConnectingToBuildKit", "failed to connect to buildkit at %s")
+	ErrBuildFailed             = errors.New("BuildFailed", "failed to build image %s")
+	ErrPreparingSolveOptions   = errors.New("PreparingSolveOptions", "failed to prepare the buildkit solve options")
+	ErrUnsupportedBuildContext = errors.New("UnsupportedBuildContext", "unsupported build context %s, only dir and git contexts are supported")
+	ErrInvalidBuildArg         = errors.New("InvalidBuildArg", "invalid build arg %s, it must be in the form KEY=VALUE")
+	ErrUnsupportedArg          = errors.New("UnsupportedArg", "argument %s is not supported by the buildkit builder")
+	ErrOpeningContextDir       = errors.New("OpeningContextDir", "failed to open the build context directory %s")
+)
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
@@ -37,7 +40,7 @@ func (d *Docker) Build(_ context.Context, b *builder.BuilderOptions) (logs strin
 	// If no builder instance exists, create a new one
 	if !strings.Contains(string(output), "default") {
 		cmd = exec.Command("docker", "buildx", "create", "--use")
-		if _, err := runCommand(cmd); err != nil {
+		if _, err := runCommand(cmd, nil); err != nil {
 			return "", ErrFailedToCreateBuilder.Wrap(err)
 		}
 		logrus.Debug("created new docker builder instance")
@@ -47,9 +50,31 @@ func (d *Docker) Build(_ context.Context, b *builder.BuilderOptions) (logs strin
 
 	buildContext := builder.GetDirFromBuildContext(b.BuildContext)
 
+	platforms := b.Platforms
+	if len(platforms) == 0 {
+		platforms = []string{builder.DefaultPlatform}
+	}
+	if err := builder.ValidatePlatforms(platforms); err != nil {
+		return "", err
+	}
+
+	// A manifest list cannot be loaded into the local image store, so it is pushed directly by buildx
+	multiPlatform := len(platforms) > 1
+	outputFlag := "--load"
+	if multiPlatform {
+		outputFlag = "--push"
+	}
+
 	// Since in docker the image name and destination must be the same, we just use the destination as the image name
-	cmd = exec.Command("docker", "buildx", "build", "--load", "--platform", "linux/amd64", "-t", b.Destination, buildContext)
-	cmdLogs, err := runCommand(cmd)
+	args := []string{"buildx", "build", outputFlag, "--platform", strings.Join(platforms, ","), "-t", b.Destination}
+	if b.Dockerfile != "" {
+		args = append(args, "--file", filepath.Join(buildContext, b.Dockerfile))
+	}
+	if b.Target != "" {
+		args = append(args, "--target", b.Target)
+	}
+	cmd = exec.Command("docker", append(args, buildContext)...)
+	cmdLogs, err := runCommand(cmd, b.Output)
 	if err != nil {
 		return "", ErrFailedToBuildImage.Wrap(err)
 	}
@@ -57,14 +82,16 @@ func (d *Docker) Build(_ context.Context, b *builder.BuilderOptions) (logs strin
 	logrus.Debug("built docker image: ", b.Destination)
 	logrus.Debug("logs: ", cmdLogs)
 
-	cmd = exec.Command("docker", "push", b.Destination)
-	cmdLogs, err = runCommand(cmd)
-	if err != nil {
-		return "", ErrFailedToPushImage.Wrap(err)
+	if !multiPlatform {
+		cmd = exec.Command("docker", "push", b.Destination)
+		cmdLogs, err = runCommand(cmd, b.Output)
+		if err != nil {
+			return "", ErrFailedToPushImage.Wrap(err)
+		}
+		logs += cmdLogs + "\n"
+		logrus.Debug("pushed docker image: ", b.Destination)
+		logrus.Debug("logs: ", cmdLogs)
 	}
-	logs += cmdLogs + "\n"
-	logrus.Debug("pushed docker image: ", b.Destination)
-	logrus.Debug("logs: ", cmdLogs)
 
 	if err := os.RemoveAll(b.BuildContext); err != nil {
 		return "", ErrFailedToRemoveContextDir.Wrap(err)
@@ -73,13 +100,32 @@ func (d *Docker) Build(_ context.Context, b *builder.BuilderOptions) (logs strin
 	return logs, nil
 }
 
-func runCommand(cmd *exec.Cmd) (logs string, err error) {
+// runCommand runs the command and returns its output, which is also streamed to the given writer if not nil
+func runCommand(cmd *exec.Cmd, output io.Writer) (logs string, err error) {
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	if output != nil {
+		// buildx writes its progress to stderr, both are copied concurrently to the output
+		output = &syncWriter{w: output}
+		cmd.Stdout = io.MultiWriter(&stdout, output)
+		cmd.Stderr = io.MultiWriter(&stderr, output)
+	}
 
 	if err := cmd.Run(); err != nil {
 		return "", ErrRunCommandFailed.Wrap(fmt.Errorf("%w\nstdout: %s\nstderr: %s", err, stdout.String(), stderr.String()))
 	}
 	return stdout.String() + stderr.String(), nil
 }
+
+// syncWriter serializes the writes to the underlying writer
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -7,5 +7,9 @@ import (
 type Error = errors.Error
 
 var (
-	ErrBuildContextEmpty = errors.New("BuildContextEmpty", "build context cannot be empty")
+	ErrBuildContextEmpty      = errors.New("BuildContextEmpty", "build context cannot be empty")
+	ErrRegistryHostEmpty      = errors.New("RegistryHostEmpty", "registry host cannot be empty")
+	ErrRegistryHostWithScheme = errors.New("RegistryHostWithScheme", "registry host %s must not contain a scheme")
+	ErrInvalidRegistryTTL     = errors.New("InvalidRegistryTTL", "invalid registry TTL %s, it must not be negative")
+	ErrInvalidPlatform        = errors.New("InvalidPlatform", "invalid platform %s, it must be in the form os/arch[/variant]")
 )
@@ -7,28 +7,39 @@ import (
 type Error = errors.Error
 
 var (
-	ErrBuildFailed                      = errors.New("BuildFailed", "build failed")
-	ErrBuildContextEmpty                = errors.New("BuildContextEmpty", "build context cannot be empty")
-	ErrCleaningUp                       = errors.New("CleaningUp", "error cleaning up")
-	ErrCreatingJob                      = errors.New("CreatingJob", "error creating Job")
-	ErrDeletingJob                      = errors.New("DeletingJob", "error deleting Job")
-	ErrDeletingPods                     = errors.New("DeletingPods", "error deleting Pods")
-	ErrGeneratingUUID                   = errors.New("GeneratingUUID", "error generating UUID")
-	ErrGettingContainerLogs             = errors.New("GettingContainerLogs", "error getting container logs")
-	ErrGettingPodFromJob                = errors.New("GettingPodFromJob", "error getting Pod from Job")
-	ErrListingJobs                      = errors.New("ListingJobs", "error listing Jobs")
-	ErrListingPods                      = errors.New("ListingPods", "error listing Pods")
-	ErrNoContainersFound                = errors.New("NoContainersFound", "no containers found")
-	ErrNoPodsFound                      = errors.New("NoPodsFound", "no Pods found")
-	ErrPreparingJob                     = errors.New("PreparingJob", "error preparing Job")
-	ErrWaitingJobCompletion             = errors.New("WaitingJobCompletion", "error waiting for Job completion")
+	ErrBuildFailed                = errors.New("BuildFailed", "build failed")
+	ErrBuildContextEmpty          = errors.New("BuildContextEmpty", "build context cannot be empty")
+	ErrCleaningUp                 = errors.New("CleaningUp", "error cleaning up")
+	ErrCreatingJob                = errors.New("CreatingJob", "error creating Job")
+	ErrDeletingJob                = errors.New("DeletingJob", "error deleting Job")
+	ErrDeletingPods               = errors.New("DeletingPods", "error deleting Pods")
+	ErrGeneratingUUID             = errors.New("GeneratingUUID", "error generating UUID")
+	ErrGettingContainerLogs       = errors.New("GettingContainerLogs", "error getting container logs")
+	ErrGettingPodFromJob          = errors.New("GettingPodFromJob", "error getting Pod from Job")
+	ErrListingJobs                = errors.New("ListingJobs", "error listing Jobs")
+	ErrNoContainersFound          = errors.New("NoContainersFound", "no containers found")
+	ErrPreparingJob               = errors.New("PreparingJob", "error preparing Job")
+	ErrWaitingJobCompletion       = errors.New("WaitingJobCompletion", "error waiting for Job completion")
+	ErrMountingDir                = errors.New("MountingDir", "error mounting directory")
+	ErrMinioNotConfigured         = errors.New("MinioNotConfigured", "Minio service is not configured")
+	ErrMinioDeploymentFailed      = errors.New("MinioDeploymentFailed", "Minio deployment failed")
+	ErrDeletingMinioContent       = errors.New("DeletingMinioContent", "error deleting Minio content")
+	ErrParsingQuantity            = errors.New("ParsingQuantity", "error parsing quantity")
+	ErrMinioFailedToGetDeployment = errors.New("MinioFailedToGetDeployment", "Minio failed to get deployment")
+	ErrCreatingDockerConfig       = errors.New("CreatingDockerConfig", "error creating the docker config secret of the build")
+	ErrMultiplePlatforms          = errors.New("MultiplePlatforms", "kaniko cannot build a manifest list, got platforms %v, use the buildkit or docker builder instead")
+)
+
+// The errors below are not returned anymore, they are kept for the callers matching them
+var (
+	// Deprecated: the pods of the build Job are listed by the k8s client, which returns its own errors
+	ErrListingPods = errors.New("ListingPods", "error listing Pods")
+	// Deprecated: the pods of the build Job are listed by the k8s client, which returns its own errors
+	ErrNoPodsFound = errors.New("NoPodsFound", "no Pods found")
+	// Deprecated: the build Job is watched by the k8s client, which watches it again when the watch is closed
 	ErrWatchingChannelCloseUnexpectedly = errors.New("WatchingChannelCloseUnexpectedly", "watch channel closed unexpectedly")
-	ErrWatchingJob                      = errors.New("WatchingJob", "error watching Job")
-	ErrContextCancelled                 = errors.New("ContextCancelled", "context cancelled")
-	ErrMountingDir                      = errors.New("MountingDir", "error mounting directory")
-	ErrMinioNotConfigured               = errors.New("MinioNotConfigured", "Minio service is not configured")
-	ErrMinioDeploymentFailed            = errors.New("MinioDeploymentFailed", "Minio deployment failed")
-	ErrDeletingMinioContent             = errors.New("DeletingMinioContent", "error deleting Minio content")
-	ErrParsingQuantity                  = errors.New("ParsingQuantity", "error parsing quantity")
-	ErrMinioFailedToGetDeployment       = errors.New("MinioFailedToGetDeployment", "Minio failed to get deployment")
+	// Deprecated: the build Job is watched by the k8s client, which returns its own errors
+	ErrWatchingJob = errors.New("WatchingJob", "error watching Job")
+	// Deprecated: a cancelled context is reported as ErrWaitingJobCompletion
+	ErrContextCancelled = errors.New("ContextCancelled", "context cancelled")
 )
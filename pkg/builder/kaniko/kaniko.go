@@ -6,6 +6,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
@@ -13,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/celestiaorg/knuu/pkg/builder"
+	"github.com/celestiaorg/knuu/pkg/k8s"
 	"github.com/celestiaorg/knuu/pkg/names"
 	"github.com/celestiaorg/knuu/pkg/system"
 )
@@ -22,11 +26,21 @@ const (
 	kanikoContainerName = "kaniko-container"
 	kanikoJobNamePrefix = "kaniko-build-job"
 
-	DefaultParallelism  = int32(1)
-	DefaultBackoffLimit = int32(5)
+	DefaultParallelism = int32(1)
+	// DefaultBackoffLimit is 0 as a failed build is not retried, it would fail the same way
+	DefaultBackoffLimit = int32(0)
 
 	MinioBucketName  = "kaniko"
 	EphemeralStorage = "10Gi"
+
+	logStreamPollInterval = time.Second
+	logStreamGracePeriod  = 5 * time.Second
+
+	dockerConfigDir     = "/kaniko/.docker"
+	dockerConfigVolName = "docker-config"
+	dockerConfigFile    = "config.json"
+
+	dockerConfigSecretSuffix = "-docker-config"
 )
 
 type Kaniko struct {
@@ -40,18 +54,22 @@ func (k *Kaniko) Build(ctx context.Context, b *builder.BuilderOptions) (logs str
 	if err != nil {
 		return "", ErrPreparingJob.Wrap(err)
 	}
+	// the build context may be canceled, the secret is deleted anyway
+	defer k.deleteDockerConfig(context.WithoutCancel(ctx), job)
 
 	cJob, err := k.K8sClient.Clientset().BatchV1().Jobs(k.K8sClient.Namespace()).Create(ctx, job, metav1.CreateOptions{})
 	if err != nil {
 		return "", ErrCreatingJob.Wrap(err)
 	}
 
-	kJob, err := k.waitForJobCompletion(ctx, cJob)
+	stopStreaming := k.streamLogs(ctx, cJob.Name, b.Output)
+	kJob, err := k.K8sClient.WaitForJobCompletion(ctx, cJob.Name)
+	stopStreaming()
 	if err != nil {
 		return "", ErrWaitingJobCompletion.Wrap(err)
 	}
 
-	pod, err := k.firstPodFromJob(ctx, kJob)
+	pod, err := k.latestPodFromJob(ctx, kJob.Name)
 	if err != nil {
 		return "", ErrGettingPodFromJob.Wrap(err)
 	}
@@ -68,50 +86,81 @@ func (k *Kaniko) Build(ctx context.Context, b *builder.BuilderOptions) (logs str
 	return logs, nil
 }
 
-func (k *Kaniko) waitForJobCompletion(ctx context.Context, job *batchv1.Job) (*batchv1.Job, error) {
-	watcher, err := k.K8sClient.Clientset().BatchV1().Jobs(k.K8sClient.Namespace()).Watch(ctx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", job.Name),
-	})
+// latestPodFromJob returns the most recent pod of the job, i.e. the one of the attempt that decided its result
+func (k *Kaniko) latestPodFromJob(ctx context.Context, jobName string) (*v1.Pod, error) {
+	pods, err := k.K8sClient.GetPodsFromJob(ctx, jobName)
 	if err != nil {
-		return nil, ErrWatchingJob.Wrap(err)
+		return nil, err
 	}
-	defer watcher.Stop()
-
-	for {
-		select {
-		case event, ok := <-watcher.ResultChan():
-			if !ok {
-				return nil, ErrWatchingChannelCloseUnexpectedly
-			}
+	if len(pods) == 0 {
+		return nil, k8s.ErrNoPodsForJob.WithParams(jobName)
+	}
+	return &pods[len(pods)-1], nil
+}
 
-			j, ok := event.Object.(*batchv1.Job)
-			if !ok {
-				continue
-			}
+// streamLogs follows the logs of the kaniko container of the job into the output, if not nil, while the job runs
+// The returned function stops the streaming; it waits for the stream to end if the container has started
+func (k *Kaniko) streamLogs(ctx context.Context, jobName string, output io.Writer) (stop func()) {
+	if output == nil {
+		return func() {}
+	}
 
-			if j.Status.Succeeded > 0 || j.Status.Failed > 0 {
-				// Job completed (successfully or failed)
-				return j, nil
+	ctx, cancel := context.WithCancel(ctx)
+	var (
+		streaming atomic.Bool
+		done      = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		pod, err := k.waitForContainerStarted(ctx, jobName)
+		if err != nil {
+			return
+		}
+		stream, err := k.K8sClient.FollowPodLogStream(ctx, pod.Name, kanikoContainerName)
+		if err != nil {
+			k.Logger.WithField("job", jobName).Debugf("cannot stream the build logs: %v", err)
+			return
+		}
+		defer stream.Close()
+		streaming.Store(true)
+		_, _ = io.Copy(output, stream)
+	}()
+
+	return func() {
+		// the stream ends with the container, give it some time to flush the last logs
+		if streaming.Load() {
+			select {
+			case <-done:
+			case <-time.After(logStreamGracePeriod):
 			}
-		case <-ctx.Done():
-			return nil, ErrContextCancelled
 		}
+		cancel()
+		<-done
 	}
 }
 
-func (k *Kaniko) firstPodFromJob(ctx context.Context, job *batchv1.Job) (*v1.Pod, error) {
-	podList, err := k.K8sClient.Clientset().CoreV1().Pods(k.K8sClient.Namespace()).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("job-name=%s", job.Name),
-	})
-	if err != nil {
-		return nil, ErrListingPods.Wrap(err)
-	}
+// waitForContainerStarted waits until the kaniko container of the job has started and returns its pod
+func (k *Kaniko) waitForContainerStarted(ctx context.Context, jobName string) (*v1.Pod, error) {
+	ticker := time.NewTicker(logStreamPollInterval)
+	defer ticker.Stop()
+	for {
+		pods, err := k.K8sClient.GetPodsFromJob(ctx, jobName)
+		if err == nil {
+			for _, pod := range pods {
+				for _, cs := range pod.Status.ContainerStatuses {
+					if cs.Name == kanikoContainerName && (cs.State.Running != nil || cs.State.Terminated != nil) {
+						return &pod, nil
+					}
+				}
+			}
+		}
 
-	if len(podList.Items) == 0 {
-		return nil, ErrNoPodsFound.Wrap(fmt.Errorf("job: %s", job.Name))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
 	}
-
-	return &podList.Items[0], nil
 }
 
 func (k *Kaniko) containerLogs(ctx context.Context, pod *v1.Pod) (string, error) {
@@ -153,7 +202,7 @@ func (k *Kaniko) prepareJob(ctx context.Context, b *builder.BuilderOptions) (*ba
 		},
 		Spec: batchv1.JobSpec{
 			Parallelism:  &parallelism,  // Set parallelism to 1 to ensure only one Pod
-			BackoffLimit: &backoffLimit, // Retry the Job at most 5 times
+			BackoffLimit: &backoffLimit, // Fail the Job with the first failed build
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -174,6 +223,10 @@ func (k *Kaniko) prepareJob(ctx context.Context, b *builder.BuilderOptions) (*ba
 		},
 	}
 
+	if err := setPlatform(job, b.Platforms); err != nil {
+		return nil, err
+	}
+
 	if builder.IsDirContext(b.BuildContext) {
 		job, err = k.mountDir(ctx, b.BuildContext, job)
 		if err != nil {
@@ -181,6 +234,11 @@ func (k *Kaniko) prepareJob(ctx context.Context, b *builder.BuilderOptions) (*ba
 		}
 	}
 
+	// the secret is created last, so it is not left behind if preparing the job fails
+	if err := k.mountDockerConfig(ctx, job, b); err != nil {
+		return nil, err
+	}
+
 	return job, nil
 }
 
@@ -252,17 +310,105 @@ func (k *Kaniko) mountDir(ctx context.Context, bCtx string, job *batchv1.Job) (*
 	return job, nil
 }
 
+// setPlatform builds the image for the given platform on a node of the same platform,
+// as kaniko runs the build steps natively and cannot build a manifest list
+func setPlatform(job *batchv1.Job, platforms []string) error {
+	switch len(platforms) {
+	case 0:
+		return nil
+	case 1:
+	default:
+		return ErrMultiplePlatforms.WithParams(platforms)
+	}
+
+	nodeSelector, err := builder.PlatformNodeSelector(platforms[0])
+	if err != nil {
+		return err
+	}
+	job.Spec.Template.Spec.NodeSelector = nodeSelector
+	job.Spec.Template.Spec.Containers[0].Args = append(job.Spec.Template.Spec.Containers[0].Args, "--custom-platform="+platforms[0])
+	return nil
+}
+
+// mountDockerConfig mounts a dockerconfigjson secret as the docker config of kaniko,
+// so kaniko can pull from and push to the registries that need credentials
+// The secret is created for the build from the registries of the builder options, which include
+// the credentials of the instance; without any, the image pull secret of the scope is mounted if there is one
+func (k *Kaniko) mountDockerConfig(ctx context.Context, job *batchv1.Job, b *builder.BuilderOptions) error {
+	secretName := k.ImagePullSecret
+	registries := append([]*builder.Registry{b.Registry}, b.RegistryCredentials...)
+	if builder.HasAuth(registries...) {
+		dockerConfig, err := builder.DockerConfigJSON(registries...)
+		if err != nil {
+			return ErrCreatingDockerConfig.Wrap(err)
+		}
+		secretName = job.Name + dockerConfigSecretSuffix
+		_, err = k.K8sClient.CreateOrUpdateSecret(ctx, secretName, nil,
+			v1.SecretTypeDockerConfigJson, map[string][]byte{v1.DockerConfigJsonKey: dockerConfig})
+		if err != nil {
+			return ErrCreatingDockerConfig.Wrap(err)
+		}
+	}
+	if secretName == "" {
+		return nil
+	}
+
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, v1.Volume{
+		Name: dockerConfigVolName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: secretName,
+				Items: []v1.KeyToPath{
+					{Key: v1.DockerConfigJsonKey, Path: dockerConfigFile},
+				},
+			},
+		},
+	})
+	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
+		Name:      dockerConfigVolName,
+		MountPath: dockerConfigDir,
+		ReadOnly:  true,
+	})
+	return nil
+}
+
+// deleteDockerConfig deletes the docker config secret created for the build job, if any
+func (k *Kaniko) deleteDockerConfig(ctx context.Context, job *batchv1.Job) {
+	secretName := job.Name + dockerConfigSecretSuffix
+	for _, vol := range job.Spec.Template.Spec.Volumes {
+		if vol.Secret == nil || vol.Secret.SecretName != secretName {
+			continue
+		}
+		if err := k.K8sClient.DeleteSecret(ctx, secretName); err != nil {
+			k.Logger.WithField("job", job.Name).Debugf("cannot delete the docker config of the build: %v", err)
+		}
+	}
+}
+
 func prepareArgs(b *builder.BuilderOptions) []string {
 	args := []string{
 		"--context=" + b.BuildContext,
 		// TODO: see if we need it or not
 		// --git gitoptions    Branch to clone if build context is a git repository (default branch=,single-branch=false,recurse-submodules=false)
 
-		// TODO: we might need to add some options to get the auth token for the registry
+		// the credentials of the registries are mounted from the image pull secret, see mountDockerConfig
 		"--destination=" + b.Destination,
 		// "--verbosity=debug", // log level
 	}
 
+	if b.Dockerfile != "" {
+		// kaniko resolves the path of the Dockerfile against the build context
+		args = append(args, "--dockerfile="+b.Dockerfile)
+	}
+	if b.Target != "" {
+		args = append(args, "--target="+b.Target)
+	}
+
+	if b.Registry != nil && b.Registry.Insecure {
+		// allows pushing and pulling the image and the cache over plain HTTP
+		args = append(args, "--insecure-registry="+b.Registry.Host)
+	}
+
 	// TODO: we need to add some configs to get the auth token for the cache repo
 	if b.Cache != nil && b.Cache.Enabled {
 		args = append(args, "--cache=true")
@@ -1,6 +1,7 @@
 package kaniko
 
 import (
+	"bytes"
 	"context"
 	"sync"
 	"testing"
@@ -70,6 +71,63 @@ func TestKanikoBuilder(t *testing.T) {
 		assert.NotEmpty(t, logs, "Build logs should not be empty")
 	})
 
+	t.Run("BuildFailure", func(t *testing.T) {
+		buildOptions := &builder.BuilderOptions{
+			ImageName:    testImage,
+			BuildContext: "git://github.com/mojtaba-esk/sample-docker",
+			Destination:  testDestination,
+		}
+
+		var (
+			logs     string
+			buildErr error
+			wg       = &sync.WaitGroup{}
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logs, buildErr = kb.Build(ctx, buildOptions)
+		}()
+
+		var job batchv1.Job
+		require.Eventually(t, func() bool {
+			jobs, listErr := k8sCS.BatchV1().Jobs(k8sNamespace).List(ctx, metav1.ListOptions{})
+			if listErr != nil {
+				return false
+			}
+			for _, j := range jobs.Items {
+				if j.Status.Succeeded == 0 {
+					job = j
+					return true
+				}
+			}
+			return false
+		}, 5*time.Second, 10*time.Millisecond)
+		// a failed build is not retried
+		require.NotNil(t, job.Spec.BackoffLimit)
+		assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+
+		// the logs are read from the latest attempt, the older one has no container to read them from
+		older := createPodFromJob(&job)
+		older.Name = job.Name + "-older"
+		older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+		older.Spec.Containers = nil
+		latest := createPodFromJob(&job)
+		latest.CreationTimestamp = metav1.NewTime(time.Now())
+		for _, pod := range []*v1.Pod{older, latest} {
+			_, err := k8sCS.CoreV1().Pods(k8sNamespace).Create(ctx, pod, metav1.CreateOptions{})
+			require.NoError(t, err)
+		}
+		job.Status.Failed = 1
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}
+		_, err := k8sCS.BatchV1().Jobs(k8sNamespace).Update(ctx, &job, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		wg.Wait()
+		assert.ErrorIs(t, buildErr, ErrBuildFailed)
+		assert.Equal(t, "fake logs", logs)
+	})
+
 	t.Run("BuildWithContextCancellation", func(t *testing.T) {
 		buildOptions := &builder.BuilderOptions{
 			ImageName:    testImage,
@@ -155,3 +213,176 @@ func TestGetDefaultCacheOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareJobWithImagePullSecret(t *testing.T) {
+	k8sCS := fake.NewSimpleClientset()
+	k8sClient, err := k8s.NewClientCustom(context.Background(), k8sCS, k8sCS.Discovery(), nil, k8sNamespace, logrus.New())
+	require.NoError(t, err)
+	kb := &Kaniko{
+		SystemDependencies: &system.SystemDependencies{
+			K8sClient:       k8sClient,
+			ImagePullSecret: "registry-credentials",
+		},
+	}
+
+	job, err := kb.prepareJob(context.Background(), &builder.BuilderOptions{
+		ImageName:    testImage,
+		BuildContext: "git://github.com/mojtaba-esk/sample-docker",
+		Destination:  testDestination,
+	})
+	require.NoError(t, err)
+
+	volumes := job.Spec.Template.Spec.Volumes
+	require.Len(t, volumes, 1)
+	require.NotNil(t, volumes[0].Secret)
+	assert.Equal(t, "registry-credentials", volumes[0].Secret.SecretName)
+	assert.Equal(t, []v1.KeyToPath{{Key: v1.DockerConfigJsonKey, Path: "config.json"}}, volumes[0].Secret.Items)
+
+	mounts := job.Spec.Template.Spec.Containers[0].VolumeMounts
+	require.Len(t, mounts, 1)
+	assert.Equal(t, "/kaniko/.docker", mounts[0].MountPath)
+}
+
+func TestPrepareJobWithRegistryCredentials(t *testing.T) {
+	k8sCS := fake.NewSimpleClientset()
+	k8sClient, err := k8s.NewClientCustom(context.Background(), k8sCS, k8sCS.Discovery(), nil, k8sNamespace, logrus.New())
+	require.NoError(t, err)
+	kb := &Kaniko{
+		SystemDependencies: &system.SystemDependencies{
+			K8sClient:       k8sClient,
+			Logger:          logrus.New(),
+			ImagePullSecret: "registry-credentials",
+		},
+	}
+	ctx := context.Background()
+
+	job, err := kb.prepareJob(ctx, &builder.BuilderOptions{
+		ImageName:    testImage,
+		BuildContext: "git://github.com/mojtaba-esk/sample-docker",
+		Destination:  testDestination,
+		Registry:     &builder.Registry{Host: "registry.example.com", Auth: &builder.RegistryAuth{Username: "push", Password: "secret"}},
+		RegistryCredentials: []*builder.Registry{
+			{Host: "private.example.com", Auth: &builder.RegistryAuth{Username: "pull", Password: "secret"}},
+		},
+	})
+	require.NoError(t, err)
+
+	// the build gets its own docker config instead of the image pull secret of the scope
+	secretName := job.Name + dockerConfigSecretSuffix
+	volumes := job.Spec.Template.Spec.Volumes
+	require.Len(t, volumes, 1)
+	require.NotNil(t, volumes[0].Secret)
+	assert.Equal(t, secretName, volumes[0].Secret.SecretName)
+
+	secret, err := k8sCS.CoreV1().Secrets(k8sNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1.SecretTypeDockerConfigJson, secret.Type)
+	dockerConfig := string(secret.Data[v1.DockerConfigJsonKey])
+	assert.Contains(t, dockerConfig, `"registry.example.com"`)
+	assert.Contains(t, dockerConfig, `"private.example.com"`)
+
+	kb.deleteDockerConfig(ctx, job)
+	_, err = k8sCS.CoreV1().Secrets(k8sNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	assert.Error(t, err, "the docker config of the build should be deleted")
+}
+
+func TestPrepareJobWithPlatforms(t *testing.T) {
+	k8sCS := fake.NewSimpleClientset()
+	k8sClient, err := k8s.NewClientCustom(context.Background(), k8sCS, k8sCS.Discovery(), nil, k8sNamespace, logrus.New())
+	require.NoError(t, err)
+	kb := &Kaniko{SystemDependencies: &system.SystemDependencies{K8sClient: k8sClient}}
+
+	opts := &builder.BuilderOptions{
+		ImageName:    testImage,
+		BuildContext: "git://github.com/mojtaba-esk/sample-docker",
+		Destination:  testDestination,
+		Platforms:    []string{"linux/arm64"},
+	}
+	job, err := kb.prepareJob(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Args, "--custom-platform=linux/arm64")
+	assert.Equal(t, "arm64", job.Spec.Template.Spec.NodeSelector[builder.NodeArchLabel])
+
+	opts.Platforms = []string{"linux/amd64", "linux/arm64"}
+	_, err = kb.prepareJob(context.Background(), opts)
+	assert.ErrorIs(t, err, ErrMultiplePlatforms)
+}
+
+func TestPrepareArgsWithDockerfile(t *testing.T) {
+	args := prepareArgs(&builder.BuilderOptions{
+		BuildContext: "dir:///tmp/context",
+		Destination:  testDestination,
+		Dockerfile:   "build/Dockerfile",
+		Target:       "app",
+	})
+	assert.Contains(t, args, "--dockerfile=build/Dockerfile")
+	assert.Contains(t, args, "--target=app")
+}
+
+// syncBuffer is a bytes.Buffer that can be read while the build writes the logs into it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestKanikoBuilderStreamsLogs(t *testing.T) {
+	k8sCS := fake.NewSimpleClientset()
+	k8sClient, err := k8s.NewClientCustom(context.Background(), k8sCS, k8sCS.Discovery(), nil, k8sNamespace, logrus.New())
+	require.NoError(t, err)
+	kb := &Kaniko{SystemDependencies: &system.SystemDependencies{K8sClient: k8sClient, Logger: logrus.New()}}
+	ctx := context.Background()
+
+	var (
+		output syncBuffer
+		wg     sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err = kb.Build(ctx, &builder.BuilderOptions{
+			ImageName:    testImage,
+			BuildContext: "git://github.com/mojtaba-esk/sample-docker",
+			Destination:  testDestination,
+			Output:       &output,
+		})
+	}()
+
+	// Simulate the start of the kaniko container, then the completion of the Job once the logs are streamed
+	var job batchv1.Job
+	require.Eventually(t, func() bool {
+		jobs, listErr := k8sCS.BatchV1().Jobs(k8sNamespace).List(ctx, metav1.ListOptions{})
+		if listErr != nil || len(jobs.Items) != 1 {
+			return false
+		}
+		job = jobs.Items[0]
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	pod := createPodFromJob(&job)
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{
+		{Name: kanikoContainerName, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+	}
+	_, createErr := k8sCS.CoreV1().Pods(k8sNamespace).Create(ctx, pod, metav1.CreateOptions{})
+	require.NoError(t, createErr)
+
+	require.Eventually(t, func() bool { return output.String() != "" }, 5*logStreamPollInterval, 10*time.Millisecond)
+	job.Status.Succeeded = 1
+	_, updateErr := k8sCS.BatchV1().Jobs(k8sNamespace).Update(ctx, &job, metav1.UpdateOptions{})
+	require.NoError(t, updateErr)
+
+	wg.Wait()
+	require.NoError(t, err)
+	assert.Equal(t, "fake logs", output.String())
+}
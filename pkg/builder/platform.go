@@ -0,0 +1,53 @@
+package builder
+
+import (
+	"strings"
+)
+
+const (
+	// DefaultPlatform is the platform the images are built for when no platform is set
+	DefaultPlatform = "linux/amd64"
+
+	// NodeArchLabel and NodeOSLabel are the well-known labels of the architecture and the OS of a node
+	NodeArchLabel = "kubernetes.io/arch"
+	NodeOSLabel   = "kubernetes.io/os"
+)
+
+// ParsePlatform splits a platform in the form os/arch[/variant], e.g. linux/arm64 or linux/arm/v7
+func ParsePlatform(platform string) (osName, arch, variant string, err error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", "", "", ErrInvalidPlatform.WithParams(platform)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return "", "", "", ErrInvalidPlatform.WithParams(platform)
+		}
+	}
+	if len(parts) == 3 {
+		variant = parts[2]
+	}
+	return parts[0], parts[1], variant, nil
+}
+
+// ValidatePlatforms checks that all the given platforms are in the form os/arch[/variant]
+func ValidatePlatforms(platforms []string) error {
+	for _, p := range platforms {
+		if _, _, _, err := ParsePlatform(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlatformNodeSelector returns the node selector that pins the pods to the nodes of the given platform
+func PlatformNodeSelector(platform string) (map[string]string, error) {
+	osName, arch, _, err := ParsePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		NodeOSLabel:   osName,
+		NodeArchLabel: arch,
+	}, nil
+}
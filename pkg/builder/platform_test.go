@@ -0,0 +1,27 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlatform(t *testing.T) {
+	osName, arch, variant, err := ParsePlatform("linux/arm/v7")
+	require.NoError(t, err)
+	assert.Equal(t, "linux", osName)
+	assert.Equal(t, "arm", arch)
+	assert.Equal(t, "v7", variant)
+
+	for _, platform := range []string{"", "arm64", "linux/", "linux/arm/v7/extra"} {
+		_, _, _, err := ParsePlatform(platform)
+		assert.ErrorIs(t, err, ErrInvalidPlatform, platform)
+	}
+}
+
+func TestPlatformNodeSelector(t *testing.T) {
+	nodeSelector, err := PlatformNodeSelector("linux/arm64")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{NodeOSLabel: "linux", NodeArchLabel: "arm64"}, nodeSelector)
+}
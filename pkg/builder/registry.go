@@ -0,0 +1,134 @@
+package builder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRegistryHost = "ttl.sh"
+	DefaultRegistryTTL  = 24 * time.Hour
+
+	latestTag = "latest"
+
+	dockerHubHost       = "docker.io"
+	dockerHubConfigHost = "https://index.docker.io/v1/"
+)
+
+// Registry is the registry the built images and the build cache are pushed to
+type Registry struct {
+	// Host of the registry, e.g. ttl.sh or registry.example.com:5000
+	Host string
+	// Repo is an optional path prefix for the images, e.g. my-org/knuu
+	Repo string
+	// Auth holds the credentials to push to and pull from the registry, nil for anonymous access
+	Auth *RegistryAuth
+	// TTL is set as the image tag for registries that expire images by tag (e.g. ttl.sh),
+	// zero means the images are tagged 'latest' and never expire
+	TTL time.Duration
+	// Insecure allows pushing to and pulling from the registry over plain HTTP
+	Insecure bool
+}
+
+type RegistryAuth struct {
+	Username string
+	Password string
+}
+
+// DefaultRegistry returns the registry used when none is configured: ttl.sh with a 24h expiry
+func DefaultRegistry() *Registry {
+	return &Registry{
+		Host: DefaultRegistryHost,
+		TTL:  DefaultRegistryTTL,
+	}
+}
+
+// Validate checks that the registry can be used to push images
+func (r *Registry) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.Host == "" {
+		return ErrRegistryHostEmpty
+	}
+	if strings.Contains(r.Host, "://") {
+		return ErrRegistryHostWithScheme.WithParams(r.Host)
+	}
+	if r.TTL < 0 {
+		return ErrInvalidRegistryTTL.WithParams(r.TTL)
+	}
+	return nil
+}
+
+// ImageName returns the full reference of the image with the given name in the registry
+// A nil registry is the default registry
+func (r *Registry) ImageName(name string) string {
+	if r == nil {
+		r = DefaultRegistry()
+	}
+
+	ref := strings.TrimSuffix(r.Host, "/")
+	if repo := strings.Trim(r.Repo, "/"); repo != "" {
+		ref += "/" + repo
+	}
+	return fmt.Sprintf("%s/%s:%s", ref, name, r.tag())
+}
+
+// tag returns the tag of the images, which is the TTL for expiring registries
+func (r *Registry) tag() string {
+	switch {
+	case r.TTL <= 0:
+		return latestTag
+	case r.TTL%time.Hour == 0:
+		return fmt.Sprintf("%dh", r.TTL/time.Hour)
+	case r.TTL%time.Minute == 0:
+		return fmt.Sprintf("%dm", r.TTL/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", r.TTL/time.Second)
+	}
+}
+
+type dockerConfig struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+type dockerConfigAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// DockerConfigJSON returns a docker config.json holding the credentials of the given registries,
+// which is also the content of a kubernetes.io/dockerconfigjson secret
+// The registries without credentials are skipped
+func DockerConfigJSON(registries ...*Registry) ([]byte, error) {
+	config := dockerConfig{Auths: map[string]dockerConfigAuth{}}
+	for _, r := range registries {
+		if r == nil || r.Auth == nil {
+			continue
+		}
+		host := r.Host
+		if host == dockerHubHost {
+			host = dockerHubConfigHost
+		}
+		config.Auths[host] = dockerConfigAuth{
+			Username: r.Auth.Username,
+			Password: r.Auth.Password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(r.Auth.Username + ":" + r.Auth.Password)),
+		}
+	}
+	return json.Marshal(config)
+}
+
+// HasAuth returns true if at least one of the registries has credentials
+func HasAuth(registries ...*Registry) bool {
+	for _, r := range registries {
+		if r != nil && r.Auth != nil {
+			return true
+		}
+	}
+	return false
+}
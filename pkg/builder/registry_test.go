@@ -0,0 +1,50 @@
+package builder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryImageName(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry *Registry
+		expected string
+	}{
+		{name: "nil registry", registry: nil, expected: "ttl.sh/abc:24h"},
+		{name: "default registry", registry: DefaultRegistry(), expected: "ttl.sh/abc:24h"},
+		{name: "private registry", registry: &Registry{Host: "registry.example.com:5000", Repo: "/org/knuu/"}, expected: "registry.example.com:5000/org/knuu/abc:latest"},
+		{name: "ttl in minutes", registry: &Registry{Host: "ttl.sh", TTL: 90 * time.Minute}, expected: "ttl.sh/abc:90m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.registry.ImageName("abc"))
+		})
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	assert.NoError(t, (*Registry)(nil).Validate())
+	assert.NoError(t, DefaultRegistry().Validate())
+	assert.ErrorIs(t, (&Registry{}).Validate(), ErrRegistryHostEmpty)
+	assert.ErrorIs(t, (&Registry{Host: "https://registry.example.com"}).Validate(), ErrRegistryHostWithScheme)
+	assert.ErrorIs(t, (&Registry{Host: "ttl.sh", TTL: -time.Hour}).Validate(), ErrInvalidRegistryTTL)
+}
+
+func TestDockerConfigJSON(t *testing.T) {
+	assert.False(t, HasAuth(nil, DefaultRegistry()))
+
+	private := &Registry{Host: "ghcr.io", Auth: &RegistryAuth{Username: "user", Password: "token"}}
+	hub := &Registry{Host: "docker.io", Auth: &RegistryAuth{Username: "hub", Password: "secret"}}
+	assert.True(t, HasAuth(DefaultRegistry(), private))
+
+	data, err := DockerConfigJSON(DefaultRegistry(), private, hub)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths":{
+		"ghcr.io":{"username":"user","password":"token","auth":"dXNlcjp0b2tlbg=="},
+		"https://index.docker.io/v1/":{"username":"hub","password":"secret","auth":"aHViOnNlY3JldA=="}
+	}}`, string(data))
+}
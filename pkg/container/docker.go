@@ -22,6 +22,12 @@ type BuilderFactory struct {
 	dockerFileInstructions []string
 	buildContext           string
 	args                   []builder.ArgInterface
+	registry               *builder.Registry
+	registryCredentials    []*builder.Registry
+	platforms              []string
+	dockerfile             string
+	target                 string
+	buildLogHandler        func(line string)
 	logger                 *logrus.Logger
 }
 
@@ -30,7 +36,17 @@ type BuilderFactoryOptions struct {
 	BuildContext string
 	ImageBuilder builder.Builder
 	Args         []builder.ArgInterface
-	Logger       *logrus.Logger
+	Registry     *builder.Registry // Registry the images are pushed to, nil means the default registry
+	// RegistryCredentials are the credentials of other registries, e.g. to pull private base images
+	RegistryCredentials []*builder.Registry
+	// Platforms the image is built for, empty means the default platform of the image builder
+	Platforms []string
+	// Dockerfile is the path of a Dockerfile relative to the build context, the image is built from it
+	// instead of the instructions generated from ImageName, which is then ignored
+	Dockerfile string
+	// Target is the stage of the multi-stage Dockerfile to build
+	Target string
+	Logger *logrus.Logger
 }
 
 // NewBuilderFactory creates a new instance of BuilderFactory.
@@ -39,6 +55,10 @@ func NewBuilderFactory(opts BuilderFactoryOptions) (*BuilderFactory, error) {
 		return nil, err
 	}
 
+	if opts.Dockerfile != "" {
+		return newDockerfileBuilderFactory(opts)
+	}
+
 	if err := os.MkdirAll(opts.BuildContext, 0755); err != nil {
 		return nil, ErrFailedToCreateContextDir.Wrap(err)
 	}
@@ -49,10 +69,48 @@ func NewBuilderFactory(opts BuilderFactoryOptions) (*BuilderFactory, error) {
 		buildContext:           opts.BuildContext,
 		imageBuilder:           opts.ImageBuilder,
 		args:                   opts.Args,
+		registry:               opts.Registry,
+		registryCredentials:    opts.RegistryCredentials,
+		platforms:              opts.Platforms,
 		logger:                 opts.Logger,
 	}, nil
 }
 
+// newDockerfileBuilderFactory creates a builder factory that builds the image from an existing Dockerfile and build context
+func newDockerfileBuilderFactory(opts BuilderFactoryOptions) (*BuilderFactory, error) {
+	if info, err := os.Stat(opts.BuildContext); err != nil || !info.IsDir() {
+		return nil, ErrBuildContextNotFound.WithParams(opts.BuildContext)
+	}
+	dockerfilePath := filepath.Join(opts.BuildContext, opts.Dockerfile)
+	if info, err := os.Stat(dockerfilePath); err != nil || info.IsDir() {
+		return nil, ErrDockerfileNotFound.WithParams(dockerfilePath)
+	}
+
+	return &BuilderFactory{
+		buildContext:        opts.BuildContext,
+		imageBuilder:        opts.ImageBuilder,
+		args:                opts.Args,
+		registry:            opts.Registry,
+		registryCredentials: opts.RegistryCredentials,
+		platforms:           opts.Platforms,
+		dockerfile:          opts.Dockerfile,
+		target:              opts.Target,
+		logger:              opts.Logger,
+	}, nil
+}
+
+// SetBuildLogHandler sets a function called with every line of the build output while the image is built.
+// The lines are also logged at debug level by the logger of the factory.
+func (f *BuilderFactory) SetBuildLogHandler(handler func(line string)) {
+	f.buildLogHandler = handler
+}
+
+// IsDockerfileBuild returns true if the image is built from an existing Dockerfile,
+// which cannot be modified with generated instructions.
+func (f *BuilderFactory) IsDockerfileBuild() bool {
+	return f.dockerfile != ""
+}
+
 // ImageNameFrom returns the name of the image from which the builder is created.
 func (f *BuilderFactory) ImageNameFrom() string {
 	return f.imageNameFrom
@@ -78,9 +136,24 @@ func (f *BuilderFactory) SetUser(user string) {
 	f.dockerFileInstructions = append(f.dockerFileInstructions, "USER "+user)
 }
 
+// SetPlatforms sets the platforms the image is built for.
+func (f *BuilderFactory) SetPlatforms(platforms []string) {
+	f.platforms = platforms
+}
+
+// SetRegistryCredentials sets the credentials of the registries used by the build, e.g. to pull private base images.
+func (f *BuilderFactory) SetRegistryCredentials(registries []*builder.Registry) {
+	f.registryCredentials = registries
+}
+
+// Platforms returns the platforms the image is built for, empty means the default platform of the image builder.
+func (f *BuilderFactory) Platforms() []string {
+	return f.platforms
+}
+
 // Changed returns true if the builder has been modified, false otherwise.
 func (f *BuilderFactory) Changed() bool {
-	return len(f.dockerFileInstructions) > 1
+	return f.IsDockerfileBuild() || len(f.dockerFileInstructions) > 1
 }
 
 // PushBuilderImage pushes the image from the given builder to a registry.
@@ -93,36 +166,52 @@ func (f *BuilderFactory) PushBuilderImage(ctx context.Context, imageName string)
 
 	f.imageNameTo = imageName
 
-	dockerFilePath := filepath.Join(f.buildContext, "Dockerfile")
-	// create path if it does not exist
-	if _, err := os.Stat(f.buildContext); os.IsNotExist(err) {
-		err = os.MkdirAll(f.buildContext, 0755)
-		if err != nil {
-			return ErrFailedToCreateContextDir.Wrap(err)
+	if !f.IsDockerfileBuild() {
+		if err := f.writeDockerfile(); err != nil {
+			return err
 		}
 	}
 
-	dockerFile := strings.Join(f.dockerFileInstructions, "\n")
-	err := os.WriteFile(dockerFilePath, []byte(dockerFile), 0644)
-	if err != nil {
-		return ErrFailedToWriteDockerfile.Wrap(err)
-	}
-
 	if f.imageBuilder == nil {
 		return ErrImageBuilderNotSet
 	}
 
-	logs, err := f.imageBuilder.Build(ctx, &builder.BuilderOptions{
+	output := f.buildOutput(f.imageNameTo)
+	_, err := f.imageBuilder.Build(ctx, &builder.BuilderOptions{
 		ImageName:    f.imageNameTo,
 		Destination:  f.imageNameTo, // in docker the image name and destination are the same
 		BuildContext: builder.DirContext{Path: f.buildContext}.BuildContext(),
 		Args:         f.args,
-	})
+		Registry:     f.registry,
 
-	f.logDebugWithQuotesDisabled("build logs: ", logs)
+		RegistryCredentials: f.registryCredentials,
+		Platforms:           f.platforms,
+		Dockerfile:          f.dockerfile,
+		Target:              f.target,
+		Output:              output,
+	})
+	output.Flush()
 	return err
 }
 
+// writeDockerfile writes the generated Dockerfile instructions to the build context
+func (f *BuilderFactory) writeDockerfile() error {
+	dockerFilePath := filepath.Join(f.buildContext, "Dockerfile")
+	// create path if it does not exist
+	if _, err := os.Stat(f.buildContext); os.IsNotExist(err) {
+		err = os.MkdirAll(f.buildContext, 0755)
+		if err != nil {
+			return ErrFailedToCreateContextDir.Wrap(err)
+		}
+	}
+
+	dockerFile := strings.Join(f.dockerFileInstructions, "\n")
+	if err := os.WriteFile(dockerFilePath, []byte(dockerFile), 0644); err != nil {
+		return ErrFailedToWriteDockerfile.Wrap(err)
+	}
+	return nil
+}
+
 // BuildImageFromGitRepo builds an image from the given git repository and
 // pushes it to a registry. The image is identified by the provided name.
 func (f *BuilderFactory) BuildImageFromGitRepo(ctx context.Context, gitCtx builder.GitContext, imageName string) error {
@@ -134,7 +223,7 @@ func (f *BuilderFactory) BuildImageFromGitRepo(ctx context.Context, gitCtx build
 	f.imageNameTo = imageName
 
 	cOpts := &builder.CacheOptions{}
-	cOpts, err = cOpts.Default(buildCtx)
+	cOpts, err = cOpts.DefaultWithRegistry(buildCtx, f.registry)
 	if err != nil {
 		return ErrFailedToGetDefaultCacheOptions.Wrap(err)
 	}
@@ -145,15 +234,20 @@ func (f *BuilderFactory) BuildImageFromGitRepo(ctx context.Context, gitCtx build
 		return ErrImageBuilderNotSet
 	}
 
-	logs, err := f.imageBuilder.Build(ctx, &builder.BuilderOptions{
+	output := f.buildOutput(imageName)
+	_, err = f.imageBuilder.Build(ctx, &builder.BuilderOptions{
 		ImageName:    imageName,
 		Destination:  imageName,
 		BuildContext: buildCtx,
 		Cache:        cOpts,
 		Args:         f.args,
-	})
+		Registry:     f.registry,
 
-	f.logDebugWithQuotesDisabled("build logs: ", logs)
+		RegistryCredentials: f.registryCredentials,
+		Platforms:           f.platforms,
+		Output:              output,
+	})
+	output.Flush()
 	return err
 }
 
@@ -168,6 +262,21 @@ func (f *BuilderFactory) GenerateImageHash() (string, error) {
 		return "", ErrHashingDockerfile.Wrap(err)
 	}
 
+	// The Dockerfile is part of the hashed build context, but the same context built
+	// from another Dockerfile or for another stage is another image
+	if f.IsDockerfileBuild() {
+		if _, err := hasher.Write([]byte(f.dockerfile + "\n" + f.target)); err != nil {
+			return "", ErrHashingDockerfile.Wrap(err)
+		}
+	}
+
+	// The same Dockerfile built for other platforms is another image
+	if len(f.platforms) != 0 {
+		if _, err := hasher.Write([]byte(strings.Join(f.platforms, ","))); err != nil {
+			return "", ErrHashingDockerfile.Wrap(err)
+		}
+	}
+
 	// Hash contents of all files in the build context
 	err = filepath.Walk(f.buildContext, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -195,7 +304,7 @@ func (f *BuilderFactory) GenerateImageHash() (string, error) {
 }
 
 func verifyOptions(opts BuilderFactoryOptions) error {
-	if opts.ImageName == "" {
+	if opts.ImageName == "" && opts.Dockerfile == "" {
 		return ErrImageNameEmpty
 	}
 	if opts.BuildContext == "" {
@@ -207,10 +316,12 @@ func verifyOptions(opts BuilderFactoryOptions) error {
 	return nil
 }
 
-func (f *BuilderFactory) logDebugWithQuotesDisabled(args ...interface{}) {
-	lf := f.logger.Formatter.(*logrus.TextFormatter)
-	qStatus := lf.DisableQuote
-	lf.DisableQuote = true
-	f.logger.Debug(args...)
-	lf.DisableQuote = qStatus
+// buildOutput returns the writer the builder streams the build output of the given image to
+func (f *BuilderFactory) buildOutput(imageName string) *lineWriter {
+	return newLineWriter(func(line string) {
+		f.logger.WithField("image", imageName).Debug(line)
+		if f.buildLogHandler != nil {
+			f.buildLogHandler(line)
+		}
+	})
 }
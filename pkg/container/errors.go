@@ -29,4 +29,6 @@ var (
 	ErrBuildContextEmpty              = errors.New("BuildContextEmpty", "build context is empty")
 	ErrImageBuilderNotSet             = errors.New("ImageBuilderNotSet", "image builder is not set")
 	ErrLoggerEmpty                    = errors.New("LoggerEmpty", "logger is empty")
+	ErrBuildContextNotFound           = errors.New("BuildContextNotFound", "build context directory %s not found")
+	ErrDockerfileNotFound             = errors.New("DockerfileNotFound", "dockerfile %s not found")
 )
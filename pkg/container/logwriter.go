@@ -0,0 +1,46 @@
+package container
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// lineWriter calls a function for every line written to it
+// The last line, if it does not end with a new line, is passed on Flush
+type lineWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	onLine func(line string)
+}
+
+func newLineWriter(onLine func(line string)) *lineWriter {
+	return &lineWriter{onLine: onLine}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		idx := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if idx < 0 {
+			break
+		}
+		line := string(w.buf.Next(idx + 1))
+		w.onLine(strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// Flush passes the remaining partial line, if any
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() != 0 {
+		w.onLine(strings.TrimRight(w.buf.String(), "\r\n"))
+		w.buf.Reset()
+	}
+}
@@ -0,0 +1,21 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := newLineWriter(func(line string) { lines = append(lines, line) })
+
+	_, _ = w.Write([]byte("step 1\r\nstep"))
+	assert.Equal(t, []string{"step 1"}, lines)
+
+	_, _ = w.Write([]byte(" 2\nstep 3"))
+	assert.Equal(t, []string{"step 1", "step 2"}, lines)
+
+	w.Flush()
+	assert.Equal(t, []string{"step 1", "step 2", "step 3"}, lines)
+}
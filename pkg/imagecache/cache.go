@@ -0,0 +1,119 @@
+// Package imagecache provides a persistent cache of the images built by knuu,
+// so identical images are not rebuilt across test runs.
+package imagecache
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+const registryCheckTimeout = 30 * time.Second
+
+// Cache maps build hashes to images, the entries are persisted by a Store
+// An entry expires when the TTL of the registry has passed or when its image is not found in the registry anymore
+// The cache is an optimization: its errors are logged and reported as misses, so the image is rebuilt
+type Cache struct {
+	store    Store
+	registry *builder.Registry
+	logger   *logrus.Logger
+	// imageExists checks the registry, it is replaced in tests
+	imageExists func(ctx context.Context, image string) (bool, error)
+	now         func() time.Time
+}
+
+// New returns a cache persisted by the store, for the images pushed to the registry
+// A nil registry is the default registry
+func New(store Store, registry *builder.Registry, logger *logrus.Logger) *Cache {
+	if registry == nil {
+		registry = builder.DefaultRegistry()
+	}
+	if logger == nil {
+		logger = logrus.New()
+	}
+	client := &http.Client{Timeout: registryCheckTimeout}
+	return &Cache{
+		store:    store,
+		registry: registry,
+		logger:   logger,
+		imageExists: func(ctx context.Context, image string) (bool, error) {
+			return imageExists(ctx, client, registry, image)
+		},
+		now: time.Now,
+	}
+}
+
+// Lookup returns the image built for the hash if it is still available in the registry
+// Expired entries are removed from the store
+func (c *Cache) Lookup(ctx context.Context, hash string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	entry, err := c.store.Get(ctx, c.key(hash))
+	if err != nil {
+		c.logger.WithError(err).WithField("hash", hash).Warn("error reading the image cache")
+		return "", false
+	}
+	if entry == nil {
+		return "", false
+	}
+
+	if c.registry.TTL > 0 && c.now().Sub(entry.CreatedAt) >= c.registry.TTL {
+		c.expire(ctx, hash, entry, "ttl passed")
+		return "", false
+	}
+
+	exists, err := c.imageExists(ctx, entry.Image)
+	if err != nil {
+		// the registry may be temporarily unavailable, keep the entry
+		c.logger.WithError(err).WithField("image", entry.Image).Warn("error checking the cached image in the registry")
+		return "", false
+	}
+	if !exists {
+		c.expire(ctx, hash, entry, "image not found in the registry")
+		return "", false
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"hash":  hash,
+		"image": entry.Image,
+	}).Debug("image found in the persistent cache")
+	return entry.Image, true
+}
+
+// Add stores the image built for the hash
+func (c *Cache) Add(ctx context.Context, hash, image string) {
+	if c == nil {
+		return
+	}
+
+	err := c.store.Set(ctx, c.key(hash), Entry{Image: image, CreatedAt: c.now().UTC()})
+	if err != nil {
+		c.logger.WithError(err).WithField("image", image).Warn("error adding the image to the persistent cache")
+	}
+}
+
+func (c *Cache) expire(ctx context.Context, hash string, entry *Entry, reason string) {
+	c.logger.WithFields(logrus.Fields{
+		"hash":   hash,
+		"image":  entry.Image,
+		"reason": reason,
+	}).Debug("image cache entry expired")
+
+	if err := c.store.Delete(ctx, c.key(hash)); err != nil {
+		c.logger.WithError(err).WithField("hash", hash).Warn("error deleting the expired image cache entry")
+	}
+}
+
+// key returns the key of the hash in the store, which includes the registry host
+// so that the runs pushing to different registries can share the store without using each other's images
+// The port separator is replaced, as the keys of a ConfigMap cannot contain ':'
+func (c *Cache) key(hash string) string {
+	return strings.ReplaceAll(c.registry.Host, ":", "_") + "_" + hash
+}
@@ -0,0 +1,193 @@
+package imagecache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(filepath.Join(t.TempDir(), "cache", "images.json"))
+	require.NoError(t, err)
+
+	stores := map[string]Store{
+		"file":      fileStore,
+		"configmap": NewConfigMapStore(fake.NewSimpleClientset()),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			entry, err := store.Get(ctx, "hash")
+			require.NoError(t, err)
+			assert.Nil(t, entry)
+
+			created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			require.NoError(t, store.Set(ctx, "hash", Entry{Image: "ttl.sh/hash:24h", CreatedAt: created}))
+			require.NoError(t, store.Set(ctx, "other", Entry{Image: "ttl.sh/other:24h", CreatedAt: created}))
+
+			entry, err = store.Get(ctx, "hash")
+			require.NoError(t, err)
+			assert.Equal(t, &Entry{Image: "ttl.sh/hash:24h", CreatedAt: created}, entry)
+
+			require.NoError(t, store.Delete(ctx, "hash"))
+			require.NoError(t, store.Delete(ctx, "hash"))
+			entry, err = store.Get(ctx, "hash")
+			require.NoError(t, err)
+			assert.Nil(t, entry)
+
+			entry, err = store.Get(ctx, "other")
+			require.NoError(t, err)
+			assert.NotNil(t, entry)
+		})
+	}
+}
+
+func TestCacheLookup(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "images.json"))
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing := map[string]bool{}
+	cache := New(store, &builder.Registry{Host: "registry.example.com", TTL: time.Hour}, logrus.New())
+	cache.now = func() time.Time { return now }
+	cache.imageExists = func(_ context.Context, image string) (bool, error) {
+		return existing[image], nil
+	}
+
+	cache.Add(ctx, "a", "registry.example.com/a:1h")
+	cache.Add(ctx, "b", "registry.example.com/b:1h")
+	existing["registry.example.com/a:1h"] = true
+
+	image, ok := cache.Lookup(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "registry.example.com/a:1h", image)
+
+	// the image of b is not in the registry anymore
+	_, ok = cache.Lookup(ctx, "b")
+	assert.False(t, ok)
+	entry, err := store.Get(ctx, cache.key("b"))
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	// the ttl of a has passed
+	now = now.Add(time.Hour)
+	_, ok = cache.Lookup(ctx, "a")
+	assert.False(t, ok)
+	entry, err = store.Get(ctx, cache.key("a"))
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	// the entries of another registry sharing the store are not used
+	existing["localhost:5000/c:latest"] = true
+	otherCache := New(store, &builder.Registry{Host: "localhost:5000"}, logrus.New())
+	otherCache.imageExists = cache.imageExists
+	otherCache.Add(ctx, "c", "localhost:5000/c:latest")
+	_, ok = cache.Lookup(ctx, "c")
+	assert.False(t, ok)
+	image, ok = otherCache.Lookup(ctx, "c")
+	assert.True(t, ok)
+	assert.Equal(t, "localhost:5000/c:latest", image)
+
+	var nilCache *Cache
+	_, ok = nilCache.Lookup(ctx, "a")
+	assert.False(t, ok)
+}
+
+func TestImageExists(t *testing.T) {
+	const token = "secret-token"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "user" || pass != "pass" || r.URL.Query().Get("scope") != "repository:org/app:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprintf(w, `{"token": %q}`, token)
+		case r.Header.Get("Authorization") != "Bearer "+token:
+			w.Header().Set(wwwAuthenticate, fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:org/app:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/org/app/manifests/v1":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	registry := &builder.Registry{
+		Host:     host,
+		Insecure: true,
+		Auth:     &builder.RegistryAuth{Username: "user", Password: "pass"},
+	}
+
+	exists, err := imageExists(context.Background(), server.Client(), registry, host+"/org/app:v1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = imageExists(context.Background(), server.Client(), registry, host+"/org/app:v2")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestAuthorizeErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		challenge string
+		err       error
+	}{
+		{"basic without credentials", `Basic realm="registry"`, ErrRegistryRequiresCredentials},
+		{"unsupported challenge", `Digest realm="registry"`, ErrUnsupportedAuthChallenge},
+		{"missing realm", `Bearer service="registry"`, ErrInvalidTokenRealm},
+		{"token endpoint failure", fmt.Sprintf(`Bearer realm="%s/token"`, server.URL), ErrTokenEndpointStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authorize(context.Background(), server.Client(), tt.challenge, nil)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected imageReference
+	}{
+		{"alpine", imageReference{host: dockerHubHost, repo: "library/alpine", reference: "latest"}},
+		{"celestiaorg/knuu:v1", imageReference{host: dockerHubHost, repo: "celestiaorg/knuu", reference: "v1"}},
+		{"ttl.sh/abc:24h", imageReference{host: "ttl.sh", repo: "abc", reference: "24h"}},
+		{"localhost:5000/org/app", imageReference{host: "localhost:5000", repo: "org/app", reference: "latest"}},
+		{"ghcr.io/org/app@sha256:1234", imageReference{host: "ghcr.io", repo: "org/app", reference: "sha256:1234"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref, err := parseImageReference(tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *ref)
+		})
+	}
+}
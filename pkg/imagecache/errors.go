@@ -0,0 +1,25 @@
+package imagecache
+
+import (
+	"github.com/celestiaorg/knuu/pkg/errors"
+)
+
+type Error = errors.Error
+
+var (
+	ErrReadingCacheFile            = errors.New("ReadingCacheFile", "error reading image cache file %s")
+	ErrWritingCacheFile            = errors.New("WritingCacheFile", "error writing image cache file %s")
+	ErrDecodingCacheEntry          = errors.New("DecodingCacheEntry", "error decoding image cache entry %s")
+	ErrEncodingCacheEntry          = errors.New("EncodingCacheEntry", "error encoding image cache entry %s")
+	ErrGettingCacheConfigMap       = errors.New("GettingCacheConfigMap", "error getting image cache config map %s/%s")
+	ErrUpdatingCacheConfigMap      = errors.New("UpdatingCacheConfigMap", "error updating image cache config map %s/%s")
+	ErrCreatingCacheNamespace      = errors.New("CreatingCacheNamespace", "error creating image cache namespace %s")
+	ErrInvalidImageReference       = errors.New("InvalidImageReference", "invalid image reference %s")
+	ErrCheckingImageInRegistry     = errors.New("CheckingImageInRegistry", "error checking image %s in the registry")
+	ErrUnexpectedRegistryStatus    = errors.New("UnexpectedRegistryStatus", "unexpected status %d from the registry for image %s")
+	ErrGettingRegistryToken        = errors.New("GettingRegistryToken", "error getting a token from the registry for image %s")
+	ErrRegistryRequiresCredentials = errors.New("RegistryRequiresCredentials", "registry requires credentials")
+	ErrUnsupportedAuthChallenge    = errors.New("UnsupportedAuthChallenge", "unsupported authentication challenge %q")
+	ErrInvalidTokenRealm           = errors.New("InvalidTokenRealm", "invalid token realm %q")
+	ErrTokenEndpointStatus         = errors.New("TokenEndpointStatus", "token endpoint returned status %d")
+)
@@ -0,0 +1,222 @@
+package imagecache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+const (
+	dockerHubHost     = "registry-1.docker.io"
+	dockerHubLibrary  = "library/"
+	defaultTag        = "latest"
+	bearerChallenge   = "bearer"
+	basicChallenge    = "basic"
+	wwwAuthenticate   = "WWW-Authenticate"
+	manifestsEndpoint = "%s://%s/v2/%s/manifests/%s"
+)
+
+// manifestMediaTypes are the manifests accepted when checking that an image exists
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// imageReference is a parsed image name, e.g. registry.example.com:5000/org/image:tag
+type imageReference struct {
+	host      string
+	repo      string
+	reference string // tag or digest
+}
+
+func parseImageReference(image string) (*imageReference, error) {
+	ref := &imageReference{}
+	name := image
+
+	if idx := strings.Index(name, "@"); idx >= 0 {
+		ref.reference = name[idx+1:]
+		name = name[:idx]
+	} else if idx := strings.LastIndex(name, ":"); idx > strings.LastIndex(name, "/") {
+		ref.reference = name[idx+1:]
+		name = name[:idx]
+	} else {
+		ref.reference = defaultTag
+	}
+
+	host, repo, found := strings.Cut(name, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		// docker hub image, e.g. alpine or org/image
+		host, repo = dockerHubHost, name
+		if !strings.Contains(repo, "/") {
+			repo = dockerHubLibrary + repo
+		}
+	}
+	if repo == "" || ref.reference == "" {
+		return nil, ErrInvalidImageReference.WithParams(image)
+	}
+	ref.host = host
+	ref.repo = repo
+	return ref, nil
+}
+
+// imageExists checks with the registry API that the manifest of the image exists
+// The credentials and the insecure setting of the registry are used when the image is hosted by it
+func imageExists(ctx context.Context, client *http.Client, registry *builder.Registry, image string) (bool, error) {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return false, err
+	}
+
+	scheme := "https"
+	var auth *builder.RegistryAuth
+	if registry != nil && registry.Host == ref.host {
+		if registry.Insecure {
+			scheme = "http"
+		}
+		auth = registry.Auth
+	}
+	manifestURL := fmt.Sprintf(manifestsEndpoint, scheme, ref.host, ref.repo, ref.reference)
+
+	resp, err := headManifest(ctx, client, manifestURL, "")
+	if err != nil {
+		return false, ErrCheckingImageInRegistry.WithParams(image).Wrap(err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorization, err := authorize(ctx, client, resp.Header.Get(wwwAuthenticate), auth)
+		if err != nil {
+			return false, ErrGettingRegistryToken.WithParams(image).Wrap(err)
+		}
+		resp, err = headManifest(ctx, client, manifestURL, authorization)
+		if err != nil {
+			return false, ErrCheckingImageInRegistry.WithParams(image).Wrap(err)
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, ErrUnexpectedRegistryStatus.WithParams(resp.StatusCode, image)
+	}
+}
+
+func headManifest(ctx context.Context, client *http.Client, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ","))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// authorize answers the challenge of the registry and returns the Authorization header to use
+func authorize(ctx context.Context, client *http.Client, challenge string, auth *builder.RegistryAuth) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case basicChallenge:
+		if auth == nil {
+			return "", ErrRegistryRequiresCredentials
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(auth.Username, auth.Password)
+		return req.Header.Get("Authorization"), nil
+	case bearerChallenge:
+		return bearerToken(ctx, client, params, auth)
+	default:
+		return "", ErrUnsupportedAuthChallenge.WithParams(challenge)
+	}
+}
+
+func bearerToken(ctx context.Context, client *http.Client, params map[string]string, auth *builder.RegistryAuth) (string, error) {
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", ErrInvalidTokenRealm.WithParams(params["realm"])
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if auth != nil {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", ErrTokenEndpointStatus.WithParams(resp.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (scheme string, params map[string]string) {
+	params = map[string]string{}
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	for _, param := range splitChallengeParams(rest) {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			continue
+		}
+		params[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return strings.ToLower(scheme), params
+}
+
+// splitChallengeParams splits the parameters of a challenge on the commas that are not quoted
+func splitChallengeParams(s string) []string {
+	var (
+		params []string
+		quoted bool
+		start  int
+	)
+	for idx, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			params = append(params, s[start:idx])
+			start = idx + 1
+		}
+	}
+	return append(params, s[start:])
+}
@@ -0,0 +1,260 @@
+package imagecache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	DefaultConfigMapNamespace = "knuu-cache"
+	DefaultConfigMapName      = "knuu-image-cache"
+	defaultFileName           = "image-cache.json"
+	cacheDirName              = "knuu"
+)
+
+// Entry maps the hash of a build (Dockerfile and build context) to the image built from it
+type Entry struct {
+	Image     string    `json:"image"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Store persists the cache entries, keyed by build hash
+type Store interface {
+	// Get returns the entry of the hash, or nil if there is none
+	Get(ctx context.Context, hash string) (*Entry, error)
+	Set(ctx context.Context, hash string, entry Entry) error
+	Delete(ctx context.Context, hash string) error
+}
+
+// FileStore stores the entries in a local JSON file, e.g. to share the cache between the runs on a CI runner
+type FileStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+var _ Store = &FileStore{}
+
+// NewFileStore returns a store backed by the given file, an empty path means <user cache dir>/knuu/image-cache.json
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(cacheDir, cacheDirName, defaultFileName)
+	}
+	return &FileStore{Path: path}, nil
+}
+
+func (s *FileStore) Get(_ context.Context, hash string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := entries[hash]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (s *FileStore) Set(_ context.Context, hash string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	entries[hash] = entry
+	return s.write(entries)
+}
+
+func (s *FileStore) Delete(_ context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[hash]; !ok {
+		return nil
+	}
+	delete(entries, hash)
+	return s.write(entries)
+}
+
+func (s *FileStore) read() (map[string]Entry, error) {
+	entries := map[string]Entry{}
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, ErrReadingCacheFile.WithParams(s.Path).Wrap(err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, ErrDecodingCacheEntry.WithParams(s.Path).Wrap(err)
+	}
+	return entries, nil
+}
+
+// write replaces the file atomically, so a concurrent reader never sees a partial file
+func (s *FileStore) write(entries map[string]Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return ErrEncodingCacheEntry.WithParams(s.Path).Wrap(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return ErrWritingCacheFile.WithParams(s.Path).Wrap(err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return ErrWritingCacheFile.WithParams(s.Path).Wrap(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return ErrWritingCacheFile.WithParams(s.Path).Wrap(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return ErrWritingCacheFile.WithParams(s.Path).Wrap(err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return ErrWritingCacheFile.WithParams(s.Path).Wrap(err)
+	}
+	return nil
+}
+
+// ConfigMapStore stores the entries in a ConfigMap, so all the runs against a cluster share the cache
+// The ConfigMap lives in its own namespace, as the namespace of a test is deleted when it is cleaned up
+type ConfigMapStore struct {
+	Clientset kubernetes.Interface
+	Namespace string
+	Name      string
+}
+
+var _ Store = &ConfigMapStore{}
+
+// NewConfigMapStore returns a store backed by the knuu-cache/knuu-image-cache ConfigMap
+func NewConfigMapStore(clientset kubernetes.Interface) *ConfigMapStore {
+	return &ConfigMapStore{
+		Clientset: clientset,
+		Namespace: DefaultConfigMapNamespace,
+		Name:      DefaultConfigMapName,
+	}
+}
+
+func (s *ConfigMapStore) Get(ctx context.Context, hash string) (*Entry, error) {
+	cm, err := s.Clientset.CoreV1().ConfigMaps(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ErrGettingCacheConfigMap.WithParams(s.Namespace, s.Name).Wrap(err)
+	}
+
+	data, ok := cm.Data[hash]
+	if !ok {
+		return nil, nil
+	}
+	var entry Entry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return nil, ErrDecodingCacheEntry.WithParams(hash).Wrap(err)
+	}
+	return &entry, nil
+}
+
+func (s *ConfigMapStore) Set(ctx context.Context, hash string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return ErrEncodingCacheEntry.WithParams(hash).Wrap(err)
+	}
+	return s.update(ctx, func(cm *v1.ConfigMap) bool {
+		cm.Data[hash] = string(data)
+		return true
+	})
+}
+
+func (s *ConfigMapStore) Delete(ctx context.Context, hash string) error {
+	return s.update(ctx, func(cm *v1.ConfigMap) bool {
+		if _, ok := cm.Data[hash]; !ok {
+			return false
+		}
+		delete(cm.Data, hash)
+		return true
+	})
+}
+
+// update applies the mutation to the ConfigMap, creating it and its namespace if needed,
+// and retries on conflicts with concurrent runs
+func (s *ConfigMapStore) update(ctx context.Context, mutate func(cm *v1.ConfigMap) (changed bool)) error {
+	if err := s.ensureNamespace(ctx); err != nil {
+		return err
+	}
+
+	configMaps := s.Clientset.CoreV1().ConfigMaps(s.Namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(ctx, s.Name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: s.Name, Namespace: s.Namespace},
+				Data:       map[string]string{},
+			}
+			if !mutate(cm) {
+				return nil
+			}
+			_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+			if apierrs.IsAlreadyExists(err) {
+				// created by a concurrent run, retry the update
+				return apierrs.NewConflict(v1.Resource("configmaps"), s.Name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		if !mutate(cm) {
+			return nil
+		}
+		_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return ErrUpdatingCacheConfigMap.WithParams(s.Namespace, s.Name).Wrap(err)
+	}
+	return nil
+}
+
+func (s *ConfigMapStore) ensureNamespace(ctx context.Context) error {
+	namespaces := s.Clientset.CoreV1().Namespaces()
+	if _, err := namespaces.Get(ctx, s.Namespace, metav1.GetOptions{}); err == nil {
+		return nil
+	}
+
+	_, err := namespaces.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: s.Namespace}}, metav1.CreateOptions{})
+	if err != nil && !apierrs.IsAlreadyExists(err) {
+		return ErrCreatingCacheNamespace.WithParams(s.Namespace).Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package instance
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+// Attach rebuilds the handle of an instance from the pod template of a workload that is already running in the cluster
+// The returned instance is in the state 'Started', so it can be used to execute commands, get the logs, stop or destroy it
+// The containers of the pod other than the instance container are attached as sidecars
+// Note: the sources of the files are not available anymore, only their destinations and permissions are restored
+func Attach(
+	ctx context.Context,
+	sysDeps *system.SystemDependencies,
+	workloadType WorkloadType,
+	replicas int32,
+	template v1.PodTemplateSpec,
+) (*Instance, error) {
+	name := template.Labels[LabelNameKey]
+	if name == "" {
+		return nil, ErrAttachingInstanceWithoutName
+	}
+
+	i, err := New(name, sysDeps)
+	if err != nil {
+		return nil, ErrAttachingInstance.WithParams(name).Wrap(err)
+	}
+	i.instanceType = instanceTypeFromString(template.Labels[LabelTypeKey])
+	i.execution.workloadType = workloadType
+	if replicas > 0 {
+		i.resources.replicas = replicas
+	}
+	i.build.nodeSelector = template.Spec.NodeSelector
+
+	if err := i.applyPodSpec(template.Spec); err != nil {
+		return nil, ErrAttachingInstance.WithParams(name).Wrap(err)
+	}
+
+	svc, err := i.K8sClient.GetService(ctx, name)
+	if err != nil && !apierrs.IsNotFound(err) {
+		return nil, ErrGettingService.WithParams(name).Wrap(err)
+	}
+	if err == nil {
+		i.network.kubernetesService = svc
+	}
+
+	role, err := i.K8sClient.GetRole(ctx, name)
+	if err != nil && !apierrs.IsNotFound(err) {
+		return nil, ErrGettingRole.WithParams(name).Wrap(err)
+	}
+	if err == nil {
+		i.security.policyRules = role.Rules
+	}
+
+	if err := i.network.attachNetworkPolicy(ctx); err != nil {
+		return nil, err
+	}
+	if err := i.storage.attachVolumeClaim(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, container := range template.Spec.Containers {
+		if container.Name == name {
+			continue
+		}
+		sc, err := New(container.Name, sysDeps)
+		if err != nil {
+			return nil, ErrAttachingSidecar.WithParams(container.Name, name).Wrap(err)
+		}
+		if err := sc.applyPodSpec(template.Spec); err != nil {
+			return nil, ErrAttachingSidecar.WithParams(container.Name, name).Wrap(err)
+		}
+		sc.sidecars.isSidecar = true
+		sc.parentInstance = i
+		if err := sc.storage.attachVolumeClaim(ctx); err != nil {
+			return nil, ErrAttachingSidecar.WithParams(container.Name, name).Wrap(err)
+		}
+		sc.state = StateStarted
+		i.sidecars.sidecars = append(i.sidecars.sidecars, &attachedSidecar{instance: sc})
+	}
+
+	i.SetState(StateStarted)
+	i.Logger.WithFields(logrus.Fields{
+		"instance": name,
+		"workload": workloadType.String(),
+		"sidecars": len(i.sidecars.sidecars),
+	}).Debug("attached to instance")
+	return i, nil
+}
+
+// applyPodSpec restores the configuration of the instance from the container with the same name in the given pod spec
+func (i *Instance) applyPodSpec(spec v1.PodSpec) error {
+	config, err := k8s.ContainerConfigFromPodSpec(spec, i.name)
+	if err != nil {
+		return err
+	}
+
+	i.build.imageName = config.Image
+	i.build.imagePullPolicy = config.ImagePullPolicy
+	if config.Command != nil {
+		i.build.command = config.Command
+	}
+	if config.Args != nil {
+		i.build.args = config.Args
+	}
+	i.build.env = config.Env
+	i.build.attachedSecretEnv = config.SecretEnv
+
+	i.resources.memoryRequest = config.MemoryRequest
+	i.resources.memoryLimit = config.MemoryLimit
+	i.resources.cpuRequest = config.CPURequest
+
+	i.monitoring.livenessProbe = config.LivenessProbe
+	i.monitoring.readinessProbe = config.ReadinessProbe
+	i.monitoring.startupProbe = config.StartupProbe
+
+	if sc := config.SecurityContext; sc != nil {
+		i.security.privileged = sc.Privileged != nil && *sc.Privileged
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				i.security.capabilitiesAdd = append(i.security.capabilitiesAdd, string(capability))
+			}
+		}
+	}
+
+	i.network.portsTCP = append(i.network.portsTCP, config.TCPPorts...)
+	i.network.portsUDP = append(i.network.portsUDP, config.UDPPorts...)
+
+	i.storage.volumes = append(i.storage.volumes, config.Volumes...)
+	i.storage.files = append(i.storage.files, config.Files...)
+	for _, sv := range config.SharedVolumes {
+		i.storage.sharedVolumes = append(i.storage.sharedVolumes, &sharedVolumeMount{
+			volume:   sharedVolumeFromK8s(sv, i.SystemDependencies),
+			path:     sv.Path,
+			readOnly: sv.ReadOnly,
+		})
+	}
+	return nil
+}
+
+// attachedSidecar is a sidecar rebuilt from a running workload
+// The original implementation of the sidecar is not known, so it only wraps the instance of the sidecar
+type attachedSidecar struct {
+	instance *Instance
+}
+
+var _ SidecarManager = &attachedSidecar{}
+
+func (a *attachedSidecar) Initialize(ctx context.Context, namePrefix string, sysDeps *system.SystemDependencies) error {
+	return nil
+}
+
+func (a *attachedSidecar) Instance() *Instance {
+	return a.instance
+}
+
+func (a *attachedSidecar) PreStart(ctx context.Context) error {
+	return nil
+}
+
+func (a *attachedSidecar) Clone(namePrefix string) (SidecarManager, error) {
+	suffix := strings.TrimPrefix(a.instance.name, a.instance.parentInstance.name)
+	clone, err := a.instance.CloneWithName(namePrefix + suffix)
+	if err != nil {
+		return nil, err
+	}
+	return &attachedSidecar{instance: clone}, nil
+}
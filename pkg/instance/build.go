@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
@@ -15,6 +16,8 @@ import (
 	"github.com/celestiaorg/knuu/pkg/container"
 )
 
+const defaultDockerfile = "Dockerfile"
+
 type build struct {
 	instance        *Instance
 	imageName       string
@@ -26,6 +29,13 @@ type build struct {
 	imageCache      *sync.Map
 	buildDir        string
 	nodeSelector    map[string]string
+	// registryCredentials are the credentials used to pull the image of the instance
+	registryCredentials []*builder.Registry
+	platforms           []string
+	// secretEnv are the environment variables read from the secret of the instance
+	secretEnv map[string]string
+	// attachedSecretEnv are the environment variables read from the secret of an attached instance, whose values are not known
+	attachedSecretEnv []string
 }
 
 func (i *Instance) Build() *build {
@@ -65,7 +75,11 @@ func (b *build) SetImage(ctx context.Context, image string, args ...builder.ArgI
 		BuildContext: buildDir,
 		ImageBuilder: b.instance.ImageBuilder,
 		Args:         args,
+		Registry:     b.instance.Registry,
 		Logger:       b.instance.Logger,
+
+		RegistryCredentials: b.builderRegistryCredentials(),
+		Platforms:           b.platforms,
 	})
 	if err != nil {
 		return ErrCreatingBuilder.Wrap(err)
@@ -87,7 +101,7 @@ func (b *build) SetGitRepo(ctx context.Context, gitContext builder.GitContext, a
 	if err != nil {
 		return ErrGettingBuildContext.Wrap(err)
 	}
-	imageName, err := builder.DefaultImageName(bCtx)
+	imageName, err := builder.DefaultImageNameWithRegistry(bCtx, b.instance.Registry)
 	if err != nil {
 		return ErrGettingImageName.Wrap(err)
 	}
@@ -102,7 +116,11 @@ func (b *build) SetGitRepo(ctx context.Context, gitContext builder.GitContext, a
 		BuildContext: buildDir,
 		ImageBuilder: b.instance.ImageBuilder,
 		Args:         args,
+		Registry:     b.instance.Registry,
 		Logger:       b.instance.Logger,
+
+		RegistryCredentials: b.builderRegistryCredentials(),
+		Platforms:           b.platforms,
 	})
 	if err != nil {
 		return ErrCreatingBuilder.Wrap(err)
@@ -114,6 +132,64 @@ func (b *build) SetGitRepo(ctx context.Context, gitContext builder.GitContext, a
 	return b.builderFactory.BuildImageFromGitRepo(ctx, gitContext, imageName)
 }
 
+// SetDockerfile builds the image from the Dockerfile at dockerfilePath with the given build context directory,
+// the image is built and pushed to the registry on Commit
+// dockerfilePath is relative to contextDir (empty means contextDir/Dockerfile) and must be inside of it
+// target is the stage of a multi-stage Dockerfile to build, empty means the last stage
+// The Dockerfile and the content of contextDir are part of the image hash, so unchanged sources reuse the cached image
+// The generated instructions (e.g. ExecuteCommand, SetUser) cannot be added to a Dockerfile build
+// This function can only be called in the state 'None'
+func (b *build) SetDockerfile(ctx context.Context, contextDir, dockerfilePath, target string, args ...builder.ArgInterface) error {
+	if !b.instance.IsState(StateNone) {
+		return ErrSettingDockerfileNotAllowed.WithParams(b.instance.state.String())
+	}
+
+	contextDir, err := filepath.Abs(contextDir)
+	if err != nil {
+		return ErrInvalidDockerfilePath.WithParams(contextDir).Wrap(err)
+	}
+	if dockerfilePath == "" {
+		dockerfilePath = defaultDockerfile
+	}
+	if filepath.IsAbs(dockerfilePath) {
+		dockerfilePath, err = filepath.Rel(contextDir, dockerfilePath)
+		if err != nil {
+			return ErrInvalidDockerfilePath.WithParams(dockerfilePath).Wrap(err)
+		}
+	}
+	dockerfilePath = filepath.Clean(dockerfilePath)
+	if dockerfilePath == ".." || strings.HasPrefix(dockerfilePath, ".."+string(filepath.Separator)) {
+		return ErrDockerfileOutsideContext.WithParams(dockerfilePath, contextDir)
+	}
+
+	factory, err := container.NewBuilderFactory(container.BuilderFactoryOptions{
+		BuildContext: contextDir,
+		ImageBuilder: b.instance.ImageBuilder,
+		Args:         args,
+		Registry:     b.instance.Registry,
+		Logger:       b.instance.Logger,
+
+		RegistryCredentials: b.builderRegistryCredentials(),
+		Platforms:           b.platforms,
+		Dockerfile:          dockerfilePath,
+		Target:              target,
+	})
+	if err != nil {
+		return ErrCreatingBuilder.Wrap(err)
+	}
+	b.builderFactory = factory
+
+	b.instance.Logger.WithFields(logrus.Fields{
+		"instance":   b.instance.name,
+		"context":    contextDir,
+		"dockerfile": dockerfilePath,
+		"target":     target,
+	}).Debug("set dockerfile for instance")
+
+	b.instance.SetState(StatePreparing)
+	return nil
+}
+
 // SetStartCommand sets the command to run in the instance
 // This function can only be called when the instance is in state 'Preparing' or 'Committed'
 func (b *build) SetStartCommand(command ...string) error {
@@ -140,6 +216,9 @@ func (b *build) ExecuteCommand(command ...string) error {
 	if b.instance.state != StatePreparing {
 		return ErrAddingCommandNotAllowed.WithParams(b.instance.state.String())
 	}
+	if b.isDockerfileBuild() {
+		return ErrModifyingDockerfileBuildNotAllowed.WithParams(b.instance.name)
+	}
 
 	b.builderFactory.AddCmdToBuilder(command)
 	return nil
@@ -151,6 +230,9 @@ func (b *build) SetUser(user string) error {
 	if !b.instance.IsState(StatePreparing) {
 		return ErrSettingUserNotAllowed.WithParams(b.instance.state.String())
 	}
+	if b.isDockerfileBuild() {
+		return ErrModifyingDockerfileBuildNotAllowed.WithParams(b.instance.name)
+	}
 
 	b.builderFactory.SetUser(user)
 	b.instance.Logger.WithFields(logrus.Fields{
@@ -169,13 +251,119 @@ func (b *build) SetNodeSelector(nodeSelector map[string]string) error {
 	return nil
 }
 
+// SetPlatforms sets the platforms the image of the instance is built for, e.g. linux/arm64
+// Several platforms produce a manifest list if the image builder supports it
+// With a single platform, the pods of the instance are pinned to the nodes of this OS and architecture
+// To build an image from a git repo for other platforms, this function must be called before SetGitRepo
+// This function can only be called in the states 'None' and 'Preparing'
+func (b *build) SetPlatforms(platforms ...string) error {
+	if !b.instance.IsInState(StateNone, StatePreparing) {
+		return ErrSettingPlatformsNotAllowed.WithParams(b.instance.state.String())
+	}
+	if err := builder.ValidatePlatforms(platforms); err != nil {
+		return ErrInvalidPlatforms.WithParams(b.instance.name).Wrap(err)
+	}
+
+	b.platforms = platforms
+	if b.builderFactory != nil {
+		b.builderFactory.SetPlatforms(platforms)
+	}
+	b.instance.Logger.WithFields(logrus.Fields{
+		"instance":  b.instance.name,
+		"platforms": platforms,
+	}).Debug("set platforms for instance")
+	return nil
+}
+
+// Platforms returns the platforms the image of the instance is built for
+func (b *build) Platforms() []string {
+	return b.platforms
+}
+
+// podNodeSelector returns the node selector of the pods, including the OS and architecture of a single platform
+// unless they are set by the node selector of the instance
+func (b *build) podNodeSelector() map[string]string {
+	if len(b.platforms) != 1 {
+		return b.nodeSelector
+	}
+	platformSelector, err := builder.PlatformNodeSelector(b.platforms[0])
+	if err != nil {
+		// the platforms are validated when they are set
+		return b.nodeSelector
+	}
+
+	nodeSelector := make(map[string]string, len(b.nodeSelector)+len(platformSelector))
+	for k, v := range platformSelector {
+		nodeSelector[k] = v
+	}
+	for k, v := range b.nodeSelector {
+		nodeSelector[k] = v
+	}
+	return nodeSelector
+}
+
+// AddRegistryCredentials adds the credentials of a private registry the image of the instance is pulled from
+// The credentials are stored in an image pull secret of the instance, in addition to the image pull secret of the scope
+// They are also used by the image builder, e.g. to pull a private base image, when added before Commit
+// This function can only be called in the states 'None', 'Preparing', 'Committed' and 'Stopped'
+func (b *build) AddRegistryCredentials(registry *builder.Registry) error {
+	if !b.instance.IsInState(StateNone, StatePreparing, StateCommitted, StateStopped) {
+		return ErrAddingRegistryCredentialsNotAllowed.WithParams(b.instance.state.String())
+	}
+	if registry == nil || registry.Auth == nil {
+		return ErrRegistryCredentialsWithoutAuth.WithParams(b.instance.name)
+	}
+	if err := registry.Validate(); err != nil {
+		return ErrInvalidRegistryCredentials.WithParams(b.instance.name).Wrap(err)
+	}
+
+	b.registryCredentials = append(b.registryCredentials, registry)
+	if b.builderFactory != nil {
+		b.builderFactory.SetRegistryCredentials(b.builderRegistryCredentials())
+	}
+	b.instance.Logger.WithFields(logrus.Fields{
+		"instance": b.instance.name,
+		"registry": registry.Host,
+		// the credentials are not logged to avoid leaking sensitive information
+	}).Debug("added registry credentials for instance")
+	return nil
+}
+
+// builderRegistryCredentials returns the credentials of the scope and of the instance, which the image builder
+// needs to pull the private base images of the instance
+func (b *build) builderRegistryCredentials() []*builder.Registry {
+	registries := append([]*builder.Registry(nil), b.instance.RegistryCredentials...)
+	return append(registries, b.registryCredentials...)
+}
+
+// CommitOption configures the commit of an instance
+type CommitOption func(*commitOptions)
+
+type commitOptions struct {
+	buildLogHandler func(line string)
+}
+
+// WithBuildLogHandler calls the handler with every line of the build output while the image is built,
+// e.g. to show the progress of a long build. The lines are also logged at debug level.
+func WithBuildLogHandler(handler func(line string)) CommitOption {
+	return func(o *commitOptions) {
+		o.buildLogHandler = handler
+	}
+}
+
 // Commit commits the instance
+// The image is built and pushed if needed, the build output is streamed to the logger and the optional handler
 // This function can only be called in the state 'Preparing'
-func (b *build) Commit(ctx context.Context) error {
+func (b *build) Commit(ctx context.Context, options ...CommitOption) error {
 	if !b.instance.IsState(StatePreparing) {
 		return ErrCommittingNotAllowed.WithParams(b.instance.state.String())
 	}
 
+	var opts commitOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
 	if !b.builderFactory.Changed() {
 		b.imageName = b.builderFactory.ImageNameFrom()
 		b.instance.Logger.WithFields(logrus.Fields{
@@ -193,7 +381,7 @@ func (b *build) Commit(ctx context.Context) error {
 		return ErrGeneratingImageHash.Wrap(err)
 	}
 
-	imageName, err := getImageRegistry(imageHash)
+	imageName, err := getImageRegistry(b.instance.Registry, imageHash)
 	if err != nil {
 		return ErrGettingImageRegistry.Wrap(err)
 	}
@@ -212,14 +400,30 @@ func (b *build) Commit(ctx context.Context) error {
 		return nil
 	}
 
+	// Check the persistent cache, which survives the test runs
+	if cachedImageName, exists := b.instance.ImageCache.Lookup(ctx, imageHash); exists {
+		b.updateImageCacheWithHash(imageHash, cachedImageName)
+		b.imageName = cachedImageName
+
+		b.instance.Logger.WithFields(logrus.Fields{
+			"instance": b.instance.name,
+			"image":    b.imageName,
+		}).Debugf("using persistently cached image for instance")
+
+		b.instance.SetState(StateCommitted)
+		return nil
+	}
+
 	b.instance.Logger.WithFields(logrus.Fields{
 		"instance": b.instance.name,
 	}).Debugf("cannot use any cached image for instance")
+	b.builderFactory.SetBuildLogHandler(opts.buildLogHandler)
 	err = b.builderFactory.PushBuilderImage(ctx, imageName)
 	if err != nil {
 		return ErrPushingImage.WithParams(b.instance.name).Wrap(err)
 	}
 	b.updateImageCacheWithHash(imageHash, imageName)
+	b.instance.ImageCache.Add(ctx, imageHash, imageName)
 	b.imageName = imageName
 
 	b.instance.Logger.WithFields(logrus.Fields{
@@ -231,17 +435,17 @@ func (b *build) Commit(ctx context.Context) error {
 	return nil
 }
 
-// getImageRegistry returns the name of the temporary image registry
-func getImageRegistry(imageName string) (string, error) {
+// getImageRegistry returns the name of the image in the given registry, a nil registry is the default registry
+func getImageRegistry(registry *builder.Registry, imageName string) (string, error) {
 	if imageName == "" {
-		// If not already set, generate a random name using ttl.sh
+		// If not already set, generate a random name
 		uuid, err := uuid.NewRandom()
 		if err != nil {
 			return "", fmt.Errorf("error generating UUID: %w", err)
 		}
 		imageName = uuid.String()
 	}
-	return fmt.Sprintf("ttl.sh/%s:24h", imageName), nil
+	return registry.ImageName(imageName), nil
 }
 
 // getBuildDir returns the build directory for the instance
@@ -257,6 +461,11 @@ func (b *build) getBuildDir() (string, error) {
 	return filepath.Join(tmpDir, b.instance.name), nil
 }
 
+// isDockerfileBuild returns true if the image of the instance is built from an existing Dockerfile
+func (b *build) isDockerfileBuild() bool {
+	return b.builderFactory != nil && b.builderFactory.IsDockerfileBuild()
+}
+
 // addFileToBuilder adds a file to the builder
 func (b *build) addFileToBuilder(src, dest, chown string) {
 	// dest is the same as src here, as we copy the file to the build dir with the subfolder structure of dest
@@ -276,7 +485,8 @@ func (b *build) SetEnvironmentVariable(key, value string) error {
 		// value is not logged to avoid leaking sensitive information
 	}).Debugf("Setting environment variable")
 
-	if b.instance.state == StatePreparing {
+	// the variables of a Dockerfile build are set on the container, as its Dockerfile cannot be modified
+	if b.instance.state == StatePreparing && !b.isDockerfileBuild() {
 		b.builderFactory.SetEnvVar(key, value)
 		return nil
 	}
@@ -315,6 +525,14 @@ func (b *build) clone() *build {
 		envCopy[k] = v
 	}
 
+	var secretEnvCopy map[string]string
+	if b.secretEnv != nil {
+		secretEnvCopy = make(map[string]string, len(b.secretEnv))
+		for k, v := range b.secretEnv {
+			secretEnvCopy[k] = v
+		}
+	}
+
 	var imageCacheClone sync.Map
 	// Clone the imageCache if it exists
 	if b.imageCache != nil {
@@ -338,5 +556,10 @@ func (b *build) clone() *build {
 		args:       argsCopy,
 		env:        envCopy,
 		imageCache: &imageCacheClone,
+
+		registryCredentials: append([]*builder.Registry(nil), b.registryCredentials...),
+		platforms:           append([]string(nil), b.platforms...),
+		secretEnv:           secretEnvCopy,
+		attachedSecretEnv:   append([]string(nil), b.attachedSecretEnv...),
 	}
 }
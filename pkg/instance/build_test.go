@@ -0,0 +1,137 @@
+package instance
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+func TestSetPlatforms(t *testing.T) {
+	sysDeps := newTestSystemDependencies(t)
+
+	i, err := New("arm", sysDeps)
+	require.NoError(t, err)
+	assert.ErrorIs(t, i.Build().SetPlatforms("arm64"), ErrInvalidPlatforms)
+	require.NoError(t, i.Build().SetPlatforms("linux/arm64"))
+	require.NoError(t, i.Build().SetImage(context.Background(), "alpine:latest"))
+	assert.Equal(t, []string{"linux/arm64"}, i.Build().builderFactory.Platforms())
+
+	require.NoError(t, i.Build().SetNodeSelector(map[string]string{"pool": "arm"}))
+	require.NoError(t, i.Build().Commit(context.Background()))
+	assert.ErrorIs(t, i.Build().SetPlatforms("linux/amd64"), ErrSettingPlatformsNotAllowed)
+
+	assert.Equal(t, map[string]string{
+		"pool":                "arm",
+		builder.NodeOSLabel:   "linux",
+		builder.NodeArchLabel: "arm64",
+	}, i.Execution().preparePodConfig().NodeSelector)
+
+	multi, err := New("multi", sysDeps)
+	require.NoError(t, err)
+	require.NoError(t, multi.Build().SetPlatforms("linux/amd64", "linux/arm64"))
+	assert.Nil(t, multi.Build().podNodeSelector(), "a manifest list runs on any of its platforms")
+}
+
+// fakeBuilder records the options of the builds instead of building the images
+type fakeBuilder struct {
+	builds []*builder.BuilderOptions
+}
+
+func (f *fakeBuilder) Build(_ context.Context, b *builder.BuilderOptions) (string, error) {
+	f.builds = append(f.builds, b)
+	logs := "step 1/2\nstep 2/2"
+	if b.Output != nil {
+		_, _ = b.Output.Write([]byte(logs))
+	}
+	return logs, nil
+}
+
+func TestSetDockerfile(t *testing.T) {
+	ctx := context.Background()
+	imageBuilder := &fakeBuilder{}
+	sysDeps := newTestSystemDependencies(t)
+	sysDeps.ImageBuilder = imageBuilder
+
+	contextDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(contextDir, "build"), 0755))
+	dockerfile := "FROM alpine AS base\nFROM base AS app\n"
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "build", "Dockerfile"), []byte(dockerfile), 0644))
+
+	newDockerfileInstance := func(name, target string) *Instance {
+		i, err := New(name, sysDeps)
+		require.NoError(t, err)
+		require.NoError(t, i.Build().SetDockerfile(ctx, contextDir, "build/Dockerfile", target))
+		return i
+	}
+
+	i := newDockerfileInstance("app", "app")
+	assert.ErrorIs(t, i.Build().ExecuteCommand("echo"), ErrModifyingDockerfileBuildNotAllowed)
+	assert.ErrorIs(t, i.Build().SetUser("nobody"), ErrModifyingDockerfileBuildNotAllowed)
+	require.NoError(t, i.Build().SetEnvironmentVariable("FOO", "bar"))
+	assert.Equal(t, "bar", i.Build().env["FOO"])
+	require.NoError(t, i.Build().Commit(ctx))
+
+	require.Len(t, imageBuilder.builds, 1)
+	assert.Equal(t, "build/Dockerfile", imageBuilder.builds[0].Dockerfile)
+	assert.Equal(t, "app", imageBuilder.builds[0].Target)
+	assert.Equal(t, builder.DirContext{Path: contextDir}.BuildContext(), imageBuilder.builds[0].BuildContext)
+	assert.Equal(t, imageBuilder.builds[0].Destination, i.Build().ImageName())
+
+	// the image name is the hash of the sources, another target is another image
+	same := newDockerfileInstance("same", "app")
+	require.NoError(t, same.Build().Commit(ctx))
+	assert.Equal(t, i.Build().ImageName(), same.Build().ImageName())
+
+	base := newDockerfileInstance("base", "base")
+	require.NoError(t, base.Build().Commit(ctx))
+	assert.NotEqual(t, i.Build().ImageName(), base.Build().ImageName())
+
+	outside, err := New("outside", sysDeps)
+	require.NoError(t, err)
+	assert.ErrorIs(t, outside.Build().SetDockerfile(ctx, contextDir, "../Dockerfile", ""), ErrDockerfileOutsideContext)
+	assert.ErrorIs(t, outside.Build().SetDockerfile(ctx, contextDir, "missing/Dockerfile", ""), ErrCreatingBuilder)
+}
+
+func TestCommitWithBuildLogHandler(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	sysDeps.ImageBuilder = &fakeBuilder{}
+
+	i, err := New("logs", sysDeps)
+	require.NoError(t, err)
+	require.NoError(t, i.Build().SetImage(ctx, "alpine:latest"))
+	require.NoError(t, i.Build().ExecuteCommand("echo", "hello"))
+
+	var lines []string
+	require.NoError(t, i.Build().Commit(ctx, WithBuildLogHandler(func(line string) {
+		lines = append(lines, line)
+	})))
+	assert.Equal(t, []string{"step 1/2", "step 2/2"}, lines)
+}
+
+func TestBuildRegistryCredentials(t *testing.T) {
+	ctx := context.Background()
+	imageBuilder := &fakeBuilder{}
+	sysDeps := newTestSystemDependencies(t)
+	sysDeps.ImageBuilder = imageBuilder
+	scopeCredentials := &builder.Registry{Host: "scope.example.com", Auth: &builder.RegistryAuth{Username: "scope", Password: "secret"}}
+	sysDeps.RegistryCredentials = []*builder.Registry{scopeCredentials}
+
+	i, err := New("private", sysDeps)
+	require.NoError(t, err)
+	require.NoError(t, i.Build().SetImage(ctx, "private.example.com/base:latest"))
+	// the credentials added after the image is set are used by the build as well
+	instanceCredentials := &builder.Registry{Host: "private.example.com", Auth: &builder.RegistryAuth{Username: "user", Password: "secret"}}
+	require.NoError(t, i.Build().AddRegistryCredentials(instanceCredentials))
+	require.NoError(t, i.Build().ExecuteCommand("echo", "hello"))
+	require.NoError(t, i.Build().Commit(ctx))
+
+	require.Len(t, imageBuilder.builds, 1)
+	assert.Equal(t, []*builder.Registry{scopeCredentials, instanceCredentials}, imageBuilder.builds[0].RegistryCredentials)
+}
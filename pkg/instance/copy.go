@@ -0,0 +1,192 @@
+package instance
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CopyFrom copies the file or folder at the absolute path remotePath of the instance to localPath, with the permissions of the remote files.
+// A remote folder is copied with its content to the folder localPath.
+// If the instance is started, the files are copied from its first replica, otherwise from its image through a temporary instance.
+// The files are streamed as a tar archive over exec, so the container must have a tar binary.
+// This function can only be called in the states 'Preparing', 'Committed' and 'Started'
+func (s *storage) CopyFrom(ctx context.Context, remotePath, localPath string) error {
+	if !s.instance.IsInState(StatePreparing, StateCommitted, StateStarted) {
+		return ErrCopyingFromNotAllowed.WithParams(s.instance.state.String())
+	}
+	if remotePath == "" {
+		return ErrSrcMustBeSet
+	}
+	if localPath == "" {
+		return ErrDestMustBeSet
+	}
+	if !path.IsAbs(remotePath) {
+		return ErrRemotePathMustBeAbsolute.WithParams(remotePath)
+	}
+	remotePath = path.Clean(remotePath)
+
+	var (
+		files int
+		err   error
+	)
+	if s.instance.state == StateStarted {
+		files, err = s.copyFromInstance(ctx, remotePath, localPath)
+	} else {
+		err = s.withImageInstance(ctx, func(ti *Instance) error {
+			var err error
+			files, err = ti.storage.copyFromInstance(ctx, remotePath, localPath)
+			return err
+		})
+	}
+	if err != nil {
+		return ErrCopyingFromInstance.WithParams(remotePath, s.instance.name).Wrap(err)
+	}
+
+	s.instance.Logger.WithFields(logrus.Fields{
+		"instance": s.instance.name,
+		"remote":   remotePath,
+		"local":    localPath,
+		"files":    files,
+	}).Debug("copied files from instance")
+	return nil
+}
+
+// copyFromInstance streams remotePath out of the first pod of the started instance and extracts it to localPath
+func (s *storage) copyFromInstance(ctx context.Context, remotePath, localPath string) (int, error) {
+	pod, err := s.instance.execution.firstPod(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	dir, base := path.Split(remotePath)
+	if base == "" {
+		// the root folder is archived as "."
+		dir, base = "/", "."
+	}
+	cmd := []string{"tar", "-cf", "-", "-C", dir, base}
+
+	pr, pw := io.Pipe()
+	streamErr := make(chan error, 1)
+	go func() {
+		err := s.instance.K8sClient.StreamCommandInPod(ctx, pod.Name, s.instance.name, cmd, nil, pw)
+		pw.CloseWithError(err)
+		streamErr <- err
+	}()
+
+	files, err := extractCopyArchive(pr, base, localPath)
+	if err == nil {
+		// drain the end of the archive to get the exit status of tar
+		_, err = io.Copy(io.Discard, pr)
+	}
+	pr.Close()
+	sErr := <-streamErr
+	if err != nil {
+		return 0, err
+	}
+	if sErr != nil {
+		return 0, sErr
+	}
+	return files, nil
+}
+
+// extractCopyArchive extracts the tar archive of the remote entry base to localPath and returns the number of extracted files.
+// Only the folders and regular files are extracted, the permissions of the folders are set once their content is extracted.
+func extractCopyArchive(r io.Reader, base, localPath string) (int, error) {
+	type dirMode struct {
+		path string
+		mode os.FileMode
+	}
+	var (
+		dirs  []dirMode
+		files int
+	)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return files, err
+		}
+
+		rel, err := copyArchiveRelPath(hdr.Name, base)
+		if err != nil {
+			return files, err
+		}
+		target := filepath.Join(localPath, filepath.FromSlash(rel))
+		mode := os.FileMode(hdr.Mode).Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return files, err
+			}
+			dirs = append(dirs, dirMode{path: target, mode: mode})
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return files, err
+			}
+			if err := copyFromTar(tr, target, mode); err != nil {
+				return files, err
+			}
+			files++
+		default:
+			// symlinks, hard links and special files are not copied
+		}
+	}
+
+	// the deepest folders come last in the archive
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// copyArchiveRelPath returns the path of the archive entry name relative to the archived entry base
+func copyArchiveRelPath(name, base string) (string, error) {
+	name = path.Clean(name)
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", ErrInvalidArchiveEntry.WithParams(name)
+	}
+	if base == "." {
+		if name == "." {
+			return "", nil
+		}
+		return name, nil
+	}
+	if name == base {
+		return "", nil
+	}
+	rel, ok := strings.CutPrefix(name, base+"/")
+	if !ok {
+		return "", ErrInvalidArchiveEntry.WithParams(name)
+	}
+	return rel, nil
+}
+
+func copyFromTar(tr *tar.Reader, target string, mode os.FileMode) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, tr); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// the permissions of an existing file are not changed by OpenFile and new files are subject to the umask
+	return os.Chmod(target, mode)
+}
@@ -0,0 +1,119 @@
+package instance
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+// tarStreamer streams a fixed tar archive as the output of the commands run in the pods
+type tarStreamer struct {
+	k8s.KubeManager
+	archive []byte
+	cmd     []string
+}
+
+func (s *tarStreamer) StreamCommandInPod(_ context.Context, _, _ string, cmd []string, _ io.Reader, stdout io.Writer) error {
+	s.cmd = cmd
+	_, err := stdout.Write(s.archive)
+	return err
+}
+
+func newCopyTestInstance(t *testing.T, archive []byte) (*Instance, *tarStreamer) {
+	sysDeps := newTestSystemDependencies(t)
+	streamer := &tarStreamer{KubeManager: sysDeps.K8sClient, archive: archive}
+	sysDeps.K8sClient = streamer
+
+	i := newStartedTestInstance(t, sysDeps, "copy")
+	addTestReplicaSetPods(t, i, "copy-0")
+	return i, streamer
+}
+
+func TestCopyFromFolder(t *testing.T) {
+	remote := t.TempDir()
+	binary := []byte{0x00, 0xff, 0x10, 0x00, 0x7f}
+	require.NoError(t, os.MkdirAll(filepath.Join(remote, "db"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(remote, "db", "blocks.bin"), binary, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(remote, "run.sh"), []byte("#!/bin/sh"), 0o755))
+
+	entries, err := collectSyncEntries(remote, "data")
+	require.NoError(t, err)
+	archive := syncArchiveBytes(t, entries, 0, 0)
+
+	i, streamer := newCopyTestInstance(t, archive)
+	local := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, i.Storage().CopyFrom(context.Background(), "/home/node/data/", local))
+	assert.Equal(t, []string{"tar", "-cf", "-", "-C", "/home/node/", "data"}, streamer.cmd)
+
+	content, err := os.ReadFile(filepath.Join(local, "db", "blocks.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, binary, content)
+
+	info, err := os.Stat(filepath.Join(local, "db", "blocks.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(local, "run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(local, "db"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+}
+
+func TestCopyFromFile(t *testing.T) {
+	remote := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(remote, []byte(`{"chain_id":"test"}`), 0o640))
+
+	entries, err := collectSyncEntries(remote, "genesis.json")
+	require.NoError(t, err)
+	archive := syncArchiveBytes(t, entries, 0, 0)
+
+	i, _ := newCopyTestInstance(t, archive)
+	local := filepath.Join(t.TempDir(), "out", "genesis.json")
+	require.NoError(t, i.Storage().CopyFrom(context.Background(), "/config/genesis.json", local))
+
+	content, err := os.ReadFile(local)
+	require.NoError(t, err)
+	assert.Equal(t, `{"chain_id":"test"}`, string(content))
+	info, err := os.Stat(local)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
+
+func TestCopyFromRejectsEntriesOutsideLocalPath(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "data/../../evil", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	i, _ := newCopyTestInstance(t, buf.Bytes())
+	local := filepath.Join(t.TempDir(), "data")
+	err = i.Storage().CopyFrom(context.Background(), "/data", local)
+	assert.ErrorIs(t, err, ErrCopyingFromInstance)
+	assert.ErrorContains(t, err, "invalid archive entry")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(local), "evil"))
+}
+
+func TestCopyFromValidation(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+
+	i, err := New("none", sysDeps)
+	require.NoError(t, err)
+	assert.ErrorIs(t, i.Storage().CopyFrom(ctx, "/data", t.TempDir()), ErrCopyingFromNotAllowed)
+
+	started := newStartedTestInstance(t, sysDeps, "started")
+	assert.ErrorIs(t, started.Storage().CopyFrom(ctx, "data", t.TempDir()), ErrRemotePathMustBeAbsolute)
+	assert.ErrorIs(t, started.Storage().CopyFrom(ctx, "/data", ""), ErrDestMustBeSet)
+}
@@ -233,4 +233,93 @@ var (
 	ErrFileTooLargeCommitted                     = errors.New("FileTooLargeCommitted", "file '%s' is too large (max 1MiB) to add after instance is committed")
 	ErrTotalFilesSizeTooLarge                    = errors.New("TotalFilesSizeTooLarge", "total files size is too large (max 1MiB)")
 	ErrFailedToCheckPersistentVolumeClaim        = errors.New("FailedToCheckPersistentVolumeClaim", "failed to check persistent volume claim")
+	ErrSettingReplicasNotAllowed                 = errors.New("SettingReplicasNotAllowed", "setting replicas is only allowed in state 'Preparing', 'Committed' or 'Stopped'. Current state is '%s'")
+	ErrSettingReplicasNotAllowedForSidecar       = errors.New("SettingReplicasNotAllowedForSidecar", "setting replicas is not allowed for sidecar '%s', set it on the parent instance")
+	ErrInvalidReplicas                           = errors.New("InvalidReplicas", "number of replicas must be at least 1, got %d")
+	ErrReplicasShareVolume                       = errors.New("ReplicasShareVolume", "the %d replicas of the ReplicaSet of instance '%s' cannot share its ReadWriteOnce volumes, use the StatefulSet workload or WithAccessMode(ReadWriteMany)")
+	ErrScalingNotAllowed                         = errors.New("ScalingNotAllowed", "scaling is only allowed in state 'Started'. Current state is '%s'")
+	ErrScalingSidecarNotAllowed                  = errors.New("ScalingSidecarNotAllowed", "scaling is not allowed for sidecar '%s', scale the parent instance instead")
+	ErrScalingInstance                           = errors.New("ScalingInstance", "error scaling instance '%s' to %d replicas")
+	ErrGettingPodsNotAllowed                     = errors.New("GettingPodsNotAllowed", "getting pods is only allowed in state 'Started'. Current state is '%s'")
+	ErrGettingPodsFromReplicaSet                 = errors.New("GettingPodsFromReplicaSet", "error getting pods of instance '%s'")
+	ErrReplicaIndexOutOfRange                    = errors.New("ReplicaIndexOutOfRange", "replica index %d is out of range for instance '%s' with %d running replicas")
+	ErrGettingLogsNotAllowed                     = errors.New("GettingLogsNotAllowed", "getting logs of a replica is only allowed in state 'Started'. Current state is '%s'")
+	ErrSettingWorkloadTypeNotAllowed             = errors.New("SettingWorkloadTypeNotAllowed", "setting workload type is only allowed in state 'Preparing' or 'Committed'. Current state is '%s'")
+	ErrSettingWorkloadTypeNotAllowedForSidecar   = errors.New("SettingWorkloadTypeNotAllowedForSidecar", "setting workload type is not allowed for sidecar '%s', set it on the parent instance")
+	ErrInvalidWorkloadType                       = errors.New("InvalidWorkloadType", "invalid workload type '%s'")
+	ErrGettingPodsFromStatefulSet                = errors.New("GettingPodsFromStatefulSet", "error getting pods from statefulset '%s'")
+	ErrNoPodsForInstance                         = errors.New("NoPodsForInstance", "no running pods found for instance '%s'")
+	ErrScalingJobNotAllowed                      = errors.New("ScalingJobNotAllowed", "scaling is not allowed for instance '%s' with the Job workload type")
+	ErrWaitingForCompletionNotAllowed            = errors.New("WaitingForCompletionNotAllowed", "waiting for completion is only allowed in state 'Started'. Current state is '%s'")
+	ErrWaitingForCompletionNotAllowedForWorkload = errors.New("WaitingForCompletionNotAllowedForWorkload", "waiting for completion is only allowed for Job instances, instance '%s' has the workload type '%s'")
+	ErrWaitingForJobCompletion                   = errors.New("WaitingForJobCompletion", "error waiting for the job of instance '%s' to complete")
+	ErrGettingPodsFromJob                        = errors.New("GettingPodsFromJob", "error getting pods from job '%s'")
+	ErrContainerNotTerminated                    = errors.New("ContainerNotTerminated", "container of instance '%s' in pod '%s' has not terminated")
+	ErrGettingJobLogs                            = errors.New("GettingJobLogs", "error getting the logs of the job of instance '%s'")
+	ErrAttachingInstanceWithoutName              = errors.New("AttachingInstanceWithoutName", "cannot attach to a workload without the 'knuu.sh/name' label")
+	ErrAttachingInstance                         = errors.New("AttachingInstance", "error attaching to instance '%s'")
+	ErrAttachingSidecar                          = errors.New("AttachingSidecar", "error attaching to sidecar '%s' of instance '%s'")
+	ErrGettingRole                               = errors.New("GettingRole", "error getting role '%s'")
+	ErrInvalidGroupConcurrency                   = errors.New("InvalidGroupConcurrency", "concurrency of a group must be at least 1, got %d")
+	ErrInvalidReadinessTimeout                   = errors.New("InvalidReadinessTimeout", "readiness timeout of a group must be positive, got '%s'")
+	ErrGroupInstanceIsNil                        = errors.New("GroupInstanceIsNil", "group contains a nil instance")
+	ErrGroupDependencyCycle                      = errors.New("GroupDependencyCycle", "dependency cycle detected in group at instance '%s'")
+	ErrStartingGroupMember                       = errors.New("StartingGroupMember", "error starting instance '%s' of group")
+	ErrCheckingDependencyReadiness               = errors.New("CheckingDependencyReadiness", "error checking readiness of dependency '%s' of instance '%s'")
+	ErrDependencyNotReady                        = errors.New("DependencyNotReady", "dependency '%s' of instance '%s' did not become ready within %s")
+	ErrPartitionGroupEmpty                       = errors.New("PartitionGroupEmpty", "both groups of a partition must contain at least one instance")
+	ErrPartitionInstanceIsNil                    = errors.New("PartitionInstanceIsNil", "partition group contains a nil instance")
+	ErrPartitioningNotAllowed                    = errors.New("PartitioningNotAllowed", "partitioning is only allowed in state 'Started', instance '%s' is in state '%s'")
+	ErrPartitioningSidecarNotAllowed             = errors.New("PartitioningSidecarNotAllowed", "partitioning is not allowed for sidecar '%s', partition the parent instance instead")
+	ErrInstanceInBothPartitionGroups             = errors.New("InstanceInBothPartitionGroups", "instance '%s' is part of both groups of the partition")
+	ErrApplyingPartitionPolicy                   = errors.New("ApplyingPartitionPolicy", "error applying partition network policy of instance '%s'")
+	ErrDeletingPartitionPolicy                   = errors.New("DeletingPartitionPolicy", "error deleting partition network policy of instance '%s'")
+	ErrSettingNetworkPolicyNotAllowed            = errors.New("SettingNetworkPolicyNotAllowed", "setting network policy is only allowed in states 'Preparing', 'Committed', 'Started' and 'Stopped'. Current state is '%s'")
+	ErrSettingNetworkPolicyNotAllowedForSidecar  = errors.New("SettingNetworkPolicyNotAllowedForSidecar", "setting network policy is not allowed for sidecar '%s', set it on the parent instance instead")
+	ErrApplyingNetworkPolicyNotAllowed           = errors.New("ApplyingNetworkPolicyNotAllowed", "applying network policy is only allowed in state 'Started'. Current state is '%s'")
+	ErrApplyingNetworkPolicy                     = errors.New("ApplyingNetworkPolicy", "error applying network policy of instance '%s'")
+	ErrDeletingNetworkPolicy                     = errors.New("DeletingNetworkPolicy", "error deleting network policy of instance '%s'")
+	ErrGettingNetworkPolicy                      = errors.New("GettingNetworkPolicy", "error getting network policy of instance '%s'")
+	ErrGettingPersistentVolumeClaim              = errors.New("GettingPersistentVolumeClaim", "error getting persistent volume claim of instance '%s'")
+	ErrAddingRegistryCredentialsNotAllowed       = errors.New("AddingRegistryCredentialsNotAllowed", "adding registry credentials is only allowed in states 'None', 'Preparing', 'Committed' and 'Stopped'. Current state is '%s'")
+	ErrRegistryCredentialsWithoutAuth            = errors.New("RegistryCredentialsWithoutAuth", "registry credentials of instance '%s' must have an auth")
+	ErrInvalidRegistryCredentials                = errors.New("InvalidRegistryCredentials", "invalid registry credentials for instance '%s'")
+	ErrCreatingImagePullSecret                   = errors.New("CreatingImagePullSecret", "error creating image pull secret of instance '%s'")
+	ErrDeletingImagePullSecret                   = errors.New("DeletingImagePullSecret", "error deleting image pull secret of instance '%s'")
+	ErrSettingPlatformsNotAllowed                = errors.New("SettingPlatformsNotAllowed", "setting platforms is only allowed in states 'None' and 'Preparing'. Current state is '%s'")
+	ErrInvalidPlatforms                          = errors.New("InvalidPlatforms", "invalid platforms for instance '%s'")
+	ErrSettingDockerfileNotAllowed               = errors.New("SettingDockerfileNotAllowed", "setting dockerfile is only allowed in state 'None'. Current state is '%s'")
+	ErrInvalidDockerfilePath                     = errors.New("InvalidDockerfilePath", "invalid dockerfile path '%s'")
+	ErrDockerfileOutsideContext                  = errors.New("DockerfileOutsideContext", "dockerfile '%s' must be inside the build context '%s'")
+	ErrSyncingFilesNotAllowed                    = errors.New("SyncingFilesNotAllowed", "syncing files is only allowed in state 'Started'. Current state is '%s'")
+	ErrSyncDestMustBeAbsolute                    = errors.New("SyncDestMustBeAbsolute", "sync destination '%s' must be an absolute path")
+	ErrSyncingFiles                              = errors.New("SyncingFiles", "error syncing '%s' into instance '%s'")
+	ErrModifyingDockerfileBuildNotAllowed        = errors.New("ModifyingDockerfileBuildNotAllowed", "the image of instance '%s' is built from a dockerfile, which cannot be modified, change the dockerfile instead")
+	ErrCopyingFromNotAllowed                     = errors.New("CopyingFromNotAllowed", "copying from instance is only allowed in states 'Preparing', 'Committed' and 'Started'. Current state is '%s'")
+	ErrRemotePathMustBeAbsolute                  = errors.New("RemotePathMustBeAbsolute", "remote path '%s' must be an absolute path")
+	ErrCopyingFromInstance                       = errors.New("CopyingFromInstance", "error copying '%s' from instance '%s'")
+	ErrInvalidArchiveEntry                       = errors.New("InvalidArchiveEntry", "invalid archive entry '%s'")
+	ErrUploadingFile                             = errors.New("UploadingFile", "error uploading file '%s' to minio")
+	ErrDeletingUploadedFile                      = errors.New("DeletingUploadedFile", "error deleting uploaded file '%s' from minio")
+	ErrSharedVolumeNameRequired                  = errors.New("SharedVolumeNameRequired", "shared volume name is required")
+	ErrInvalidSharedVolumeSize                   = errors.New("InvalidSharedVolumeSize", "size of shared volume '%s' must be greater than zero")
+	ErrSharedVolumeRequired                      = errors.New("SharedVolumeRequired", "shared volume is required")
+	ErrAddingSharedVolumeNotAllowed              = errors.New("AddingSharedVolumeNotAllowed", "adding shared volume is only allowed in states 'Preparing', 'Committed' and 'Stopped'. Current state is '%s'")
+	ErrSharedVolumeAlreadyAdded                  = errors.New("SharedVolumeAlreadyAdded", "shared volume '%s' is already added to instance '%s'")
+	ErrDeployingSharedVolume                     = errors.New("DeployingSharedVolume", "error deploying shared volume '%s'")
+	ErrDestroyingSharedVolume                    = errors.New("DestroyingSharedVolume", "error destroying shared volume '%s'")
+	ErrAddingSecretFileNotAllowed                = errors.New("AddingSecretFileNotAllowed", "adding secret file is only allowed in states 'Preparing', 'Committed' and 'Stopped'. Current state is '%s'")
+	ErrSettingSecretEnvNotAllowed                = errors.New("SettingSecretEnvNotAllowed", "setting secret environment variable is only allowed in states 'Preparing', 'Committed' and 'Stopped'. Current state is '%s'")
+	ErrInvalidSecretEnvName                      = errors.New("InvalidSecretEnvName", "invalid secret environment variable name '%s'")
+	ErrCreatingSecret                            = errors.New("CreatingSecret", "error creating secret of instance '%s'")
+	ErrDeletingSecret                            = errors.New("DeletingSecret", "error deleting secret of instance '%s'")
+	ErrTakingSnapshotNotAllowed                  = errors.New("TakingSnapshotNotAllowed", "taking snapshot is only allowed in state 'Stopped'. Current state is '%s'")
+	ErrSnapshotRequiresVolume                    = errors.New("SnapshotRequiresVolume", "instance '%s' has no persistent volume to snapshot")
+	ErrSnapshotStatefulSetNotSupported           = errors.New("SnapshotStatefulSetNotSupported", "snapshots of the volumes of the statefulSet instance '%s' are not supported")
+	ErrTakingSnapshot                            = errors.New("TakingSnapshot", "error taking snapshot '%s' of instance '%s'")
+	ErrAddingVolumeFromSnapshotNotAllowed        = errors.New("AddingVolumeFromSnapshotNotAllowed", "adding volume from snapshot is only allowed in states 'Preparing' and 'Committed'. Current state is '%s'")
+	ErrSnapshotNameRequired                      = errors.New("SnapshotNameRequired", "snapshot name is required")
+	ErrRestoringSnapshot                         = errors.New("RestoringSnapshot", "error restoring snapshot '%s' in instance '%s'")
+	ErrDeployingHeadlessService                  = errors.New("DeployingHeadlessService", "error deploying headless service for instance '%s'")
+	ErrDestroyingHeadlessService                 = errors.New("DestroyingHeadlessService", "error destroying headless service for instance '%s'")
 )
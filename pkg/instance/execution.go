@@ -2,6 +2,7 @@ package instance
 
 import (
 	"context"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -9,21 +10,25 @@ import (
 	"github.com/celestiaorg/knuu/pkg/k8s"
 
 	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 )
 
+const labelAppKey = "app"
+
+// The labels set by knuu on the k8s resources of the instances, which other packages use to find them
 const (
-	labelAppKey         = "app"
-	labelManagedByKey   = "k8s.kubernetes.io/managed-by"
-	labelScopeKey       = "knuu.sh/scope"
-	labelTestStartedKey = "knuu.sh/test-started"
-	labelNameKey        = "knuu.sh/name"
-	labelK8sNameKey     = "knuu.sh/k8s-name"
-	labelTypeKey        = "knuu.sh/type"
-	labelKnuuValue      = "knuu"
+	LabelManagedByKey   = "k8s.kubernetes.io/managed-by"
+	LabelScopeKey       = "knuu.sh/scope"
+	LabelTestStartedKey = "knuu.sh/test-started"
+	LabelNameKey        = "knuu.sh/name"
+	LabelK8sNameKey     = "knuu.sh/k8s-name"
+	LabelTypeKey        = "knuu.sh/type"
+	LabelKnuuValue      = "knuu"
 )
 
 type execution struct {
-	instance *Instance
+	instance     *Instance
+	workloadType WorkloadType
 }
 
 func (i *Instance) Execution() *execution {
@@ -37,31 +42,109 @@ func (e *execution) ExecuteCommand(ctx context.Context, command ...string) (stri
 		return "", ErrExecutingCommandNotAllowed.WithParams(e.instance.state.String())
 	}
 
-	var (
-		instanceName  string
-		eErr          *Error
-		containerName = e.instance.name
-	)
+	pod, err := e.firstPod(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return e.executeCommandInPod(ctx, pod.Name, command...)
+}
+
+// SetWorkloadType sets the kind of kubernetes workload the instance is deployed as
+// A sidecar always runs in the workload of its parent instance
+// This function can only be called in the states 'Preparing' and 'Committed'
+func (e *execution) SetWorkloadType(workloadType WorkloadType) error {
+	if !e.instance.IsInState(StatePreparing, StateCommitted) {
+		return ErrSettingWorkloadTypeNotAllowed.WithParams(e.instance.state.String())
+	}
+	if e.instance.sidecars.IsSidecar() {
+		return ErrSettingWorkloadTypeNotAllowedForSidecar.WithParams(e.instance.name)
+	}
+	switch workloadType {
+	case ReplicaSetWorkload, StatefulSetWorkload, JobWorkload:
+	default:
+		return ErrInvalidWorkloadType.WithParams(workloadType.String())
+	}
+	e.workloadType = workloadType
+	e.instance.Logger.WithFields(logrus.Fields{
+		"instance": e.instance.name,
+		"workload": workloadType.String(),
+	}).Debug("set workload type for instance")
+	return nil
+}
 
+// WorkloadType returns the kind of kubernetes workload the instance is deployed as
+// For a sidecar, the workload type of the parent instance is returned
+func (e *execution) WorkloadType() WorkloadType {
 	if e.instance.sidecars.isSidecar {
-		instanceName = e.instance.parentInstance.name
-		eErr = ErrExecutingCommandInSidecar.WithParams(command, e.instance.name, e.instance.parentInstance.name)
-	} else {
-		instanceName = e.instance.name
-		eErr = ErrExecutingCommandInInstance.WithParams(command, e.instance.name)
+		return e.instance.parentInstance.execution.workloadType
 	}
+	return e.workloadType
+}
 
-	pod, err := e.instance.K8sClient.GetFirstPodFromReplicaSet(ctx, instanceName)
-	if err != nil {
-		return "", ErrGettingPodFromReplicaSet.WithParams(e.instance.name).Wrap(err)
+// ExecuteCommandInReplica executes the given command in the replica with the given index
+// The replica index is the position of the pod in the list returned by Pods()
+// This function can only be called in the states 'Started'
+func (e *execution) ExecuteCommandInReplica(ctx context.Context, replica int, command ...string) (string, error) {
+	if e.instance.state != StateStarted {
+		return "", ErrExecutingCommandNotAllowed.WithParams(e.instance.state.String())
 	}
 
-	commandWithShell := []string{"/bin/sh", "-c", strings.Join(command, " ")}
-	output, err := e.instance.K8sClient.RunCommandInPod(ctx, pod.Name, containerName, commandWithShell)
+	pod, err := e.replicaPod(ctx, replica)
 	if err != nil {
-		return "", eErr.Wrap(err)
+		return "", err
 	}
-	return output, nil
+
+	return e.executeCommandInPod(ctx, pod.Name, command...)
+}
+
+// Pods returns the pods of all replicas of the instance, sorted by their replica index
+// For a StatefulSet workload, the replica index is the ordinal of the pod
+// For a sidecar, the pods of the parent instance are returned
+// This function can only be called in the state 'Started'
+func (e *execution) Pods(ctx context.Context) ([]v1.Pod, error) {
+	if !e.instance.IsInState(StateStarted) {
+		return nil, ErrGettingPodsNotAllowed.WithParams(e.instance.state.String())
+	}
+
+	return e.workloadPods(ctx)
+}
+
+// Scale changes the number of replicas of a started instance
+// This function can only be called in the state 'Started'
+func (e *execution) Scale(ctx context.Context, replicas int32) error {
+	if !e.instance.IsInState(StateStarted) {
+		return ErrScalingNotAllowed.WithParams(e.instance.state.String())
+	}
+	if e.instance.sidecars.IsSidecar() {
+		return ErrScalingSidecarNotAllowed.WithParams(e.instance.name)
+	}
+	if replicas < 1 {
+		return ErrInvalidReplicas.WithParams(replicas)
+	}
+	if err := e.instance.resources.verifyReplicasVolumes(replicas); err != nil {
+		return err
+	}
+
+	var scale func(ctx context.Context, name string, replicas int32) error
+	switch e.workloadType {
+	case StatefulSetWorkload:
+		scale = e.instance.K8sClient.ScaleStatefulSet
+	case JobWorkload:
+		return ErrScalingJobNotAllowed.WithParams(e.instance.name)
+	default:
+		scale = e.instance.K8sClient.ScaleReplicaSet
+	}
+	if err := scale(ctx, e.instance.name, replicas); err != nil {
+		return ErrScalingInstance.WithParams(e.instance.name, replicas).Wrap(err)
+	}
+	e.instance.resources.replicas = replicas
+
+	e.instance.Logger.WithFields(logrus.Fields{
+		"instance": e.instance.name,
+		"replicas": replicas,
+	}).Debug("scaled instance")
+	return nil
 }
 
 // StartWithCallback starts the instance asynchronously and calls a callback function when the instance is running
@@ -107,12 +190,21 @@ func (e *execution) StartAsync(ctx context.Context) error {
 		return ErrStartingSidecarNotAllowed
 	}
 
+	// the volumes or the workload type may have changed since the replicas were set
+	if err := e.instance.resources.verifyReplicasVolumes(e.instance.resources.replicas); err != nil {
+		return err
+	}
+
 	if e.instance.state == StateCommitted {
 		if err := e.deployResourcesForCommittedState(ctx); err != nil {
 			return ErrDeployingResourcesForInstance.WithParams(e.instance.name).Wrap(err)
 		}
 	}
 
+	if err := e.instance.network.applyNetworkPolicy(ctx); err != nil {
+		return err
+	}
+
 	if err := e.deployPod(ctx); err != nil {
 		return ErrDeployingPodForInstance.WithParams(e.instance.name).Wrap(err)
 	}
@@ -136,13 +228,81 @@ func (e *execution) Start(ctx context.Context) error {
 }
 
 // IsRunning returns true if the instance is running
+// For a Job workload, the instance is also considered running once it has completed
 // This function can only be called in the state 'Started'
 func (e *execution) IsRunning(ctx context.Context) (bool, error) {
 	if !e.instance.IsInState(StateStarted, StateStopped) {
 		return false, ErrCheckingIfInstanceRunningNotAllowed.WithParams(e.instance.state.String())
 	}
 
-	return e.instance.K8sClient.IsReplicaSetRunning(ctx, e.instance.name)
+	switch e.workloadType {
+	case StatefulSetWorkload:
+		return e.instance.K8sClient.IsStatefulSetRunning(ctx, e.instance.name)
+	case JobWorkload:
+		return e.instance.K8sClient.IsJobStarted(ctx, e.instance.name)
+	default:
+		return e.instance.K8sClient.IsReplicaSetRunning(ctx, e.instance.name)
+	}
+}
+
+// WaitForCompletion waits until the Job of the instance has completed and returns its result
+// The result is taken from the last pod created by the Job
+// This function can only be called in the state 'Started' and for instances with the Job workload type
+func (e *execution) WaitForCompletion(ctx context.Context) (*JobResult, error) {
+	if !e.instance.IsInState(StateStarted) {
+		return nil, ErrWaitingForCompletionNotAllowed.WithParams(e.instance.state.String())
+	}
+	if e.WorkloadType() != JobWorkload {
+		return nil, ErrWaitingForCompletionNotAllowedForWorkload.WithParams(e.instance.name, e.WorkloadType().String())
+	}
+
+	job, err := e.instance.K8sClient.WaitForJobCompletion(ctx, e.workloadName())
+	if err != nil {
+		return nil, ErrWaitingForJobCompletion.WithParams(e.instance.name).Wrap(err)
+	}
+
+	pods, err := e.instance.K8sClient.GetPodsFromJob(ctx, e.workloadName())
+	if err != nil {
+		return nil, ErrGettingPodsFromJob.WithParams(e.workloadName()).Wrap(err)
+	}
+	if len(pods) == 0 {
+		return nil, ErrNoPodsForInstance.WithParams(e.instance.name)
+	}
+	pod := pods[len(pods)-1]
+
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	result := &JobResult{
+		Succeeded: job.Status.Succeeded >= completions,
+		PodName:   pod.Name,
+	}
+	terminated := containerTerminatedState(&pod, e.instance.name)
+	if terminated == nil {
+		return nil, ErrContainerNotTerminated.WithParams(e.instance.name, pod.Name)
+	}
+	result.ExitCode = terminated.ExitCode
+	result.Message = terminated.Message
+
+	logs, err := e.instance.K8sClient.GetPodLogStream(ctx, pod.Name, e.instance.name)
+	if err != nil {
+		return nil, ErrGettingJobLogs.WithParams(e.instance.name).Wrap(err)
+	}
+	defer logs.Close()
+
+	logBytes, err := io.ReadAll(logs)
+	if err != nil {
+		return nil, ErrGettingJobLogs.WithParams(e.instance.name).Wrap(err)
+	}
+	result.Logs = string(logBytes)
+
+	e.instance.Logger.WithFields(logrus.Fields{
+		"instance":  e.instance.name,
+		"exit_code": result.ExitCode,
+		"succeeded": result.Succeeded,
+	}).Debug("job of instance completed")
+	return result, nil
 }
 
 // WaitInstanceIsRunning waits until the instance is running
@@ -200,6 +360,7 @@ func (e *execution) WaitInstanceIsStopped(ctx context.Context) error {
 
 // Stop stops the instance
 // CAUTION: In order to keep data of the instance, you need to use AddVolume() before.
+// For a StatefulSet workload, the volumes of all replicas are kept until the instance is destroyed.
 // This function can only be called in the state 'Started'
 func (e *execution) Stop(ctx context.Context) error {
 	if !e.instance.IsInState(StateStarted) {
@@ -224,12 +385,12 @@ func (b *execution) SetImage(ctx context.Context, image string) error {
 func (e *execution) Labels() map[string]string {
 	return map[string]string{
 		labelAppKey:         e.instance.name,
-		labelManagedByKey:   labelKnuuValue,
-		labelScopeKey:       e.instance.Scope,
-		labelTestStartedKey: e.instance.StartTime,
-		labelNameKey:        e.instance.name,
-		labelK8sNameKey:     e.instance.name,
-		labelTypeKey:        e.instance.instanceType.String(),
+		LabelManagedByKey:   LabelKnuuValue,
+		LabelScopeKey:       e.instance.Scope,
+		LabelTestStartedKey: e.instance.StartTime,
+		LabelNameKey:        e.instance.name,
+		LabelK8sNameKey:     e.instance.name,
+		LabelTypeKey:        e.instance.instanceType.String(),
 	}
 }
 
@@ -314,6 +475,21 @@ func (e *execution) deployPod(ctx context.Context) error {
 		return ErrFailedToCreateServiceAccount.Wrap(err)
 	}
 
+	// create the image pull secret for the pod if the instance or its sidecars have registry credentials
+	if err := e.deployImagePullSecret(ctx); err != nil {
+		return err
+	}
+
+	// create the secrets of the instance and its sidecars
+	if err := e.deploySecrets(ctx); err != nil {
+		return err
+	}
+
+	// create the shared volumes mounted by the instance or its sidecars if they do not exist yet
+	if err := e.deploySharedVolumes(ctx); err != nil {
+		return err
+	}
+
 	// create a role and role binding for the pod if there are policy rules
 	if len(e.instance.security.policyRules) > 0 {
 		if err := e.instance.K8sClient.CreateRole(ctx, e.instance.name, labels, e.instance.security.policyRules); err != nil {
@@ -324,24 +500,47 @@ func (e *execution) deployPod(ctx context.Context) error {
 		}
 	}
 
-	// Deploy the statefulSet
-	replicaSet, err := e.instance.K8sClient.CreateReplicaSet(ctx, e.prepareReplicaSetConfig(), true)
-	if err != nil {
-		return ErrFailedToDeployPod.Wrap(err)
+	switch e.workloadType {
+	case StatefulSetWorkload:
+		statefulSet, err := e.instance.K8sClient.CreateStatefulSet(ctx, e.prepareStatefulSetConfig(), true)
+		if err != nil {
+			return ErrFailedToDeployPod.Wrap(err)
+		}
+		e.instance.kubernetesStatefulSet = statefulSet
+	case JobWorkload:
+		job, err := e.instance.K8sClient.CreateJob(ctx, e.prepareJobConfig(), true)
+		if err != nil {
+			return ErrFailedToDeployPod.Wrap(err)
+		}
+		e.instance.kubernetesJob = job
+	default:
+		replicaSet, err := e.instance.K8sClient.CreateReplicaSet(ctx, e.prepareReplicaSetConfig(), true)
+		if err != nil {
+			return ErrFailedToDeployPod.Wrap(err)
+		}
+		e.instance.kubernetesReplicaSet = replicaSet
 	}
 
-	// Set the state of the instance to started
-	e.instance.kubernetesReplicaSet = replicaSet
-
 	// Log the deployment of the pod
-	e.instance.Logger.WithField("instance", e.instance.name).Debugf("started statefulSet")
+	e.instance.Logger.WithFields(logrus.Fields{
+		"instance": e.instance.name,
+		"workload": e.workloadType.String(),
+	}).Debugf("started workload")
 	return nil
 }
 
 // destroyPod destroys the pod for the instance (no grace period)
 // Skips if the pod is already destroyed
 func (e *execution) destroyPod(ctx context.Context) error {
-	err := e.instance.K8sClient.DeleteReplicaSetWithGracePeriod(ctx, e.instance.name, nil)
+	var err error
+	switch e.workloadType {
+	case StatefulSetWorkload:
+		err = e.instance.K8sClient.DeleteStatefulSetWithGracePeriod(ctx, e.instance.name, nil)
+	case JobWorkload:
+		err = e.instance.K8sClient.DeleteJob(ctx, e.instance.name)
+	default:
+		err = e.instance.K8sClient.DeleteReplicaSetWithGracePeriod(ctx, e.instance.name, nil)
+	}
 	if err != nil {
 		return ErrFailedToDeletePod.Wrap(err)
 	}
@@ -351,6 +550,13 @@ func (e *execution) destroyPod(ctx context.Context) error {
 		return ErrFailedToDeleteServiceAccount.Wrap(err)
 	}
 
+	if err := e.destroyImagePullSecret(ctx); err != nil {
+		return err
+	}
+	if err := e.destroySecrets(ctx); err != nil {
+		return err
+	}
+
 	// Delete the role and role binding for the pod if there are policy rules
 	if len(e.instance.security.policyRules) == 0 {
 		return nil
@@ -366,8 +572,45 @@ func (e *execution) destroyPod(ctx context.Context) error {
 	return nil
 }
 
-// prepareConfig prepares the config for the instance
+// prepareReplicaSetConfig prepares the ReplicaSet config for the instance
 func (e *execution) prepareReplicaSetConfig() k8s.ReplicaSetConfig {
+	return k8s.ReplicaSetConfig{
+		Namespace: e.instance.K8sClient.Namespace(),
+		Name:      e.instance.name,
+		Labels:    e.Labels(),
+		Replicas:  e.instance.resources.replicas,
+		PodConfig: e.preparePodConfig(),
+	}
+}
+
+// prepareStatefulSetConfig prepares the StatefulSet config for the instance
+// The headless service of the instance is used as the governing service of the StatefulSet
+func (e *execution) prepareStatefulSetConfig() k8s.StatefulSetConfig {
+	return k8s.StatefulSetConfig{
+		Namespace:   e.instance.K8sClient.Namespace(),
+		Name:        e.instance.name,
+		Labels:      e.Labels(),
+		Replicas:    e.instance.resources.replicas,
+		ServiceName: e.instance.network.headlessServiceName(),
+		PodConfig:   e.preparePodConfig(),
+	}
+}
+
+// prepareJobConfig prepares the Job config for the instance
+// Every replica runs once; a failed pod is not retried so that its exit code can be retrieved
+func (e *execution) prepareJobConfig() k8s.JobConfig {
+	return k8s.JobConfig{
+		Namespace:    e.instance.K8sClient.Namespace(),
+		Name:         e.instance.name,
+		Labels:       e.Labels(),
+		Completions:  e.instance.resources.replicas,
+		BackoffLimit: 0,
+		PodConfig:    e.preparePodConfig(),
+	}
+}
+
+// preparePodConfig prepares the pod config for the instance and its sidecars
+func (e *execution) preparePodConfig() k8s.PodConfig {
 	containerConfig := k8s.ContainerConfig{
 		Name:            e.instance.name,
 		Image:           e.instance.build.imageName,
@@ -375,6 +618,7 @@ func (e *execution) prepareReplicaSetConfig() k8s.ReplicaSetConfig {
 		Command:         e.instance.build.command,
 		Args:            e.instance.build.args,
 		Env:             e.instance.build.env,
+		SecretEnv:       e.instance.build.secretEnvKeys(),
 		Volumes:         e.instance.storage.volumes,
 		MemoryRequest:   e.instance.resources.memoryRequest,
 		MemoryLimit:     e.instance.resources.memoryLimit,
@@ -383,6 +627,7 @@ func (e *execution) prepareReplicaSetConfig() k8s.ReplicaSetConfig {
 		ReadinessProbe:  e.instance.monitoring.readinessProbe,
 		StartupProbe:    e.instance.monitoring.startupProbe,
 		Files:           e.instance.storage.files,
+		SharedVolumes:   e.instance.storage.k8sSharedVolumes(),
 		SecurityContext: e.instance.security.prepareSecurityContext(),
 		TCPPorts:        e.instance.network.portsTCP,
 		UDPPorts:        e.instance.network.portsUDP,
@@ -396,6 +641,7 @@ func (e *execution) prepareReplicaSetConfig() k8s.ReplicaSetConfig {
 			Command:         sidecar.Instance().build.command,
 			Args:            sidecar.Instance().build.args,
 			Env:             sidecar.Instance().build.env,
+			SecretEnv:       sidecar.Instance().build.secretEnvKeys(),
 			Volumes:         sidecar.Instance().storage.volumes,
 			MemoryRequest:   sidecar.Instance().resources.memoryRequest,
 			MemoryLimit:     sidecar.Instance().resources.memoryLimit,
@@ -404,31 +650,121 @@ func (e *execution) prepareReplicaSetConfig() k8s.ReplicaSetConfig {
 			ReadinessProbe:  sidecar.Instance().monitoring.readinessProbe,
 			StartupProbe:    sidecar.Instance().monitoring.startupProbe,
 			Files:           sidecar.Instance().storage.files,
+			SharedVolumes:   sidecar.Instance().storage.k8sSharedVolumes(),
 			SecurityContext: sidecar.Instance().security.prepareSecurityContext(),
 			TCPPorts:        sidecar.Instance().network.portsTCP,
 			UDPPorts:        sidecar.Instance().network.portsUDP,
 		})
 	}
 
-	podConfig := k8s.PodConfig{
+	return k8s.PodConfig{
 		Namespace:          e.instance.K8sClient.Namespace(),
 		Name:               e.instance.name,
 		Labels:             e.Labels(),
 		ServiceAccountName: e.instance.name,
 		ContainerConfig:    containerConfig,
 		SidecarConfigs:     sidecarConfigs,
-		NodeSelector:       e.instance.build.nodeSelector,
+		NodeSelector:       e.instance.build.podNodeSelector(),
+		ImagePullSecrets:   e.imagePullSecrets(),
 	}
+}
 
-	return k8s.ReplicaSetConfig{
-		Namespace: e.instance.K8sClient.Namespace(),
-		Name:      e.instance.name,
-		Labels:    e.Labels(),
-		Replicas:  1,
-		PodConfig: podConfig,
+// executeCommandInPod executes the given command in the container of the instance in the given pod
+func (e *execution) executeCommandInPod(ctx context.Context, podName string, command ...string) (string, error) {
+	var eErr *Error
+	if e.instance.sidecars.isSidecar {
+		eErr = ErrExecutingCommandInSidecar.WithParams(command, e.instance.name, e.instance.parentInstance.name)
+	} else {
+		eErr = ErrExecutingCommandInInstance.WithParams(command, e.instance.name)
 	}
+
+	commandWithShell := []string{"/bin/sh", "-c", strings.Join(command, " ")}
+	output, err := e.instance.K8sClient.RunCommandInPod(ctx, podName, e.instance.name, commandWithShell)
+	if err != nil {
+		return "", eErr.Wrap(err)
+	}
+	return output, nil
+}
+
+// workloadPods returns the pods of the workload the instance runs in
+func (e *execution) workloadPods(ctx context.Context) ([]v1.Pod, error) {
+	switch e.WorkloadType() {
+	case StatefulSetWorkload:
+		pods, err := e.instance.K8sClient.GetPodsFromStatefulSet(ctx, e.workloadName())
+		if err != nil {
+			return nil, ErrGettingPodsFromStatefulSet.WithParams(e.workloadName()).Wrap(err)
+		}
+		return pods, nil
+	case JobWorkload:
+		pods, err := e.instance.K8sClient.GetPodsFromJob(ctx, e.workloadName())
+		if err != nil {
+			return nil, ErrGettingPodsFromJob.WithParams(e.workloadName()).Wrap(err)
+		}
+		return pods, nil
+	default:
+		pods, err := e.instance.K8sClient.GetPodsFromReplicaSet(ctx, e.workloadName())
+		if err != nil {
+			return nil, ErrGettingPodsFromReplicaSet.WithParams(e.instance.name).Wrap(err)
+		}
+		return pods, nil
+	}
+}
+
+// firstPod returns the first pod of the workload the instance runs in
+func (e *execution) firstPod(ctx context.Context) (*v1.Pod, error) {
+	if e.WorkloadType() == ReplicaSetWorkload {
+		pod, err := e.instance.K8sClient.GetFirstPodFromReplicaSet(ctx, e.workloadName())
+		if err != nil {
+			return nil, ErrGettingPodFromReplicaSet.WithParams(e.workloadName()).Wrap(err)
+		}
+		return pod, nil
+	}
+
+	pods, err := e.workloadPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return nil, ErrNoPodsForInstance.WithParams(e.instance.name)
+	}
+	return &pods[0], nil
+}
+
+// containerTerminatedState returns the terminated state of the given container in the pod
+// If the container has not terminated, nil is returned
+func containerTerminatedState(pod *v1.Pod, containerName string) *v1.ContainerStateTerminated {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName {
+			return status.State.Terminated
+		}
+	}
+	return nil
+}
+
+// replicaPod returns the pod of the replica with the given index
+func (e *execution) replicaPod(ctx context.Context, replica int) (*v1.Pod, error) {
+	pods, err := e.Pods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if replica < 0 || replica >= len(pods) {
+		return nil, ErrReplicaIndexOutOfRange.WithParams(replica, e.instance.name, len(pods))
+	}
+	return &pods[replica], nil
+}
+
+// workloadName returns the name of the kubernetes workload the instance runs in
+// A sidecar runs in the workload of its parent instance
+func (e *execution) workloadName() string {
+	if e.instance.sidecars.isSidecar {
+		return e.instance.parentInstance.name
+	}
+	return e.instance.name
 }
 
 func (e *execution) clone() *execution {
-	return &execution{instance: nil}
+	return &execution{
+		instance:     nil,
+		workloadType: e.workloadType,
+	}
 }
@@ -0,0 +1,266 @@
+package instance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultGroupConcurrency           = 10
+	defaultDependencyReadinessTimeout = 10 * time.Minute
+)
+
+// ReadinessCondition reports whether a dependency is ready for its dependents to be started
+// It is called repeatedly until it returns true, an error or the readiness timeout of the group is reached
+type ReadinessCondition func(ctx context.Context, dependency *Instance) (bool, error)
+
+// ReadyWhenRunning is the default readiness condition, the dependency is ready once all its pods are running
+func ReadyWhenRunning(ctx context.Context, dependency *Instance) (bool, error) {
+	return dependency.execution.IsRunning(ctx)
+}
+
+// ReadyWhenCommandSucceeds returns a readiness condition that executes the given command in the dependency
+// and considers it ready once the command exits successfully
+func ReadyWhenCommandSucceeds(command ...string) ReadinessCondition {
+	return func(ctx context.Context, dependency *Instance) (bool, error) {
+		running, err := dependency.execution.IsRunning(ctx)
+		if err != nil || !running {
+			return false, err
+		}
+		_, err = dependency.execution.ExecuteCommand(ctx, command...)
+		return err == nil, nil
+	}
+}
+
+// Group starts a set of instances respecting the dependencies between them
+// Instances that do not depend on each other are started concurrently
+type Group struct {
+	members          []*GroupMember
+	concurrency      int
+	readinessTimeout time.Duration
+	pollInterval     time.Duration
+}
+
+// GroupMember is an instance of a group together with the instances it depends on
+type GroupMember struct {
+	group        *Group
+	instance     *Instance
+	dependencies []dependency
+}
+
+type dependency struct {
+	member    *GroupMember
+	condition ReadinessCondition
+}
+
+// NewGroup creates a group with the given instances
+func NewGroup(instances ...*Instance) *Group {
+	g := &Group{
+		concurrency:      defaultGroupConcurrency,
+		readinessTimeout: defaultDependencyReadinessTimeout,
+		pollInterval:     waitForInstanceRetry,
+	}
+	for _, i := range instances {
+		g.Add(i)
+	}
+	return g
+}
+
+// Add adds the instance to the group and returns its member, which is used to declare its dependencies
+// Adding an instance that is already part of the group returns the existing member
+func (g *Group) Add(i *Instance) *GroupMember {
+	for _, m := range g.members {
+		if m.instance == i {
+			return m
+		}
+	}
+	m := &GroupMember{group: g, instance: i}
+	g.members = append(g.members, m)
+	return m
+}
+
+// SetConcurrency sets the maximum number of instances that are started at the same time
+func (g *Group) SetConcurrency(concurrency int) error {
+	if concurrency < 1 {
+		return ErrInvalidGroupConcurrency.WithParams(concurrency)
+	}
+	g.concurrency = concurrency
+	return nil
+}
+
+// SetReadinessTimeout sets how long a dependency may take to become ready before the start of the group fails
+func (g *Group) SetReadinessTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return ErrInvalidReadinessTimeout.WithParams(timeout.String())
+	}
+	g.readinessTimeout = timeout
+	return nil
+}
+
+// Instances returns the instances of the group in the order they were added
+func (g *Group) Instances() []*Instance {
+	instances := make([]*Instance, len(g.members))
+	for idx, m := range g.members {
+		instances[idx] = m.instance
+	}
+	return instances
+}
+
+// DependsOn declares that the member must only be started once the other instance is ready
+// The other instance is added to the group if it is not part of it yet
+// If the condition is nil, ReadyWhenRunning is used
+func (m *GroupMember) DependsOn(other *Instance, condition ReadinessCondition) *GroupMember {
+	if condition == nil {
+		condition = ReadyWhenRunning
+	}
+	m.dependencies = append(m.dependencies, dependency{
+		member:    m.group.Add(other),
+		condition: condition,
+	})
+	return m
+}
+
+// Instance returns the instance of the member
+func (m *GroupMember) Instance() *Instance {
+	return m.instance
+}
+
+// Start starts all the instances of the group
+// An instance is started as soon as all its dependencies are ready, at most concurrency instances are started at once
+// If an instance fails to start or a dependency does not become ready in time, the remaining instances are not started
+// and the first error is returned
+// The instances without dependents are not waited for, use Execution().WaitInstanceIsRunning() if needed
+func (g *Group) Start(ctx context.Context) error {
+	for _, m := range g.members {
+		if m.instance == nil {
+			return ErrGroupInstanceIsNil
+		}
+	}
+	if err := g.checkCycles(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		sem      = make(chan struct{}, g.concurrency)
+		started  = make(map[*GroupMember]chan struct{}, len(g.members))
+	)
+	for _, m := range g.members {
+		started[m] = make(chan struct{})
+	}
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for _, m := range g.members {
+		wg.Add(1)
+		go func(m *GroupMember) {
+			defer wg.Done()
+
+			for _, dep := range m.dependencies {
+				select {
+				case <-started[dep.member]:
+				case <-ctx.Done():
+					return
+				}
+				if err := g.waitForDependency(ctx, m, dep); err != nil {
+					fail(err)
+					return
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			err := m.instance.execution.StartAsync(ctx)
+			<-sem
+			if err != nil {
+				fail(ErrStartingGroupMember.WithParams(m.instance.name).Wrap(err))
+				return
+			}
+			close(started[m])
+
+			m.instance.Logger.WithFields(logrus.Fields{
+				"instance":     m.instance.name,
+				"dependencies": len(m.dependencies),
+			}).Debug("started instance of group")
+		}(m)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// waitForDependency polls the readiness condition of the dependency until it is met
+func (g *Group) waitForDependency(ctx context.Context, m *GroupMember, dep dependency) error {
+	ctx, cancel := context.WithTimeout(ctx, g.readinessTimeout)
+	defer cancel()
+
+	for {
+		ready, err := dep.condition(ctx, dep.member.instance)
+		if err != nil {
+			return ErrCheckingDependencyReadiness.WithParams(dep.member.instance.name, m.instance.name).Wrap(err)
+		}
+		if ready {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ErrDependencyNotReady.
+				WithParams(dep.member.instance.name, m.instance.name, g.readinessTimeout.String()).Wrap(ctx.Err())
+		case <-time.After(g.pollInterval):
+		}
+	}
+}
+
+// checkCycles returns an error if the dependencies of the group contain a cycle
+func (g *Group) checkCycles() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[*GroupMember]int, len(g.members))
+
+	var visit func(m *GroupMember) error
+	visit = func(m *GroupMember) error {
+		switch marks[m] {
+		case visiting:
+			return ErrGroupDependencyCycle.WithParams(m.instance.name)
+		case visited:
+			return nil
+		}
+		marks[m] = visiting
+		for _, dep := range m.dependencies {
+			if err := visit(dep.member); err != nil {
+				return err
+			}
+		}
+		marks[m] = visited
+		return nil
+	}
+
+	for _, m := range g.members {
+		if err := visit(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
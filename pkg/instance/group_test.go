@@ -0,0 +1,114 @@
+package instance
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	discfake "k8s.io/client-go/discovery/fake"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+func TestGroupStart(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+
+	validator := newCommittedTestInstance(t, sysDeps, "validator")
+	bridge := newCommittedTestInstance(t, sysDeps, "bridge")
+	light := newCommittedTestInstance(t, sysDeps, "light")
+
+	var (
+		mu      sync.Mutex
+		checked []string
+	)
+	readyWhenStarted := func(ctx context.Context, dependency *Instance) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		checked = append(checked, dependency.Name())
+		return dependency.IsInState(StateStarted), nil
+	}
+
+	g := NewGroup()
+	g.Add(light).DependsOn(bridge, readyWhenStarted)
+	g.Add(bridge).DependsOn(validator, readyWhenStarted)
+	require.NoError(t, g.SetConcurrency(1))
+
+	require.NoError(t, g.Start(ctx))
+	for _, i := range []*Instance{validator, bridge, light} {
+		assert.Equal(t, StateStarted, i.State())
+	}
+	assert.Equal(t, []string{"validator", "bridge"}, checked)
+	assert.ElementsMatch(t, []*Instance{validator, bridge, light}, g.Instances())
+}
+
+func TestGroupStartDependencyNotReady(t *testing.T) {
+	sysDeps := newTestSystemDependencies(t)
+
+	validator := newCommittedTestInstance(t, sysDeps, "validator")
+	bridge := newCommittedTestInstance(t, sysDeps, "bridge")
+
+	g := NewGroup(validator)
+	g.pollInterval = 10 * time.Millisecond
+	require.NoError(t, g.SetReadinessTimeout(50*time.Millisecond))
+	g.Add(bridge).DependsOn(validator, func(ctx context.Context, dependency *Instance) (bool, error) {
+		return false, nil
+	})
+
+	err := g.Start(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrDependencyNotReady)
+	assert.Equal(t, StateStarted, validator.State())
+	assert.Equal(t, StateCommitted, bridge.State())
+}
+
+func TestGroupStartCycle(t *testing.T) {
+	sysDeps := newTestSystemDependencies(t)
+
+	a := newCommittedTestInstance(t, sysDeps, "a")
+	b := newCommittedTestInstance(t, sysDeps, "b")
+
+	g := NewGroup()
+	g.Add(a).DependsOn(b, nil)
+	g.Add(b).DependsOn(a, nil)
+
+	err := g.Start(context.Background())
+	assert.ErrorIs(t, err, ErrGroupDependencyCycle)
+	assert.Equal(t, StateCommitted, a.State())
+	assert.Equal(t, StateCommitted, b.State())
+}
+
+func TestGroupSetConcurrency(t *testing.T) {
+	g := NewGroup()
+	assert.ErrorIs(t, g.SetConcurrency(0), ErrInvalidGroupConcurrency)
+	assert.NoError(t, g.SetConcurrency(5))
+	assert.Equal(t, 5, g.concurrency)
+}
+
+func newTestSystemDependencies(t *testing.T) *system.SystemDependencies {
+	k8sClient, err := k8s.NewClientCustom(context.Background(), fake.NewSimpleClientset(), &discfake.FakeDiscovery{Fake: &k8stesting.Fake{}}, dynfake.NewSimpleDynamicClient(runtime.NewScheme()), "test", logrus.New())
+	require.NoError(t, err)
+	return &system.SystemDependencies{
+		K8sClient: k8sClient,
+		Logger:    logrus.New(),
+		Scope:     "test",
+		StartTime: time.Now().UTC().Format("20060102T150405Z"),
+	}
+}
+
+func newCommittedTestInstance(t *testing.T, sysDeps *system.SystemDependencies, name string) *Instance {
+	i, err := New(name, sysDeps)
+	require.NoError(t, err)
+	require.NoError(t, i.Build().SetImage(context.Background(), "alpine:latest"))
+	require.NoError(t, i.Build().Commit(context.Background()))
+	return i
+}
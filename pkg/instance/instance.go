@@ -5,6 +5,7 @@ import (
 	"time"
 
 	appv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -19,6 +20,7 @@ const (
 	retryInterval        = 5 * time.Second
 	waitForInstanceRetry = 1 * time.Second
 	labelType            = "knuu.sh/type"
+	defaultReplicas      = 1
 )
 
 // Instance represents a instance
@@ -38,7 +40,9 @@ type Instance struct {
 	state        InstanceState
 	instanceType InstanceType
 
-	kubernetesReplicaSet *appv1.ReplicaSet
+	kubernetesReplicaSet  *appv1.ReplicaSet
+	kubernetesStatefulSet *appv1.StatefulSet
+	kubernetesJob         *batchv1.Job
 
 	parentInstance *Instance
 }
@@ -69,6 +73,7 @@ func New(name string, sysDeps *system.SystemDependencies) (*Instance, error) {
 		memoryRequest: resource.Quantity{},
 		memoryLimit:   resource.Quantity{},
 		cpuRequest:    resource.Quantity{},
+		replicas:      defaultReplicas,
 	}
 	i.network = &network{
 		instance: i,
@@ -20,10 +20,25 @@ func (i *Instance) Monitoring() *monitoring {
 }
 
 func (m *monitoring) Logs(ctx context.Context) (io.ReadCloser, error) {
-	if m.instance.sidecars.IsSidecar() {
-		return m.instance.K8sClient.GetLogStream(ctx, m.instance.parentInstance.Name(), m.instance.Name())
+	pod, err := m.instance.execution.firstPod(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return m.instance.K8sClient.GetLogStream(ctx, m.instance.Name(), m.instance.Name())
+	return m.instance.K8sClient.GetPodLogStream(ctx, pod.Name, m.instance.Name())
+}
+
+// ReplicaLogs returns the logs of the replica with the given index
+// This function can only be called in the state 'Started'
+func (m *monitoring) ReplicaLogs(ctx context.Context, replica int) (io.ReadCloser, error) {
+	if !m.instance.IsInState(StateStarted) {
+		return nil, ErrGettingLogsNotAllowed.WithParams(m.instance.state.String())
+	}
+
+	pod, err := m.instance.execution.replicaPod(ctx, replica)
+	if err != nil {
+		return nil, err
+	}
+	return m.instance.K8sClient.GetPodLogStream(ctx, pod.Name, m.instance.Name())
 }
 
 // SetLivenessProbe sets the liveness probe of the instance
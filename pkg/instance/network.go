@@ -11,11 +11,17 @@ import (
 	"github.com/celestiaorg/knuu/pkg/k8s"
 )
 
+// headlessServiceSuffix is appended to the name of the instance to name the governing service of its StatefulSet
+const headlessServiceSuffix = "-headless"
+
 type network struct {
 	instance          *Instance
 	portsTCP          []int
 	portsUDP          []int
 	kubernetesService *v1.Service
+	// partitionedFrom counts the active partitions that separate the instance from other instances, by name
+	partitionedFrom map[string]int
+	policy          networkPolicy
 }
 
 func (i *Instance) Network() *network {
@@ -64,9 +70,9 @@ func (n *network) PortForwardTCP(ctx context.Context, port int) (int, error) {
 	}
 
 	// Forward the port
-	pod, err := n.instance.K8sClient.GetFirstPodFromReplicaSet(ctx, n.instance.name)
+	pod, err := n.instance.execution.firstPod(ctx)
 	if err != nil {
-		return -1, ErrGettingPodFromReplicaSet.WithParams(n.instance.name).Wrap(err)
+		return -1, err
 	}
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
@@ -129,9 +135,28 @@ func (n *network) GetEphemeralIP(ctx context.Context) (string, error) {
 		return "", ErrGettingIPNotAllowed.WithParams(n.instance.state.String())
 	}
 
-	pod, err := n.instance.K8sClient.GetFirstPodFromReplicaSet(ctx, n.instance.name)
+	pod, err := n.instance.execution.firstPod(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if pod.Status.PodIP == "" {
+		return "", ErrPodIPNotReady.WithParams(pod.Name)
+	}
+
+	return pod.Status.PodIP, nil
+}
+
+// GetReplicaEphemeralIP returns the ephemeral IP of the replica with the given index
+// This function can only be called in the states 'Started'
+func (n *network) GetReplicaEphemeralIP(ctx context.Context, replica int) (string, error) {
+	if !n.instance.IsInState(StateStarted) {
+		return "", ErrGettingIPNotAllowed.WithParams(n.instance.state.String())
+	}
+
+	pod, err := n.instance.execution.replicaPod(ctx, replica)
 	if err != nil {
-		return "", ErrGettingPodFromReplicaSet.WithParams(n.instance.name).Wrap(err)
+		return "", err
 	}
 
 	if pod.Status.PodIP == "" {
@@ -208,6 +233,43 @@ func (n *network) destroyService(ctx context.Context) error {
 	return n.instance.K8sClient.DeleteService(ctx, n.instance.name)
 }
 
+// headlessServiceName returns the name of the headless service governing the StatefulSet of the instance
+func (n *network) headlessServiceName() string {
+	return n.instance.name + headlessServiceSuffix
+}
+
+// deployHeadlessService deploys the headless service governing the StatefulSet of the instance,
+// which gives every replica a stable DNS name '<instance>-<replica>.<instance>-headless'
+// It exists even if the instance has no ports, unlike the regular service of the instance
+func (n *network) deployHeadlessService(ctx context.Context, portsTCP, portsUDP []int) error {
+	serviceName := n.headlessServiceName()
+	if svc, err := n.instance.K8sClient.GetService(ctx, serviceName); err == nil && svc != nil {
+		return nil
+	}
+
+	labels := n.instance.execution.Labels()
+	_, err := n.instance.K8sClient.CreateService(ctx, serviceName, k8s.ServiceOptions{
+		Labels:        labels,
+		SelectorMap:   labels,
+		TCPPorts:      portsTCP,
+		UDPPorts:      portsUDP,
+		PortsOptional: true,
+	})
+	if err != nil {
+		return ErrDeployingHeadlessService.WithParams(n.instance.name).Wrap(err)
+	}
+	n.instance.Logger.WithFields(logrus.Fields{
+		"instance": n.instance.name,
+		"service":  serviceName,
+	}).Debug("started headless service")
+	return nil
+}
+
+// destroyHeadlessService destroys the headless service governing the StatefulSet of the instance
+func (n *network) destroyHeadlessService(ctx context.Context) error {
+	return n.instance.K8sClient.DeleteService(ctx, n.headlessServiceName())
+}
+
 // isTCPPortRegistered returns true if the given port is registered
 // with the instance, and false otherwise
 func (n *network) isTCPPortRegistered(port int) bool {
@@ -331,6 +393,7 @@ func (n *network) clone() *network {
 		portsTCP:          portsTCPCopy,
 		portsUDP:          portsUDPCopy,
 		kubernetesService: nil, //TODO: discuss the implementation of a clone for the service
+		policy:            n.policy.clone(),
 	}
 }
 
@@ -0,0 +1,272 @@
+package instance
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+const (
+	networkPolicySuffix = "-policy"
+	dnsPort             = 53
+	kubeSystemNamespace = "kube-system"
+)
+
+// networkPolicy holds the traffic rules of an instance
+type networkPolicy struct {
+	restrictIngress bool
+	ingress         []k8s.NetworkPolicyRule
+	restrictEgress  bool
+	egress          []k8s.NetworkPolicyRule
+	deployed        bool
+}
+
+// PortTCP returns a network policy port for the given TCP port
+func PortTCP(port int) k8s.NetworkPolicyPort {
+	return k8s.NetworkPolicyPort{Protocol: v1.ProtocolTCP, Port: port}
+}
+
+// PortUDP returns a network policy port for the given UDP port
+func PortUDP(port int) k8s.NetworkPolicyPort {
+	return k8s.NetworkPolicyPort{Protocol: v1.ProtocolUDP, Port: port}
+}
+
+// PeerInstances returns one network policy peer per given instance
+func PeerInstances(instances ...*Instance) []k8s.NetworkPolicyPeer {
+	peers := make([]k8s.NetworkPolicyPeer, 0, len(instances))
+	for _, i := range instances {
+		peers = append(peers, k8s.NetworkPolicyPeer{
+			PodSelector: map[string]string{LabelNameKey: i.name},
+		})
+	}
+	return peers
+}
+
+// PeerLabels returns a network policy peer selecting the pods of the namespace with the given labels
+func PeerLabels(labels map[string]string) k8s.NetworkPolicyPeer {
+	return k8s.NetworkPolicyPeer{PodSelector: labels}
+}
+
+// PeerNamespace returns a network policy peer selecting all the pods of the given namespace
+func PeerNamespace(namespace string) k8s.NetworkPolicyPeer {
+	return k8s.NetworkPolicyPeer{NamespaceSelector: map[string]string{k8s.NamespaceNameLabel: namespace}}
+}
+
+// PeerCIDR returns a network policy peer selecting an IP block, without the except CIDRs
+func PeerCIDR(cidr string, except ...string) k8s.NetworkPolicyPeer {
+	return k8s.NetworkPolicyPeer{CIDR: cidr, Except: except}
+}
+
+// AllowIngress allows the incoming traffic on the given ports from the given peers
+// Empty ports means all ports and no peers means all peers
+// Once an ingress rule is added, all the incoming traffic that does not match an ingress rule is denied
+// On a started instance, the rules are applied by calling ApplyNetworkPolicy
+// This function can only be called in the states 'Preparing', 'Committed', 'Started' and 'Stopped'
+func (n *network) AllowIngress(ports []k8s.NetworkPolicyPort, from ...k8s.NetworkPolicyPeer) error {
+	if !n.instance.IsInState(StatePreparing, StateCommitted, StateStarted, StateStopped) {
+		return ErrSettingNetworkPolicyNotAllowed.WithParams(n.instance.state.String())
+	}
+	if n.instance.sidecars.IsSidecar() {
+		return ErrSettingNetworkPolicyNotAllowedForSidecar.WithParams(n.instance.name)
+	}
+
+	n.policy.ingress = append(n.policy.ingress, k8s.NetworkPolicyRule{Ports: ports, Peers: from})
+	n.instance.Logger.WithFields(logrus.Fields{
+		"instance": n.instance.name,
+		"ports":    ports,
+		"peers":    from,
+	}).Debug("allowed ingress traffic for instance")
+	return nil
+}
+
+// AllowEgress allows the outgoing traffic on the given ports to the given peers
+// Empty ports means all ports and no peers means all peers
+// Once an egress rule is added, all the outgoing traffic that does not match an egress rule is denied
+// On a started instance, the rules are applied by calling ApplyNetworkPolicy
+// This function can only be called in the states 'Preparing', 'Committed', 'Started' and 'Stopped'
+func (n *network) AllowEgress(ports []k8s.NetworkPolicyPort, to ...k8s.NetworkPolicyPeer) error {
+	if !n.instance.IsInState(StatePreparing, StateCommitted, StateStarted, StateStopped) {
+		return ErrSettingNetworkPolicyNotAllowed.WithParams(n.instance.state.String())
+	}
+	if n.instance.sidecars.IsSidecar() {
+		return ErrSettingNetworkPolicyNotAllowedForSidecar.WithParams(n.instance.name)
+	}
+
+	n.policy.egress = append(n.policy.egress, k8s.NetworkPolicyRule{Ports: ports, Peers: to})
+	n.instance.Logger.WithFields(logrus.Fields{
+		"instance": n.instance.name,
+		"ports":    ports,
+		"peers":    to,
+	}).Debug("allowed egress traffic for instance")
+	return nil
+}
+
+// AllowDNSEgress allows the DNS queries to the pods of the kube-system namespace
+// It is usually combined with DenyAllEgress, so the instance can still resolve names
+// This function can only be called in the states 'Preparing', 'Committed', 'Started' and 'Stopped'
+func (n *network) AllowDNSEgress() error {
+	return n.AllowEgress(
+		[]k8s.NetworkPolicyPort{PortUDP(dnsPort), PortTCP(dnsPort)},
+		PeerNamespace(kubeSystemNamespace),
+	)
+}
+
+// DenyAllIngress denies all the incoming traffic, except the traffic allowed by AllowIngress
+// This function can only be called in the states 'Preparing', 'Committed', 'Started' and 'Stopped'
+func (n *network) DenyAllIngress() error {
+	if !n.instance.IsInState(StatePreparing, StateCommitted, StateStarted, StateStopped) {
+		return ErrSettingNetworkPolicyNotAllowed.WithParams(n.instance.state.String())
+	}
+	if n.instance.sidecars.IsSidecar() {
+		return ErrSettingNetworkPolicyNotAllowedForSidecar.WithParams(n.instance.name)
+	}
+
+	n.policy.restrictIngress = true
+	n.instance.Logger.WithField("instance", n.instance.name).Debug("denied all ingress traffic for instance")
+	return nil
+}
+
+// DenyAllEgress denies all the outgoing traffic, except the traffic allowed by AllowEgress
+// This function can only be called in the states 'Preparing', 'Committed', 'Started' and 'Stopped'
+func (n *network) DenyAllEgress() error {
+	if !n.instance.IsInState(StatePreparing, StateCommitted, StateStarted, StateStopped) {
+		return ErrSettingNetworkPolicyNotAllowed.WithParams(n.instance.state.String())
+	}
+	if n.instance.sidecars.IsSidecar() {
+		return ErrSettingNetworkPolicyNotAllowedForSidecar.WithParams(n.instance.name)
+	}
+
+	n.policy.restrictEgress = true
+	n.instance.Logger.WithField("instance", n.instance.name).Debug("denied all egress traffic for instance")
+	return nil
+}
+
+// ResetNetworkPolicy removes all the traffic rules of the instance
+// On a started instance, the policy is removed by calling ApplyNetworkPolicy
+// This function can only be called in the states 'Preparing', 'Committed', 'Started' and 'Stopped'
+func (n *network) ResetNetworkPolicy() error {
+	if !n.instance.IsInState(StatePreparing, StateCommitted, StateStarted, StateStopped) {
+		return ErrSettingNetworkPolicyNotAllowed.WithParams(n.instance.state.String())
+	}
+
+	n.policy = n.policy.reset()
+	n.instance.Logger.WithField("instance", n.instance.name).Debug("reset network policy for instance")
+	return nil
+}
+
+// ApplyNetworkPolicy creates, updates or deletes the NetworkPolicy of a started instance according to its rules
+// The rules of an instance are applied automatically when it is started
+// Note: NetworkPolicies are additive, the traffic allowed by this policy is allowed even if the network is disabled
+// This function can only be called in the state 'Started'
+func (n *network) ApplyNetworkPolicy(ctx context.Context) error {
+	if !n.instance.IsInState(StateStarted) {
+		return ErrApplyingNetworkPolicyNotAllowed.WithParams(n.instance.state.String())
+	}
+	return n.applyNetworkPolicy(ctx)
+}
+
+// NetworkPolicyOptions returns the options of the NetworkPolicy built from the rules of the instance
+func (n *network) NetworkPolicyOptions() k8s.NetworkPolicyOptions {
+	return k8s.NetworkPolicyOptions{
+		Labels:          n.instance.execution.Labels(),
+		SelectorMap:     n.instance.execution.Labels(),
+		RestrictIngress: n.policy.restrictIngress,
+		Ingress:         n.policy.ingress,
+		RestrictEgress:  n.policy.restrictEgress,
+		Egress:          n.policy.egress,
+	}
+}
+
+func (n *network) applyNetworkPolicy(ctx context.Context) error {
+	if n.policy.isEmpty() {
+		return n.destroyNetworkPolicy(ctx)
+	}
+
+	policyName := n.instance.name + networkPolicySuffix
+	if _, err := n.instance.K8sClient.CreateOrUpdateNetworkPolicy(ctx, policyName, n.NetworkPolicyOptions()); err != nil {
+		return ErrApplyingNetworkPolicy.WithParams(n.instance.name).Wrap(err)
+	}
+	n.policy.deployed = true
+
+	n.instance.Logger.WithFields(logrus.Fields{
+		"instance": n.instance.name,
+		"policy":   policyName,
+	}).Debug("applied network policy for instance")
+	return nil
+}
+
+// destroyNetworkPolicy deletes the NetworkPolicy of the instance if it was deployed
+func (n *network) destroyNetworkPolicy(ctx context.Context) error {
+	if !n.policy.deployed {
+		return nil
+	}
+
+	policyName := n.instance.name + networkPolicySuffix
+	if err := n.instance.K8sClient.DeleteNetworkPolicy(ctx, policyName); err != nil {
+		return ErrDeletingNetworkPolicy.WithParams(n.instance.name).Wrap(err)
+	}
+	n.policy.deployed = false
+	return nil
+}
+
+// attachNetworkPolicy restores the rules of the NetworkPolicy deployed for an attached instance,
+// so that it is applied again on restart and deleted with the instance
+func (n *network) attachNetworkPolicy(ctx context.Context) error {
+	policyName := n.instance.name + networkPolicySuffix
+	if !n.instance.K8sClient.NetworkPolicyExists(ctx, policyName) {
+		return nil
+	}
+	np, err := n.instance.K8sClient.GetNetworkPolicy(ctx, policyName)
+	if err != nil {
+		return ErrGettingNetworkPolicy.WithParams(n.instance.name).Wrap(err)
+	}
+
+	opts := k8s.NetworkPolicyOptionsFromSpec(np)
+	n.policy = networkPolicy{
+		restrictIngress: opts.RestrictIngress,
+		ingress:         opts.Ingress,
+		restrictEgress:  opts.RestrictEgress,
+		egress:          opts.Egress,
+		deployed:        true,
+	}
+	n.instance.Logger.WithFields(logrus.Fields{
+		"instance": n.instance.name,
+		"policy":   policyName,
+	}).Debug("attached network policy of instance")
+	return nil
+}
+
+func (p networkPolicy) isEmpty() bool {
+	return !p.restrictIngress && !p.restrictEgress && len(p.ingress) == 0 && len(p.egress) == 0
+}
+
+// reset removes the rules and keeps track of the deployed policy, so it can be deleted
+func (p networkPolicy) reset() networkPolicy {
+	return networkPolicy{deployed: p.deployed}
+}
+
+func (p networkPolicy) clone() networkPolicy {
+	return networkPolicy{
+		restrictIngress: p.restrictIngress,
+		ingress:         cloneNetworkPolicyRules(p.ingress),
+		restrictEgress:  p.restrictEgress,
+		egress:          cloneNetworkPolicyRules(p.egress),
+	}
+}
+
+func cloneNetworkPolicyRules(rules []k8s.NetworkPolicyRule) []k8s.NetworkPolicyRule {
+	if rules == nil {
+		return nil
+	}
+	rulesCopy := make([]k8s.NetworkPolicyRule, len(rules))
+	for idx, rule := range rules {
+		rulesCopy[idx] = k8s.NetworkPolicyRule{
+			Ports: append([]k8s.NetworkPolicyPort(nil), rule.Ports...),
+			Peers: append([]k8s.NetworkPolicyPeer(nil), rule.Peers...),
+		}
+	}
+	return rulesCopy
+}
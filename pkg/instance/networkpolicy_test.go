@@ -0,0 +1,44 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+func TestNetworkPolicy(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+
+	peer := newStartedTestInstance(t, sysDeps, "peer")
+	validator := newCommittedTestInstance(t, sysDeps, "validator")
+
+	require.NoError(t, validator.Network().AllowIngress([]k8s.NetworkPolicyPort{PortTCP(26656)}, PeerInstances(peer)...))
+	require.NoError(t, validator.Network().DenyAllEgress())
+	require.NoError(t, validator.Network().AllowDNSEgress())
+
+	clone, err := validator.CloneWithName("validator-clone")
+	require.NoError(t, err)
+	assert.Equal(t, validator.network.policy.ingress, clone.network.policy.ingress)
+
+	require.NoError(t, validator.Execution().StartAsync(ctx))
+	np, err := sysDeps.K8sClient.GetNetworkPolicy(ctx, "validator"+networkPolicySuffix)
+	require.NoError(t, err)
+	assert.Equal(t, []netv1.PolicyType{netv1.PolicyTypeIngress, netv1.PolicyTypeEgress}, np.Spec.PolicyTypes)
+	assert.Equal(t, map[string]string{LabelNameKey: "peer"}, np.Spec.Ingress[0].From[0].PodSelector.MatchLabels)
+	assert.Equal(t, int32(dnsPort), np.Spec.Egress[0].Ports[0].Port.IntVal)
+
+	require.NoError(t, validator.Network().ResetNetworkPolicy())
+	require.NoError(t, validator.Network().ApplyNetworkPolicy(ctx))
+	assert.False(t, sysDeps.K8sClient.NetworkPolicyExists(ctx, "validator"+networkPolicySuffix))
+
+	err = peer.Network().ApplyNetworkPolicy(ctx)
+	assert.NoError(t, err)
+	err = clone.Network().ApplyNetworkPolicy(ctx)
+	assert.ErrorIs(t, err, ErrApplyingNetworkPolicyNotAllowed)
+}
@@ -0,0 +1,163 @@
+package instance
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const partitionPolicySuffix = "-partition"
+
+// partitionMu guards the partition state of all the instances,
+// as partitions may span instances that are used from different goroutines
+var partitionMu sync.Mutex
+
+// NetworkPartition is a network partition between two groups of instances
+type NetworkPartition struct {
+	groupA []*Instance
+	groupB []*Instance
+	healed bool
+}
+
+// Partition prevents the instances of groupA from exchanging traffic with the instances of groupB,
+// while both groups can still reach every other instance, other namespaces and the outside of the cluster
+// It creates one NetworkPolicy per instance, which is updated when partitions overlap
+// Note: NetworkPolicies are additive, while an instance is partitioned its Network().Disable() does not isolate it anymore
+// This function can only be called when all the instances are in the state 'Started'
+func Partition(ctx context.Context, groupA, groupB []*Instance) (*NetworkPartition, error) {
+	if len(groupA) == 0 || len(groupB) == 0 {
+		return nil, ErrPartitionGroupEmpty
+	}
+
+	inA := make(map[*Instance]bool, len(groupA))
+	for _, i := range append(append([]*Instance{}, groupA...), groupB...) {
+		if i == nil {
+			return nil, ErrPartitionInstanceIsNil
+		}
+		if !i.IsInState(StateStarted) {
+			return nil, ErrPartitioningNotAllowed.WithParams(i.name, i.state.String())
+		}
+		if i.sidecars.IsSidecar() {
+			return nil, ErrPartitioningSidecarNotAllowed.WithParams(i.name)
+		}
+	}
+	for _, i := range groupA {
+		inA[i] = true
+	}
+	for _, i := range groupB {
+		if inA[i] {
+			return nil, ErrInstanceInBothPartitionGroups.WithParams(i.name)
+		}
+	}
+
+	p := &NetworkPartition{groupA: groupA, groupB: groupB}
+	if err := p.update(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	groupA[0].Logger.WithFields(logrus.Fields{
+		"group_a": instanceNames(groupA),
+		"group_b": instanceNames(groupB),
+	}).Debug("partitioned network")
+	return p, nil
+}
+
+// PartitionGroups is like Partition, with the instances of two groups
+func PartitionGroups(ctx context.Context, groupA, groupB *Group) (*NetworkPartition, error) {
+	return Partition(ctx, groupA.Instances(), groupB.Instances())
+}
+
+// Heal removes the partition, the instances can reach each other again unless another partition separates them
+// Healing a partition that is already healed does nothing
+func (p *NetworkPartition) Heal(ctx context.Context) error {
+	if p.healed {
+		return nil
+	}
+	if err := p.update(ctx, -1); err != nil {
+		return err
+	}
+	p.healed = true
+
+	p.groupA[0].Logger.WithFields(logrus.Fields{
+		"group_a": instanceNames(p.groupA),
+		"group_b": instanceNames(p.groupB),
+	}).Debug("healed network partition")
+	return nil
+}
+
+// update adds (delta 1) or removes (delta -1) the partition from the state of its instances
+// and applies their NetworkPolicies
+func (p *NetworkPartition) update(ctx context.Context, delta int) error {
+	partitionMu.Lock()
+	defer partitionMu.Unlock()
+
+	for _, a := range p.groupA {
+		a.network.updatePartitionedFrom(p.groupB, delta)
+	}
+	for _, b := range p.groupB {
+		b.network.updatePartitionedFrom(p.groupA, delta)
+	}
+
+	for _, i := range append(append([]*Instance{}, p.groupA...), p.groupB...) {
+		if err := i.network.applyPartitionPolicy(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *network) updatePartitionedFrom(peers []*Instance, delta int) {
+	if n.partitionedFrom == nil {
+		n.partitionedFrom = make(map[string]int)
+	}
+	for _, peer := range peers {
+		n.partitionedFrom[peer.name] += delta
+		if n.partitionedFrom[peer.name] <= 0 {
+			delete(n.partitionedFrom, peer.name)
+		}
+	}
+}
+
+// applyPartitionPolicy creates, updates or deletes the partition NetworkPolicy of the instance
+// according to the instances it is currently partitioned from
+func (n *network) applyPartitionPolicy(ctx context.Context) error {
+	policyName := n.instance.name + partitionPolicySuffix
+	if len(n.partitionedFrom) == 0 {
+		return n.destroyPartitionPolicy(ctx)
+	}
+
+	blocked := make([]string, 0, len(n.partitionedFrom))
+	for name := range n.partitionedFrom {
+		blocked = append(blocked, name)
+	}
+	sort.Strings(blocked)
+
+	err := n.instance.K8sClient.CreateOrUpdatePartitionNetworkPolicy(
+		ctx, policyName, n.instance.execution.Labels(), LabelNameKey, blocked)
+	if err != nil {
+		return ErrApplyingPartitionPolicy.WithParams(n.instance.name).Wrap(err)
+	}
+	return nil
+}
+
+// destroyPartitionPolicy deletes the partition NetworkPolicy of the instance if it exists
+func (n *network) destroyPartitionPolicy(ctx context.Context) error {
+	policyName := n.instance.name + partitionPolicySuffix
+	if !n.instance.K8sClient.NetworkPolicyExists(ctx, policyName) {
+		return nil
+	}
+	if err := n.instance.K8sClient.DeleteNetworkPolicy(ctx, policyName); err != nil {
+		return ErrDeletingPartitionPolicy.WithParams(n.instance.name).Wrap(err)
+	}
+	return nil
+}
+
+func instanceNames(instances []*Instance) []string {
+	names := make([]string, len(instances))
+	for idx, i := range instances {
+		names[idx] = i.name
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+func TestPartition(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+
+	a := newStartedTestInstance(t, sysDeps, "a")
+	b := newStartedTestInstance(t, sysDeps, "b")
+	c := newStartedTestInstance(t, sysDeps, "c")
+
+	blockedBy := func(i *Instance) []string {
+		np, err := sysDeps.K8sClient.GetNetworkPolicy(ctx, i.Name()+partitionPolicySuffix)
+		if err != nil {
+			return nil
+		}
+		return np.Spec.Ingress[0].From[0].PodSelector.MatchExpressions[0].Values
+	}
+
+	ab, err := Partition(ctx, []*Instance{a}, []*Instance{b})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, blockedBy(a))
+	assert.Equal(t, []string{"a"}, blockedBy(b))
+	assert.Nil(t, blockedBy(c))
+
+	ac, err := Partition(ctx, []*Instance{a}, []*Instance{c})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, blockedBy(a))
+
+	require.NoError(t, ab.Heal(ctx))
+	assert.Equal(t, []string{"c"}, blockedBy(a))
+	assert.Nil(t, blockedBy(b))
+
+	require.NoError(t, ac.Heal(ctx))
+	require.NoError(t, ac.Heal(ctx))
+	assert.Nil(t, blockedBy(a))
+	assert.Nil(t, blockedBy(c))
+}
+
+func TestPartitionValidation(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+
+	started := newStartedTestInstance(t, sysDeps, "started")
+	committed := newCommittedTestInstance(t, sysDeps, "committed")
+
+	_, err := Partition(ctx, nil, []*Instance{started})
+	assert.ErrorIs(t, err, ErrPartitionGroupEmpty)
+
+	_, err = Partition(ctx, []*Instance{started}, []*Instance{committed})
+	assert.ErrorIs(t, err, ErrPartitioningNotAllowed)
+
+	_, err = Partition(ctx, []*Instance{started}, []*Instance{started})
+	assert.ErrorIs(t, err, ErrInstanceInBothPartitionGroups)
+}
+
+func newStartedTestInstance(t *testing.T, sysDeps *system.SystemDependencies, name string) *Instance {
+	i := newCommittedTestInstance(t, sysDeps, name)
+	require.NoError(t, i.Execution().StartAsync(context.Background()))
+	return i
+}
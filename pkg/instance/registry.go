@@ -0,0 +1,70 @@
+package instance
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+const imagePullSecretSuffix = "-registry-credentials"
+
+// registryCredentials returns the registry credentials of the instance and its sidecars
+func (e *execution) registryCredentials() []*builder.Registry {
+	registries := append([]*builder.Registry(nil), e.instance.build.registryCredentials...)
+	for _, sidecar := range e.instance.sidecars.sidecars {
+		registries = append(registries, sidecar.Instance().build.registryCredentials...)
+	}
+	return registries
+}
+
+// imagePullSecrets returns the names of the secrets used to pull the images of the pod
+func (e *execution) imagePullSecrets() []string {
+	var secrets []string
+	if e.instance.ImagePullSecret != "" {
+		secrets = append(secrets, e.instance.ImagePullSecret)
+	}
+	if len(e.registryCredentials()) != 0 {
+		secrets = append(secrets, e.instance.name+imagePullSecretSuffix)
+	}
+	return secrets
+}
+
+// deployImagePullSecret creates the image pull secret of the instance if it has registry credentials
+func (e *execution) deployImagePullSecret(ctx context.Context) error {
+	registries := e.registryCredentials()
+	if len(registries) == 0 {
+		return nil
+	}
+
+	dockerConfig, err := builder.DockerConfigJSON(registries...)
+	if err != nil {
+		return ErrCreatingImagePullSecret.WithParams(e.instance.name).Wrap(err)
+	}
+
+	secretName := e.instance.name + imagePullSecretSuffix
+	_, err = e.instance.K8sClient.CreateOrUpdateSecret(ctx, secretName, e.Labels(),
+		v1.SecretTypeDockerConfigJson, map[string][]byte{v1.DockerConfigJsonKey: dockerConfig})
+	if err != nil {
+		return ErrCreatingImagePullSecret.WithParams(e.instance.name).Wrap(err)
+	}
+
+	e.instance.Logger.WithFields(logrus.Fields{
+		"instance": e.instance.name,
+		"secret":   secretName,
+	}).Debug("created image pull secret for instance")
+	return nil
+}
+
+// destroyImagePullSecret deletes the image pull secret of the instance if it has registry credentials
+func (e *execution) destroyImagePullSecret(ctx context.Context) error {
+	if len(e.registryCredentials()) == 0 {
+		return nil
+	}
+	if err := e.instance.K8sClient.DeleteSecret(ctx, e.instance.name+imagePullSecretSuffix); err != nil {
+		return ErrDeletingImagePullSecret.WithParams(e.instance.name).Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+)
+
+func TestRegistryCredentials(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	sysDeps.ImagePullSecret = "knuu-registry-credentials"
+
+	i := newCommittedTestInstance(t, sysDeps, "private")
+	assert.ErrorIs(t, i.Build().AddRegistryCredentials(&builder.Registry{Host: "ghcr.io"}), ErrRegistryCredentialsWithoutAuth)
+	require.NoError(t, i.Build().AddRegistryCredentials(&builder.Registry{
+		Host: "ghcr.io",
+		Auth: &builder.RegistryAuth{Username: "user", Password: "token"},
+	}))
+	require.NoError(t, i.Execution().StartAsync(ctx))
+
+	rs, err := sysDeps.K8sClient.Clientset().AppsV1().ReplicaSets(sysDeps.K8sClient.Namespace()).Get(ctx, "private", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []v1.LocalObjectReference{
+		{Name: "knuu-registry-credentials"},
+		{Name: "private" + imagePullSecretSuffix},
+	}, rs.Spec.Template.Spec.ImagePullSecrets)
+
+	secret, err := sysDeps.K8sClient.GetSecret(ctx, "private"+imagePullSecretSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, v1.SecretTypeDockerConfigJson, secret.Type)
+	assert.Contains(t, string(secret.Data[v1.DockerConfigJsonKey]), "ghcr.io")
+
+	assert.ErrorIs(t, i.Build().AddRegistryCredentials(&builder.Registry{Host: "quay.io"}), ErrAddingRegistryCredentialsNotAllowed)
+
+	require.NoError(t, i.Execution().Destroy(ctx))
+	_, err = sysDeps.K8sClient.GetSecret(ctx, "private"+imagePullSecretSuffix)
+	assert.Error(t, err)
+}
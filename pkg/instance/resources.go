@@ -13,6 +13,7 @@ type resources struct {
 	memoryRequest resource.Quantity
 	memoryLimit   resource.Quantity
 	cpuRequest    resource.Quantity
+	replicas      int32
 }
 
 func (i *Instance) Resources() *resources {
@@ -49,6 +50,54 @@ func (r *resources) SetCPU(request resource.Quantity) error {
 	return nil
 }
 
+// SetReplicas sets the number of replicas (pods) the instance is deployed with
+// All the replicas share the same configuration and the same service
+// The replicas of a ReplicaSet cannot share a ReadWriteOnce volume, use the StatefulSet workload or WithAccessMode(ReadWriteMany)
+// To change the number of replicas of a started instance, use Execution().Scale()
+// This function can only be called in the states 'Preparing', 'Committed' and 'Stopped'
+func (r *resources) SetReplicas(replicas int32) error {
+	if !r.instance.IsInState(StatePreparing, StateCommitted, StateStopped) {
+		return ErrSettingReplicasNotAllowed.WithParams(r.instance.state.String())
+	}
+	if r.instance.sidecars.IsSidecar() {
+		return ErrSettingReplicasNotAllowedForSidecar.WithParams(r.instance.name)
+	}
+	if replicas < 1 {
+		return ErrInvalidReplicas.WithParams(replicas)
+	}
+	if err := r.verifyReplicasVolumes(replicas); err != nil {
+		return err
+	}
+	r.replicas = replicas
+	r.instance.Logger.WithFields(logrus.Fields{
+		"instance": r.instance.name,
+		"replicas": replicas,
+	}).Debug("set replicas for instance")
+	return nil
+}
+
+// verifyReplicasVolumes returns an error if several replicas of a ReplicaSet would share the claim of the instance or its sidecars
+// The claims of a StatefulSet are per replica and emptyDirs are per pod, so they are not shared
+func (r *resources) verifyReplicasVolumes(replicas int32) error {
+	if replicas < 2 || r.instance.execution.WorkloadType() != ReplicaSetWorkload {
+		return nil
+	}
+	if r.instance.storage.hasSingleNodeClaim() {
+		return ErrReplicasShareVolume.WithParams(replicas, r.instance.name)
+	}
+	return r.instance.sidecars.applyFunctionToSidecars(func(sc SidecarManager) error {
+		if sc.Instance().storage.hasSingleNodeClaim() {
+			return ErrReplicasShareVolume.WithParams(replicas, sc.Instance().name)
+		}
+		return nil
+	})
+}
+
+// Replicas returns the number of replicas of the instance
+func (r *resources) Replicas() int32 {
+	return r.replicas
+}
+
 // CreateCustomResource creates a custom resource for the instance
 // The names and namespace are set and overridden by knuu
 func (r *resources) CreateCustomResource(ctx context.Context, gvr *schema.GroupVersionResource, obj *map[string]interface{}) error {
@@ -110,6 +159,11 @@ func (r *resources) deployService(ctx context.Context) error {
 			return ErrFailedToDeployOrPatchService.Wrap(err)
 		}
 	}
+	if r.instance.execution.WorkloadType() == StatefulSetWorkload {
+		if err := r.instance.network.deployHeadlessService(ctx, portsTCP, portsUDP); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -132,6 +186,11 @@ func (r *resources) destroyResources(ctx context.Context) error {
 			return ErrDestroyingServiceForInstance.WithParams(r.instance.name).Wrap(err)
 		}
 	}
+	if r.instance.execution.WorkloadType() == StatefulSetWorkload && !r.instance.sidecars.IsSidecar() {
+		if err := r.instance.network.destroyHeadlessService(ctx); err != nil {
+			return ErrDestroyingHeadlessService.WithParams(r.instance.name).Wrap(err)
+		}
+	}
 
 	// disable network only for non-sidecar instances
 	if !r.instance.sidecars.IsSidecar() {
@@ -139,6 +198,12 @@ func (r *resources) destroyResources(ctx context.Context) error {
 		if err := r.instance.network.enableIfDisabled(ctx); err != nil {
 			return ErrEnablingNetworkForInstance.WithParams(r.instance.name).Wrap(err)
 		}
+		if err := r.instance.network.destroyPartitionPolicy(ctx); err != nil {
+			return err
+		}
+		if err := r.instance.network.destroyNetworkPolicy(ctx); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -158,5 +223,6 @@ func (r *resources) clone() *resources {
 		memoryRequest: memoryRequestCopy,
 		memoryLimit:   memoryLimitCopy,
 		cpuRequest:    cpuRequestCopy,
+		replicas:      r.replicas,
 	}
 }
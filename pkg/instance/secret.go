@@ -0,0 +1,176 @@
+package instance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+// envNameRegex matches the names of the environment variables, which are also keys of the secret of the instance
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// AddSecretFile adds a file with the given content and permissions to the instance
+// The content is stored in the secret of the instance instead of the image or the configmap,
+// the file is owned by root, so a container running as another user needs a mode readable by it
+// Adding a file to the same destination replaces its content
+// This function can only be called in the states 'Preparing', 'Committed' and 'Stopped'
+func (s *storage) AddSecretFile(bytes []byte, dest string, mode os.FileMode) error {
+	if !s.instance.IsInState(StatePreparing, StateCommitted, StateStopped) {
+		return ErrAddingSecretFileNotAllowed.WithParams(s.instance.state.String())
+	}
+	if dest == "" {
+		return ErrDestMustBeSet
+	}
+
+	if s.secretFileData == nil {
+		s.secretFileData = make(map[string][]byte)
+	}
+	if _, ok := s.secretFileData[dest]; !ok {
+		s.files = append(s.files, &k8s.File{
+			Dest:       dest,
+			Permission: fmt.Sprintf("%o", mode.Perm()),
+			Secret:     true,
+		})
+	} else {
+		for _, file := range s.files {
+			if file.Secret && file.Dest == dest {
+				file.Permission = fmt.Sprintf("%o", mode.Perm())
+			}
+		}
+	}
+	s.secretFileData[dest] = bytes
+
+	s.instance.Logger.WithFields(logrus.Fields{
+		"instance": s.instance.name,
+		"file":     dest,
+	}).Debug("added secret file")
+	return nil
+}
+
+// SetSecretEnv sets the given environment variable in the instance from its secret
+// Unlike SetEnvironmentVariable, the value is never part of the image or the pod spec
+// This function can only be called in the states 'Preparing', 'Committed' and 'Stopped'
+func (b *build) SetSecretEnv(key, value string) error {
+	if !b.instance.IsInState(StatePreparing, StateCommitted, StateStopped) {
+		return ErrSettingSecretEnvNotAllowed.WithParams(b.instance.state.String())
+	}
+	if !envNameRegex.MatchString(key) {
+		return ErrInvalidSecretEnvName.WithParams(key)
+	}
+	if b.secretEnv == nil {
+		b.secretEnv = make(map[string]string)
+	}
+	b.secretEnv[key] = value
+
+	b.instance.Logger.WithFields(logrus.Fields{
+		"instance": b.instance.name,
+		"key":      key,
+	}).Debug("set secret environment variable")
+	return nil
+}
+
+// secretEnvKeys returns the sorted names of the environment variables read from the secret of the instance
+func (b *build) secretEnvKeys() []string {
+	keys := make([]string, 0, len(b.secretEnv)+len(b.attachedSecretEnv))
+	for key := range b.secretEnv {
+		keys = append(keys, key)
+	}
+	keys = append(keys, b.attachedSecretEnv...)
+	sort.Strings(keys)
+	return keys
+}
+
+// hasSecret returns true if the instance has secret files, secret environment variables or signed files
+func (i *Instance) hasSecret() bool {
+	return len(i.storage.secretFileData) != 0 || len(i.build.secretEnv) != 0 ||
+		i.storage.fileCredentials != "" || i.hasAttachedSecret()
+}
+
+// hasAttachedSecret returns true if the instance was attached with a secret, whose content is not known
+func (i *Instance) hasAttachedSecret() bool {
+	if len(i.build.attachedSecretEnv) != 0 {
+		return true
+	}
+	for _, file := range i.storage.files {
+		if _, ok := i.storage.secretFileData[file.Dest]; file.Secret && !ok {
+			return true
+		}
+		if file.Signed && i.storage.fileCredentials == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// secretData returns the data of the secret of the instance
+// The secret files are keyed by their index among the secret files, as they are mounted by the init container,
+// and the credentials of the signed files by k8s.FileCredentialsKey
+func (i *Instance) secretData() map[string][]byte {
+	data := make(map[string][]byte, len(i.storage.secretFileData)+len(i.build.secretEnv))
+	n := 0
+	for _, file := range i.storage.files {
+		if !file.Secret {
+			continue
+		}
+		data[k8s.SecretFileKey(n)] = i.storage.secretFileData[file.Dest]
+		n++
+	}
+	for key, value := range i.build.secretEnv {
+		data[key] = []byte(value)
+	}
+	if i.storage.fileCredentials != "" {
+		data[k8s.FileCredentialsKey] = []byte(i.storage.fileCredentials)
+	}
+	return data
+}
+
+// secretInstances returns the instance and its sidecars that have a secret
+func (e *execution) secretInstances() []*Instance {
+	var instances []*Instance
+	if e.instance.hasSecret() {
+		instances = append(instances, e.instance)
+	}
+	for _, sidecar := range e.instance.sidecars.sidecars {
+		if sidecar.Instance().hasSecret() {
+			instances = append(instances, sidecar.Instance())
+		}
+	}
+	return instances
+}
+
+// deploySecrets creates or updates the secrets of the instance and its sidecars
+func (e *execution) deploySecrets(ctx context.Context) error {
+	for _, i := range e.secretInstances() {
+		secretName := k8s.ContainerSecretName(i.name)
+		if i.hasAttachedSecret() {
+			i.Logger.WithField("secret", secretName).Debug("keeping the secret of the attached instance")
+			continue
+		}
+		_, err := i.K8sClient.CreateOrUpdateSecret(ctx, secretName, i.execution.Labels(), v1.SecretTypeOpaque, i.secretData())
+		if err != nil {
+			return ErrCreatingSecret.WithParams(i.name).Wrap(err)
+		}
+		i.Logger.WithFields(logrus.Fields{
+			"instance": i.name,
+			"secret":   secretName,
+		}).Debug("deployed secret")
+	}
+	return nil
+}
+
+// destroySecrets deletes the secrets of the instance and its sidecars
+func (e *execution) destroySecrets(ctx context.Context) error {
+	for _, i := range e.secretInstances() {
+		if err := i.K8sClient.DeleteSecret(ctx, k8s.ContainerSecretName(i.name)); err != nil {
+			return ErrDeletingSecret.WithParams(i.name).Wrap(err)
+		}
+	}
+	return nil
+}
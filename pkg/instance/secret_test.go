@@ -0,0 +1,96 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+func TestSecret(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	clientset := sysDeps.K8sClient.Clientset()
+	namespace := sysDeps.K8sClient.Namespace()
+
+	i := newCommittedTestInstance(t, sysDeps, "validator")
+	require.NoError(t, i.Storage().AddSecretFile([]byte("old"), "/home/validator/key.json", 0644))
+	require.NoError(t, i.Storage().AddSecretFile([]byte("key"), "/home/validator/key.json", 0600))
+	require.NoError(t, i.Build().SetSecretEnv("MNEMONIC", "word word"))
+	assert.ErrorIs(t, i.Build().SetSecretEnv("not-valid", "value"), ErrInvalidSecretEnvName)
+
+	require.NoError(t, i.Execution().StartAsync(ctx))
+	assert.ErrorIs(t, i.Storage().AddSecretFile([]byte("key"), "/key.json", 0600), ErrAddingSecretFileNotAllowed)
+	assert.ErrorIs(t, i.Build().SetSecretEnv("TOKEN", "value"), ErrSettingSecretEnvNotAllowed)
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, "validator-secret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"file-0": []byte("key"), "MNEMONIC": []byte("word word")}, secret.Data)
+
+	rs, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, i.execution.workloadName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	podSpec := rs.Spec.Template.Spec
+	// the values are only read from the secret, they are not part of the pod spec
+	assert.Contains(t, podSpec.Containers[0].Env, v1.EnvVar{
+		Name: "MNEMONIC",
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "validator-secret"},
+			Key:                  "MNEMONIC",
+		}},
+	})
+	var volumeNames []string
+	for _, volume := range podSpec.Volumes {
+		volumeNames = append(volumeNames, volume.Name)
+	}
+	assert.Contains(t, volumeNames, "validator-secret")
+	assert.NotContains(t, volumeNames, "validator-config")
+	require.Len(t, podSpec.InitContainers, 1)
+	assert.Contains(t, podSpec.InitContainers[0].Command[2], "chmod 600 /knuu/home/validator/key.json")
+
+	require.NoError(t, i.Execution().Destroy(ctx))
+	_, err = clientset.CoreV1().Secrets(namespace).Get(ctx, "validator-secret", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestSecretFileCredentials(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	clientset := sysDeps.K8sClient.Clientset()
+	namespace := sysDeps.K8sClient.Namespace()
+
+	i := newStartedTestInstance(t, sysDeps, "node")
+	require.NoError(t, i.Execution().Stop(ctx))
+	// the file is deployed as if it was uploaded to minio
+	i.storage.files = append(i.storage.files, &k8s.File{
+		Source: "snapshot.tar",
+		Dest:   "/data/snapshot.tar",
+		URL:    "http://minio-service.test:9000/knuu-files/test/node/data/snapshot.tar",
+		Signed: true,
+	})
+	i.storage.fileCredentials = "minio:minio-secret-key"
+	require.NoError(t, i.Execution().StartAsync(ctx))
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, "node-secret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("minio:minio-secret-key"), secret.Data[k8s.FileCredentialsKey])
+
+	rs, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, i.execution.workloadName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, rs.Spec.Template.Spec.InitContainers, 1)
+	initContainer := rs.Spec.Template.Spec.InitContainers[0]
+	for _, arg := range initContainer.Command {
+		assert.NotContains(t, arg, "minio-secret-key")
+	}
+	assert.Contains(t, initContainer.Env, v1.EnvVar{
+		Name: k8s.FileCredentialsKey,
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "node-secret"},
+			Key:                  k8s.FileCredentialsKey,
+		}},
+	})
+}
@@ -0,0 +1,295 @@
+package instance
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+// sharedVolumePrefix prefixes the name of the kubernetes objects backing the shared volumes
+const (
+	sharedVolumePrefix = "shared-"
+	// labelSharedVolumeKey holds the name of the shared volume on its kubernetes object,
+	// the name label is not used as it selects the pods of the instance of that name
+	labelSharedVolumeKey = "knuu.sh/shared-volume"
+)
+
+// SharedVolumeType is the kind of kubernetes object backing a shared volume
+type SharedVolumeType int
+
+const (
+	// SharedPersistentVolume is backed by a ReadWriteMany PersistentVolumeClaim
+	SharedPersistentVolume SharedVolumeType = iota
+	// SharedConfigMapVolume is backed by a ConfigMap, its keys are the files of the volume
+	SharedConfigMapVolume
+	// SharedSecretVolume is backed by a Secret, its keys are the files of the volume
+	SharedSecretVolume
+)
+
+// String returns the string representation of the type
+func (t SharedVolumeType) String() string {
+	switch t {
+	case SharedPersistentVolume:
+		return "PersistentVolume"
+	case SharedConfigMapVolume:
+		return "ConfigMap"
+	case SharedSecretVolume:
+		return "Secret"
+	}
+	return "Unknown"
+}
+
+// SharedVolume is a named volume of the scope that several instances mount, possibly at different paths
+// Its lifecycle is independent of the instances mounting it: it is deployed by Deploy or when the first instance
+// mounting it starts, and it is only destroyed by Destroy or with the scope
+type SharedVolume struct {
+	*system.SystemDependencies
+
+	name       string
+	volumeType SharedVolumeType
+	size       resource.Quantity
+	data       map[string]string
+	secretData map[string][]byte
+}
+
+// NewSharedVolume creates a shared volume backed by a ReadWriteMany PersistentVolumeClaim of the given size
+// The storage class of the cluster must support the ReadWriteMany access mode, e.g. NFS, CephFS or EFS
+func NewSharedVolume(name string, size resource.Quantity, sysDeps *system.SystemDependencies) (*SharedVolume, error) {
+	if size.Value() <= 0 {
+		return nil, ErrInvalidSharedVolumeSize.WithParams(name)
+	}
+	return newSharedVolume(name, SharedPersistentVolume, sysDeps, func(v *SharedVolume) {
+		v.size = size
+	})
+}
+
+// NewSharedConfigMapVolume creates a shared volume backed by a ConfigMap, every key of data is a file of the volume
+func NewSharedConfigMapVolume(name string, data map[string]string, sysDeps *system.SystemDependencies) (*SharedVolume, error) {
+	return newSharedVolume(name, SharedConfigMapVolume, sysDeps, func(v *SharedVolume) {
+		v.data = data
+	})
+}
+
+// NewSharedSecretVolume creates a shared volume backed by a Secret, every key of data is a file of the volume
+func NewSharedSecretVolume(name string, data map[string][]byte, sysDeps *system.SystemDependencies) (*SharedVolume, error) {
+	return newSharedVolume(name, SharedSecretVolume, sysDeps, func(v *SharedVolume) {
+		v.secretData = data
+	})
+}
+
+func newSharedVolume(name string, volumeType SharedVolumeType, sysDeps *system.SystemDependencies, set func(*SharedVolume)) (*SharedVolume, error) {
+	name = k8s.SanitizeName(name)
+	if name == "" {
+		return nil, ErrSharedVolumeNameRequired
+	}
+	v := &SharedVolume{
+		SystemDependencies: sysDeps,
+		name:               name,
+		volumeType:         volumeType,
+	}
+	set(v)
+	return v, nil
+}
+
+// Name returns the name of the shared volume
+func (v *SharedVolume) Name() string {
+	return v.name
+}
+
+// Type returns the kind of kubernetes object backing the shared volume
+func (v *SharedVolume) Type() SharedVolumeType {
+	return v.volumeType
+}
+
+// Deploy creates the kubernetes object backing the shared volume
+// The data of a ConfigMap or a Secret volume is updated if it already exists,
+// the running instances see the new data after the kubelet sync period
+func (v *SharedVolume) Deploy(ctx context.Context) error {
+	if err := v.deploy(ctx, true); err != nil {
+		return ErrDeployingSharedVolume.WithParams(v.name).Wrap(err)
+	}
+	return nil
+}
+
+// Destroy deletes the kubernetes object backing the shared volume
+// The instances mounting it must be destroyed first
+func (v *SharedVolume) Destroy(ctx context.Context) error {
+	var err error
+	switch v.volumeType {
+	case SharedPersistentVolume:
+		err = v.K8sClient.DeletePersistentVolumeClaim(ctx, v.objectName())
+	case SharedConfigMapVolume:
+		err = v.K8sClient.DeleteConfigMap(ctx, v.objectName())
+	case SharedSecretVolume:
+		err = v.K8sClient.DeleteSecret(ctx, v.objectName())
+	}
+	if err != nil {
+		return ErrDestroyingSharedVolume.WithParams(v.name).Wrap(err)
+	}
+	v.Logger.WithField("volume", v.name).Debug("destroyed shared volume")
+	return nil
+}
+
+// ensureDeployed creates the kubernetes object backing the shared volume if it does not exist yet
+func (v *SharedVolume) ensureDeployed(ctx context.Context) error {
+	if err := v.deploy(ctx, false); err != nil {
+		return ErrDeployingSharedVolume.WithParams(v.name).Wrap(err)
+	}
+	return nil
+}
+
+func (v *SharedVolume) deploy(ctx context.Context, update bool) error {
+	name := v.objectName()
+	switch v.volumeType {
+	case SharedPersistentVolume:
+		exists, err := v.K8sClient.PersistentVolumeClaimExists(ctx, name)
+		if err != nil || exists {
+			return err
+		}
+		err = v.K8sClient.CreatePersistentVolumeClaim(ctx, name, v.labels(), v.size, k8s.WithAccessModes(v1.ReadWriteMany))
+		if err != nil {
+			return err
+		}
+	case SharedConfigMapVolume:
+		exists, err := v.K8sClient.ConfigMapExists(ctx, name)
+		if err != nil || (exists && !update) {
+			return err
+		}
+		if _, err := v.K8sClient.CreateOrUpdateConfigMap(ctx, name, v.labels(), v.data); err != nil {
+			return err
+		}
+	case SharedSecretVolume:
+		if !update {
+			if _, err := v.K8sClient.GetSecret(ctx, name); err == nil {
+				return nil
+			}
+		}
+		if _, err := v.K8sClient.CreateOrUpdateSecret(ctx, name, v.labels(), v1.SecretTypeOpaque, v.secretData); err != nil {
+			return err
+		}
+	}
+
+	v.Logger.WithFields(logrus.Fields{
+		"volume": v.name,
+		"type":   v.volumeType.String(),
+	}).Debug("deployed shared volume")
+	return nil
+}
+
+// objectName returns the name of the kubernetes object backing the shared volume, which is also its name in the pods
+func (v *SharedVolume) objectName() string {
+	return sharedVolumePrefix + v.name
+}
+
+func (v *SharedVolume) labels() map[string]string {
+	return map[string]string{
+		LabelManagedByKey:    LabelKnuuValue,
+		LabelScopeKey:        v.Scope,
+		LabelTestStartedKey:  v.StartTime,
+		labelSharedVolumeKey: v.name,
+	}
+}
+
+// volumeSource returns the source of the shared volume in the pods
+func (v *SharedVolume) volumeSource() v1.VolumeSource {
+	switch v.volumeType {
+	case SharedConfigMapVolume:
+		return v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+			LocalObjectReference: v1.LocalObjectReference{Name: v.objectName()},
+		}}
+	case SharedSecretVolume:
+		return v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: v.objectName()}}
+	default:
+		return v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: v.objectName()}}
+	}
+}
+
+// sharedVolumeFromK8s rebuilds a shared volume from its mount in a pod spec, it is used when attaching to running instances
+func sharedVolumeFromK8s(sv *k8s.SharedVolume, sysDeps *system.SystemDependencies) *SharedVolume {
+	v := &SharedVolume{
+		SystemDependencies: sysDeps,
+		name:               strings.TrimPrefix(sv.Name, sharedVolumePrefix),
+	}
+	switch {
+	case sv.Source.ConfigMap != nil:
+		v.volumeType = SharedConfigMapVolume
+	case sv.Source.Secret != nil:
+		v.volumeType = SharedSecretVolume
+	default:
+		v.volumeType = SharedPersistentVolume
+	}
+	return v
+}
+
+// sharedVolumeMount is a shared volume mounted by an instance
+type sharedVolumeMount struct {
+	volume   *SharedVolume
+	path     string
+	readOnly bool
+}
+
+// AddSharedVolume mounts the shared volume at the given path of the instance
+// ConfigMap and Secret volumes are always mounted read-only
+// This function can only be called in the states 'Preparing', 'Committed' and 'Stopped'
+func (s *storage) AddSharedVolume(volume *SharedVolume, path string, readOnly bool) error {
+	if !s.instance.IsInState(StatePreparing, StateCommitted, StateStopped) {
+		return ErrAddingSharedVolumeNotAllowed.WithParams(s.instance.state.String())
+	}
+	if volume == nil {
+		return ErrSharedVolumeRequired
+	}
+	if path == "" {
+		return ErrDestMustBeSet
+	}
+	for _, m := range s.sharedVolumes {
+		if m.volume.name == volume.name {
+			return ErrSharedVolumeAlreadyAdded.WithParams(volume.name, s.instance.name)
+		}
+	}
+	if volume.volumeType != SharedPersistentVolume {
+		readOnly = true
+	}
+
+	s.sharedVolumes = append(s.sharedVolumes, &sharedVolumeMount{volume: volume, path: path, readOnly: readOnly})
+	s.instance.Logger.WithFields(logrus.Fields{
+		"instance":  s.instance.name,
+		"volume":    volume.name,
+		"path":      path,
+		"read_only": readOnly,
+	}).Debug("added shared volume")
+	return nil
+}
+
+// deploySharedVolumes deploys the shared volumes of the instance and its sidecars that do not exist yet
+func (e *execution) deploySharedVolumes(ctx context.Context) error {
+	mounts := e.instance.storage.sharedVolumes
+	for _, sidecar := range e.instance.sidecars.sidecars {
+		mounts = append(mounts, sidecar.Instance().storage.sharedVolumes...)
+	}
+	for _, m := range mounts {
+		if err := m.volume.ensureDeployed(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// k8sSharedVolumes returns the shared volumes of the instance for its container config
+func (s *storage) k8sSharedVolumes() []*k8s.SharedVolume {
+	sharedVolumes := make([]*k8s.SharedVolume, 0, len(s.sharedVolumes))
+	for _, m := range s.sharedVolumes {
+		sharedVolumes = append(sharedVolumes, &k8s.SharedVolume{
+			Name:     m.volume.objectName(),
+			Path:     m.path,
+			ReadOnly: m.readOnly,
+			Source:   m.volume.volumeSource(),
+		})
+	}
+	return sharedVolumes
+}
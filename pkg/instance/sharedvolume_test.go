@@ -0,0 +1,76 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSharedVolume(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	clientset := sysDeps.K8sClient.Clientset()
+	namespace := sysDeps.K8sClient.Namespace()
+
+	_, err := NewSharedVolume("keyring", resource.Quantity{}, sysDeps)
+	assert.ErrorIs(t, err, ErrInvalidSharedVolumeSize)
+
+	keyring, err := NewSharedVolume("keyring", resource.MustParse("1Gi"), sysDeps)
+	require.NoError(t, err)
+	genesis, err := NewSharedConfigMapVolume("genesis", map[string]string{"genesis.json": "{}"}, sysDeps)
+	require.NoError(t, err)
+
+	validator := newCommittedTestInstance(t, sysDeps, "validator")
+	require.NoError(t, validator.Storage().AddSharedVolume(keyring, "/home/validator/keyring", false))
+	require.NoError(t, validator.Storage().AddSharedVolume(genesis, "/home/validator/config", false))
+	assert.ErrorIs(t, validator.Storage().AddSharedVolume(keyring, "/keyring", false), ErrSharedVolumeAlreadyAdded)
+
+	bridge := newCommittedTestInstance(t, sysDeps, "bridge")
+	require.NoError(t, bridge.Storage().AddSharedVolume(keyring, "/keyring", true))
+
+	// the shared volumes are deployed when the first instance mounting them starts
+	require.NoError(t, validator.Execution().StartAsync(ctx))
+	require.NoError(t, bridge.Execution().StartAsync(ctx))
+	assert.ErrorIs(t, bridge.Storage().AddSharedVolume(genesis, "/config", true), ErrAddingSharedVolumeNotAllowed)
+
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, "shared-keyring", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []v1.PersistentVolumeAccessMode{v1.ReadWriteMany}, pvc.Spec.AccessModes)
+	// the name label would select the pods of an instance named like the volume, e.g. in network policies
+	assert.Equal(t, "keyring", pvc.Labels[labelSharedVolumeKey])
+	assert.NotContains(t, pvc.Labels, LabelNameKey)
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, "shared-genesis", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "{}", cm.Data["genesis.json"])
+
+	validatorMounts := replicaSetMounts(t, validator)
+	assert.Contains(t, validatorMounts, v1.VolumeMount{Name: "shared-keyring", MountPath: "/home/validator/keyring"})
+	// the configmap volumes are always read-only
+	assert.Contains(t, validatorMounts, v1.VolumeMount{Name: "shared-genesis", MountPath: "/home/validator/config", ReadOnly: true})
+	assert.Equal(t, []v1.VolumeMount{{Name: "shared-keyring", MountPath: "/keyring", ReadOnly: true}}, replicaSetMounts(t, bridge))
+
+	// the shared volumes outlive the instances mounting them
+	require.NoError(t, validator.Execution().Destroy(ctx))
+	require.NoError(t, bridge.Execution().Destroy(ctx))
+	_, err = clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, "shared-keyring", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, keyring.Destroy(ctx))
+	require.NoError(t, genesis.Destroy(ctx))
+	_, err = clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, "shared-keyring", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = clientset.CoreV1().ConfigMaps(namespace).Get(ctx, "shared-genesis", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func replicaSetMounts(t *testing.T, i *Instance) []v1.VolumeMount {
+	rs, err := i.K8sClient.Clientset().AppsV1().ReplicaSets(i.K8sClient.Namespace()).Get(context.Background(), i.execution.workloadName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, rs.Spec.Template.Spec.Containers)
+	return rs.Spec.Template.Spec.Containers[0].VolumeMounts
+}
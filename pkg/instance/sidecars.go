@@ -35,6 +35,13 @@ func (s *sidecars) IsSidecar() bool {
 	return s.isSidecar
 }
 
+// List returns the sidecars of the instance in the order they were added
+func (s *sidecars) List() []SidecarManager {
+	list := make([]SidecarManager, len(s.sidecars))
+	copy(list, s.sidecars)
+	return list
+}
+
 // Add adds a sidecar to the instance
 // This function can only be called in the state 'Preparing', 'Committed' or 'Stopped'
 func (s *sidecars) Add(ctx context.Context, sc SidecarManager) error {
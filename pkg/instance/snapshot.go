@@ -0,0 +1,110 @@
+package instance
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+// labelSnapshotKey holds the name of the snapshot on its VolumeSnapshot,
+// the name label is not used as it selects the pods of the instance of that name
+const labelSnapshotKey = "knuu.sh/snapshot"
+
+// Snapshot takes a CSI snapshot of the volume of the instance and waits until it can be restored
+// The snapshot belongs to the scope and outlives the instance, e.g. a synced node state restored
+// by AddVolumeFromSnapshot in other instances of the same test, it is deleted along with the scope
+// The cluster must have the snapshot CRDs and controller and a default VolumeSnapshotClass for the storage class of the volume
+// This function can only be called in the state 'Stopped'
+func (s *storage) Snapshot(ctx context.Context, name string) error {
+	if !s.instance.IsInState(StateStopped) {
+		return ErrTakingSnapshotNotAllowed.WithParams(s.instance.state.String())
+	}
+	if len(s.volumes) == 0 || s.isEphemeral() {
+		return ErrSnapshotRequiresVolume.WithParams(s.instance.name)
+	}
+	if s.instance.execution.WorkloadType() == StatefulSetWorkload {
+		return ErrSnapshotStatefulSetNotSupported.WithParams(s.instance.name)
+	}
+
+	err := s.instance.K8sClient.CreateVolumeSnapshot(ctx, name, s.snapshotLabels(name), s.instance.name)
+	if err != nil {
+		return ErrTakingSnapshot.WithParams(name, s.instance.name).Wrap(err)
+	}
+	if _, err := s.instance.K8sClient.WaitForVolumeSnapshotReady(ctx, name); err != nil {
+		return ErrTakingSnapshot.WithParams(name, s.instance.name).Wrap(err)
+	}
+
+	s.instance.Logger.WithFields(logrus.Fields{
+		"instance": s.instance.name,
+		"snapshot": name,
+	}).Debug("took volume snapshot")
+	return nil
+}
+
+// AddVolumeFromSnapshot adds a volume restored from the given snapshot to the instance
+// The snapshot holds the volume at the path it had in the snapshotted instance, so the same path must be used
+// The owner of the volume is set to 0, if the snapshotted volume had a custom owner use AddVolumeFromSnapshotWithOwner
+// The restored data keeps the ownership it had in the snapshot and the content of the image is not copied into the volume
+// The size of the volume is the restore size of the snapshot
+// This function can only be called in the states 'Preparing' and 'Committed'
+func (s *storage) AddVolumeFromSnapshot(path, snapshotName string) error {
+	return s.AddVolumeFromSnapshotWithOwner(path, snapshotName, 0)
+}
+
+// AddVolumeFromSnapshotWithOwner adds a volume restored from the given snapshot to the instance with the given owner
+// The owner must be the one of the volume of the snapshotted instance
+// This function can only be called in the states 'Preparing' and 'Committed'
+func (s *storage) AddVolumeFromSnapshotWithOwner(path, snapshotName string, owner int64) error {
+	if !s.instance.IsInState(StatePreparing, StateCommitted) {
+		return ErrAddingVolumeFromSnapshotNotAllowed.WithParams(s.instance.state.String())
+	}
+	if snapshotName == "" {
+		return ErrSnapshotNameRequired
+	}
+	// temporary feat, we will remove it once we can add multiple volumes
+	if len(s.volumes) > 0 {
+		return ErrMaximumVolumesExceeded.WithParams(s.instance.name)
+	}
+
+	volume := s.instance.K8sClient.NewVolume(path, resource.Quantity{}, owner)
+	volume.Restored = true
+	s.volumes = append(s.volumes, volume)
+	s.volumeSnapshot = snapshotName
+	s.instance.Logger.WithFields(logrus.Fields{
+		"volume":   path,
+		"owner":    owner,
+		"snapshot": snapshotName,
+		"instance": s.instance.name,
+	}).Debug("added volume from snapshot")
+	return nil
+}
+
+// restoreSnapshotOptions returns the options restoring the volume of the instance from its snapshot
+// It waits until the snapshot is ready and sizes the restored volume after it
+func (s *storage) restoreSnapshotOptions(ctx context.Context) ([]k8s.PersistentVolumeClaimOption, error) {
+	if s.volumeSnapshot == "" {
+		return nil, nil
+	}
+	snapshot, err := s.instance.K8sClient.WaitForVolumeSnapshotReady(ctx, s.volumeSnapshot)
+	if err != nil {
+		return nil, ErrRestoringSnapshot.WithParams(s.volumeSnapshot, s.instance.name).Wrap(err)
+	}
+	for _, volume := range s.volumes {
+		if volume.Size.IsZero() {
+			volume.Size = snapshot.RestoreSize
+		}
+	}
+	return []k8s.PersistentVolumeClaimOption{k8s.WithVolumeSnapshotSource(s.volumeSnapshot)}, nil
+}
+
+func (s *storage) snapshotLabels(name string) map[string]string {
+	return map[string]string{
+		LabelManagedByKey:   LabelKnuuValue,
+		LabelScopeKey:       s.instance.Scope,
+		LabelTestStartedKey: s.instance.StartTime,
+		labelSnapshotKey:    name,
+	}
+}
@@ -0,0 +1,70 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var volumeSnapshotGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	clientset := sysDeps.K8sClient.Clientset()
+	namespace := sysDeps.K8sClient.Namespace()
+
+	// the snapshots are ready as soon as they are created, as if the snapshot controller took them
+	sysDeps.K8sClient.DynamicClient().(*dynfake.FakeDynamicClient).PrependReactor("create", "volumesnapshots",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+			require.NoError(t, unstructured.SetNestedField(obj.Object, true, "status", "readyToUse"))
+			require.NoError(t, unstructured.SetNestedField(obj.Object, "2Gi", "status", "restoreSize"))
+			return false, nil, nil
+		})
+
+	synced := newCommittedTestInstance(t, sysDeps, "synced")
+	assert.ErrorIs(t, synced.Storage().Snapshot(ctx, "state"), ErrTakingSnapshotNotAllowed)
+	require.NoError(t, synced.Storage().AddVolume("/home/celestia", resource.MustParse("1Gi")))
+	require.NoError(t, synced.Execution().StartAsync(ctx))
+	require.NoError(t, synced.Execution().Stop(ctx))
+	require.NoError(t, synced.Storage().Snapshot(ctx, "state"))
+
+	snapshot, err := sysDeps.K8sClient.DynamicClient().Resource(volumeSnapshotGVR).Namespace(namespace).Get(ctx, "state", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "test", snapshot.GetLabels()[LabelScopeKey])
+	assert.Equal(t, "state", snapshot.GetLabels()[labelSnapshotKey])
+	assert.NotContains(t, snapshot.GetLabels(), LabelNameKey)
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "synced", source)
+
+	restored := newCommittedTestInstance(t, sysDeps, "restored")
+	assert.ErrorIs(t, restored.Storage().AddVolumeFromSnapshot("/home/celestia", ""), ErrSnapshotNameRequired)
+	require.NoError(t, restored.Storage().AddVolumeFromSnapshotWithOwner("/home/celestia", "state", 10001))
+	require.NoError(t, restored.Execution().StartAsync(ctx))
+
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, "restored", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, pvc.Spec.DataSource)
+	assert.Equal(t, "VolumeSnapshot", pvc.Spec.DataSource.Kind)
+	assert.Equal(t, "state", pvc.Spec.DataSource.Name)
+	// the volume is as large as the restore size of the snapshot
+	size := pvc.Spec.Resources.Requests["storage"]
+	assert.Equal(t, "2Gi", size.String())
+
+	// the restored data is not overwritten by the content of the image
+	rs, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, restored.execution.workloadName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, rs.Spec.Template.Spec.InitContainers)
+	assert.Contains(t, rs.Spec.Template.Spec.Containers[0].VolumeMounts, v1.VolumeMount{Name: "restored", MountPath: "/home/celestia", SubPath: "home/celestia"})
+}
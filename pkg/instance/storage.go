@@ -2,6 +2,8 @@ package instance
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -18,10 +20,26 @@ import (
 
 const maxTotalFilesBytes = 1024 * 1024
 
+const (
+	// largeFileThreshold is the size above which the files added after commit are uploaded to MinIO,
+	// if a MinIO client is set, and downloaded by the init container instead of being mounted from the configmap
+	largeFileThreshold = 512 * 1024
+	// FilesBucketName is the MinIO bucket of the large files, they are stored under the UploadedFilesPrefix of their scope
+	FilesBucketName = "knuu-files"
+)
+
 type storage struct {
-	instance *Instance
-	volumes  []*k8s.Volume
-	files    []*k8s.File
+	instance      *Instance
+	volumes       []*k8s.Volume
+	files         []*k8s.File
+	sharedVolumes []*sharedVolumeMount
+	// secretFileData is the content of the secret files, by destination
+	secretFileData map[string][]byte
+	// volumeSnapshot is the name of the snapshot the volume is restored from
+	volumeSnapshot string
+	// fileCredentials are the credentials of MinIO signing the download of the uploaded files,
+	// they are stored in the secret of the instance to keep them out of the pod spec
+	fileCredentials string
 }
 
 const defaultFilePermission = 0644
@@ -58,7 +76,7 @@ func (s *storage) AddFile(src string, dest string, chown string) error {
 		if err != nil {
 			return ErrFailedToGetFileSize.Wrap(err)
 		}
-		if srcInfo.Size() > maxTotalFilesBytes {
+		if srcInfo.Size() > maxTotalFilesBytes && s.instance.MinioClient == nil {
 			return ErrFileTooLargeCommitted.WithParams(src)
 		}
 		return s.addFileToInstance(buildDirPath, dest, chown)
@@ -166,8 +184,9 @@ func (s *storage) AddFileBytes(bytes []byte, dest string, chown string) error {
 
 // AddVolume adds a volume to the instance
 // The owner of the volume is set to 0, if you want to set a custom owner use AddVolumeWithOwner
+// The options set the storage class, the access and volume modes or make the volume an emptyDir or a tmpfs
 // This function can only be called in the states 'Preparing', 'Committed' and 'Stopped'
-func (s *storage) AddVolume(path string, size resource.Quantity) error {
+func (s *storage) AddVolume(path string, size resource.Quantity, opts ...VolumeOption) error {
 	// temporary feat, we will remove it once we can add multiple volumes
 	if len(s.volumes) > 0 {
 		s.instance.Logger.WithFields(logrus.Fields{
@@ -176,12 +195,12 @@ func (s *storage) AddVolume(path string, size resource.Quantity) error {
 		}).Debug("maximum volumes exceeded")
 		return ErrMaximumVolumesExceeded.WithParams(s.instance.name)
 	}
-	return s.AddVolumeWithOwner(path, size, 0)
+	return s.AddVolumeWithOwner(path, size, 0, opts...)
 }
 
 // AddVolumeWithOwner adds a volume to the instance with the given owner
 // This function can only be called in the states 'Preparing', 'Committed' and 'Stopped'
-func (s *storage) AddVolumeWithOwner(path string, size resource.Quantity, owner int64) error {
+func (s *storage) AddVolumeWithOwner(path string, size resource.Quantity, owner int64, opts ...VolumeOption) error {
 	if !s.instance.IsInState(StatePreparing, StateCommitted, StateStopped) {
 		return ErrAddingVolumeNotAllowed.WithParams(s.instance.state.String())
 	}
@@ -194,12 +213,17 @@ func (s *storage) AddVolumeWithOwner(path string, size resource.Quantity, owner
 		return ErrMaximumVolumesExceeded.WithParams(s.instance.name)
 	}
 	volume := s.instance.K8sClient.NewVolume(path, size, owner)
+	for _, opt := range opts {
+		opt(volume)
+	}
 	s.volumes = append(s.volumes, volume)
 	s.instance.Logger.WithFields(logrus.Fields{
-		"volume":   path,
-		"size":     size.String(),
-		"owner":    owner,
-		"instance": s.instance.name,
+		"volume":        path,
+		"size":          size.String(),
+		"owner":         owner,
+		"storage_class": volume.StorageClass,
+		"empty_dir":     volume.EmptyDir,
+		"instance":      s.instance.name,
 	}).Debug("added volume")
 	return nil
 }
@@ -323,19 +347,27 @@ func (s *storage) addFileToInstance(srcPath, dest, chown string) error {
 	// get the permission of the src file
 	permission := fmt.Sprintf("%o", srcInfo.Mode().Perm())
 
+	// the large files are not part of the configmap
 	size := int64(0)
 	for _, file := range s.files {
+		if file.Secret {
+			continue
+		}
 		srcInfo, err := os.Stat(file.Source)
 		if err != nil {
 			return ErrFailedToGetFileSize.Wrap(err)
 		}
-		size += srcInfo.Size()
+		if !s.isLargeFile(srcInfo.Size()) {
+			size += srcInfo.Size()
+		}
 	}
 	srcInfo, err = os.Stat(srcPath)
 	if err != nil {
 		return ErrFailedToGetFileSize.Wrap(err)
 	}
-	size += srcInfo.Size()
+	if !s.isLargeFile(srcInfo.Size()) {
+		size += srcInfo.Size()
+	}
 	if size > maxTotalFilesBytes {
 		return ErrTotalFilesSizeTooLarge.WithParams(srcPath)
 	}
@@ -346,16 +378,38 @@ func (s *storage) addFileToInstance(srcPath, dest, chown string) error {
 	return nil
 }
 
+// isLargeFile returns true if a file of the given size is delivered through MinIO
+func (s *storage) isLargeFile(size int64) bool {
+	return s.instance.MinioClient != nil && size > largeFileThreshold
+}
+
 // checkStateForAddingFile checks if the current state allows adding a file
 func (s *storage) checkStateForAddingFile() error {
 	if !s.instance.IsInState(StatePreparing, StateCommitted, StateStopped) {
 		return ErrAddingFileNotAllowed.WithParams(s.instance.state.String())
 	}
+	// the files of a Dockerfile build belong to its build context
+	if s.instance.IsState(StatePreparing) && s.instance.build.isDockerfileBuild() {
+		return ErrModifyingDockerfileBuildNotAllowed.WithParams(s.instance.name)
+	}
 	return nil
 }
 
 // deployVolume deploys the volume for the instance
+// For a StatefulSet workload, the volumes are claimed per replica by the StatefulSet itself
 func (s *storage) deployVolume(ctx context.Context) error {
+	if s.instance.execution.WorkloadType() == StatefulSetWorkload {
+		if s.volumeSnapshot != "" {
+			return ErrSnapshotStatefulSetNotSupported.WithParams(s.instance.name)
+		}
+		s.instance.Logger.WithField("instance", s.instance.name).Debug("volumes are claimed by the statefulSet, skipping deployment")
+		return nil
+	}
+	if s.isEphemeral() {
+		s.instance.Logger.WithField("instance", s.instance.name).Debug("volumes are emptyDirs of the pod, skipping deployment")
+		return nil
+	}
+
 	// Check if PVC already exists
 	exists, err := s.instance.K8sClient.PersistentVolumeClaimExists(ctx, s.instance.name)
 	if err != nil {
@@ -367,11 +421,16 @@ func (s *storage) deployVolume(ctx context.Context) error {
 		return nil
 	}
 
+	opts, err := s.restoreSnapshotOptions(ctx)
+	if err != nil {
+		return err
+	}
 	totalSize := resource.Quantity{}
 	for _, volume := range s.volumes {
 		totalSize.Add(volume.Size)
 	}
-	err = s.instance.K8sClient.CreatePersistentVolumeClaim(ctx, s.instance.name, s.instance.execution.Labels(), totalSize)
+	opts = append(opts, k8s.VolumeClaimOptions(s.volumes)...)
+	err = s.instance.K8sClient.CreatePersistentVolumeClaim(ctx, s.instance.name, s.instance.execution.Labels(), totalSize, opts...)
 	if err != nil {
 		return ErrFailedToCreatePersistentVolumeClaim.Wrap(err)
 	}
@@ -385,6 +444,13 @@ func (s *storage) deployVolume(ctx context.Context) error {
 
 // destroyVolume destroys the volume for the instance
 func (s *storage) destroyVolume(ctx context.Context) error {
+	if s.instance.execution.WorkloadType() == StatefulSetWorkload {
+		return s.destroyStatefulSetVolumes(ctx)
+	}
+	if s.isEphemeral() {
+		return nil
+	}
+
 	err := s.instance.K8sClient.DeletePersistentVolumeClaim(ctx, s.instance.name)
 	if err != nil {
 		return ErrFailedToDeletePersistentVolumeClaim.Wrap(err)
@@ -393,29 +459,52 @@ func (s *storage) destroyVolume(ctx context.Context) error {
 	return nil
 }
 
+// destroyStatefulSetVolumes destroys the volumes claimed by the StatefulSet of the instance
+// The claims of the sidecars carry the labels of the parent instance
+func (s *storage) destroyStatefulSetVolumes(ctx context.Context) error {
+	labels := s.instance.execution.Labels()
+	if s.instance.sidecars.IsSidecar() {
+		labels = s.instance.parentInstance.execution.Labels()
+	}
+
+	err := s.instance.K8sClient.DeletePersistentVolumeClaimsByLabels(ctx, labels)
+	if err != nil {
+		return ErrFailedToDeletePersistentVolumeClaim.Wrap(err)
+	}
+	s.instance.Logger.WithField("instance", s.instance.name).Debug("destroyed persistent volumes of statefulSet")
+	return nil
+}
+
 // deployFiles deploys the files for the instance
+// The large files are uploaded to MinIO and the other files are deployed in a configmap
 func (s *storage) deployFiles(ctx context.Context) error {
 	data := map[string]string{}
 
-	for i, file := range s.files {
-		// read out file content and assign to variable
-		srcFile, err := os.Open(file.Source)
+	for _, file := range s.files {
+		// the secret files are deployed in the secret of the instance
+		if file.Secret {
+			continue
+		}
+		srcInfo, err := os.Stat(file.Source)
 		if err != nil {
-			return ErrFailedToOpenFile.Wrap(err)
+			return ErrFailedToGetFileSize.Wrap(err)
+		}
+		if s.isLargeFile(srcInfo.Size()) {
+			if err := s.uploadFile(ctx, file); err != nil {
+				return err
+			}
+			continue
 		}
-		defer srcFile.Close()
+		file.URL, file.Signed, file.Checksum = "", false, ""
 
-		fileContentBytes, err := io.ReadAll(srcFile)
+		// read out file content and assign to variable
+		fileContentBytes, err := os.ReadFile(file.Source)
 		if err != nil {
 			return ErrFailedToReadFile.Wrap(err)
 		}
 
-		var (
-			fileContent = string(fileContentBytes)
-			keyName     = fmt.Sprintf("%d", i)
-		)
-
-		data[keyName] = fileContent
+		// the files of the configmap are keyed by their index among the files of the configmap
+		data[fmt.Sprintf("%d", len(data))] = string(fileContentBytes)
 	}
 
 	// If the configmap already exists, we update it
@@ -429,44 +518,116 @@ func (s *storage) deployFiles(ctx context.Context) error {
 	return nil
 }
 
+// uploadFile uploads the file to MinIO and sets the url the init container downloads it from and its checksum
+func (s *storage) uploadFile(ctx context.Context, file *k8s.File) error {
+	srcFile, err := os.Open(file.Source)
+	if err != nil {
+		return ErrFailedToOpenFile.Wrap(err)
+	}
+	defer srcFile.Close()
+
+	var (
+		hash = sha256.New()
+		key  = s.fileObjectKey(file)
+	)
+	if err := s.instance.MinioClient.Push(ctx, io.TeeReader(srcFile, hash), key, FilesBucketName); err != nil {
+		return ErrUploadingFile.WithParams(file.Source).Wrap(err)
+	}
+	// the file is downloaded through the minio service with the credentials of minio read from the secret of the instance,
+	// a presigned URL would expire while the URL stays in the spec of the pods
+	url, err := s.instance.MinioClient.GetInternalURL(key, FilesBucketName)
+	if err != nil {
+		return ErrUploadingFile.WithParams(file.Source).Wrap(err)
+	}
+	minioConfig, err := s.instance.MinioClient.GetConfigs(ctx)
+	if err != nil {
+		return ErrUploadingFile.WithParams(file.Source).Wrap(err)
+	}
+	file.URL = url
+	file.Signed = true
+	s.fileCredentials = minioConfig.AccessKeyID + ":" + minioConfig.SecretAccessKey
+	file.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	s.instance.Logger.WithFields(logrus.Fields{
+		"instance": s.instance.name,
+		"file":     file.Dest,
+		"key":      key,
+		"checksum": file.Checksum,
+	}).Debug("uploaded file to minio")
+	return nil
+}
+
+// fileObjectKey returns the key of the file in the MinIO bucket
+func (s *storage) fileObjectKey(file *k8s.File) string {
+	return fmt.Sprintf("%s%s/%s", UploadedFilesPrefix(s.instance.Scope), s.instance.name, strings.TrimPrefix(file.Dest, "/"))
+}
+
+// UploadedFilesPrefix returns the prefix of the large files uploaded to the FilesBucketName by the instances of the scope
+func UploadedFilesPrefix(scope string) string {
+	return scope + "/"
+}
+
 // destroyFiles destroys the files for the instance
 func (s *storage) destroyFiles(ctx context.Context) error {
 	if err := s.instance.K8sClient.DeleteConfigMap(ctx, s.instance.name); err != nil {
 		return ErrFailedToDeleteConfigMap.Wrap(err)
 	}
-
 	s.instance.Logger.WithField("configmap", s.instance.name).Debug("destroyed configmap")
+
+	for _, file := range s.files {
+		if !file.IsRemote() {
+			continue
+		}
+		if err := s.instance.MinioClient.Delete(ctx, s.fileObjectKey(file), FilesBucketName); err != nil {
+			return ErrDeletingUploadedFile.WithParams(file.Dest).Wrap(err)
+		}
+		file.URL, file.Signed, file.Checksum = "", false, ""
+	}
 	return nil
 }
 
 func (s *storage) readFileFromImage(ctx context.Context, filePath string) ([]byte, error) {
+	var output string
+	err := s.withImageInstance(ctx, func(ti *Instance) error {
+		var err error
+		output, err = ti.execution.ExecuteCommand(ctx, "cat", filePath)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(output), nil
+}
+
+// withImageInstance runs fn against a temporary instance started from the image of the instance
+func (s *storage) withImageInstance(ctx context.Context, fn func(ti *Instance) error) error {
 	// Another way to implement this is to download all the layers of the image and then
-	// extract the file from them, but it seems hacky and will run on the user's machine.
-	// Therefore, we will use the tmp instance to get the file from the image
+	// extract the files from them, but it seems hacky and will run on the user's machine.
+	// Therefore, we will use the tmp instance to get the files from the image
 
 	tmpName, err := names.NewRandomK8("tmp-dl")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	ti, err := New(tmpName, s.instance.SystemDependencies)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if err := ti.build.SetImage(ctx, s.instance.build.ImageName()); err != nil {
-		return nil, err
+		return err
 	}
 
 	if err := ti.build.SetStartCommand("sleep", "infinity"); err != nil {
-		return nil, err
+		return err
 	}
 
 	if err := ti.build.Commit(ctx); err != nil {
-		return nil, err
+		return err
 	}
 
 	if err := ti.execution.Start(ctx); err != nil {
-		return nil, err
+		return err
 	}
 	defer func() {
 		if err := ti.execution.Destroy(ctx); err != nil {
@@ -474,11 +635,7 @@ func (s *storage) readFileFromImage(ctx context.Context, filePath string) ([]byt
 		}
 	}()
 
-	output, err := ti.execution.ExecuteCommand(ctx, "cat", filePath)
-	if err != nil {
-		return nil, err
-	}
-	return []byte(output), nil
+	return fn(ti)
 }
 
 func (s *storage) clone() *storage {
@@ -502,9 +659,28 @@ func (s *storage) clone() *storage {
 		}
 	}
 
+	// the shared volumes belong to the scope, so the clone mounts the same ones
+	sharedVolumesCopy := make([]*sharedVolumeMount, len(s.sharedVolumes))
+	for i, m := range s.sharedVolumes {
+		mountCopy := *m
+		sharedVolumesCopy[i] = &mountCopy
+	}
+
+	var secretFileDataCopy map[string][]byte
+	if s.secretFileData != nil {
+		secretFileDataCopy = make(map[string][]byte, len(s.secretFileData))
+		for dest, data := range s.secretFileData {
+			secretFileDataCopy[dest] = data
+		}
+	}
+
 	return &storage{
-		instance: nil,
-		volumes:  volumesCopy,
-		files:    filesCopy,
+		instance:        nil,
+		volumes:         volumesCopy,
+		files:           filesCopy,
+		sharedVolumes:   sharedVolumesCopy,
+		secretFileData:  secretFileDataCopy,
+		volumeSnapshot:  s.volumeSnapshot,
+		fileCredentials: s.fileCredentials,
 	}
 }
@@ -0,0 +1,48 @@
+package instance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/knuu/pkg/minio"
+)
+
+func writeTestFile(t *testing.T, name string, size int) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+	return path
+}
+
+func TestAddLargeFileCommitted(t *testing.T) {
+	large := writeTestFile(t, "snapshot.tar", 2*maxTotalFilesBytes)
+	medium := writeTestFile(t, "genesis.json", 700*1024)
+	small := writeTestFile(t, "config.toml", 1024)
+
+	t.Run("without minio", func(t *testing.T) {
+		i := newCommittedTestInstance(t, newTestSystemDependencies(t), "no-minio")
+
+		assert.ErrorIs(t, i.Storage().AddFile(large, "/data/snapshot.tar", "0:0"), ErrFileTooLargeCommitted)
+		require.NoError(t, i.Storage().AddFile(medium, "/config/genesis.json", "0:0"))
+		assert.ErrorIs(t, i.Storage().AddFile(medium, "/config/genesis-2.json", "0:0"), ErrTotalFilesSizeTooLarge)
+	})
+
+	t.Run("with minio", func(t *testing.T) {
+		sysDeps := newTestSystemDependencies(t)
+		sysDeps.MinioClient = &minio.Minio{}
+		i := newCommittedTestInstance(t, sysDeps, "minio")
+
+		require.NoError(t, i.Storage().AddFile(large, "/data/snapshot.tar", "0:0"))
+		require.NoError(t, i.Storage().AddFile(medium, "/config/genesis.json", "0:0"))
+		require.NoError(t, i.Storage().AddFile(medium, "/config/genesis-2.json", "0:0"))
+		require.NoError(t, i.Storage().AddFile(small, "/config/config.toml", "0:0"))
+		assert.Len(t, i.storage.files, 4)
+
+		assert.True(t, i.storage.isLargeFile(2*maxTotalFilesBytes))
+		assert.True(t, i.storage.isLargeFile(700*1024))
+		assert.False(t, i.storage.isLargeFile(1024))
+	})
+}
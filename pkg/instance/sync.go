@@ -0,0 +1,271 @@
+package instance
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// syncExtractCommand extracts the tar archive from stdin at the root of the container
+var syncExtractCommand = []string{"tar", "-xf", "-", "-C", "/"}
+
+// SyncOption configures the synchronization of local files into a started instance
+type SyncOption func(*syncOptions)
+
+type syncOptions struct {
+	watchInterval time.Duration
+}
+
+// WithWatch keeps synchronizing the local changes at the given interval, until the context is done
+// or the instance is not started anymore. The files deleted locally are not deleted in the instance.
+func WithWatch(interval time.Duration) SyncOption {
+	return func(o *syncOptions) {
+		o.watchInterval = interval
+	}
+}
+
+// syncEntry is a local file or directory and its path in the container
+type syncEntry struct {
+	src     string
+	dest    string
+	info    os.FileInfo
+	modTime time.Time
+	size    int64
+}
+
+// SyncFile copies the local file src to the absolute path dest in the container of a started instance,
+// in all its replicas, with the given owner (user:group ids) and the permissions of the local file.
+// The file is streamed as a tar archive over exec, so the container must have a tar binary.
+// This function can only be called in the state 'Started'
+func (s *storage) SyncFile(ctx context.Context, src, dest, chown string, options ...SyncOption) error {
+	if err := s.validateSyncArgs(src, dest, chown); err != nil {
+		return err
+	}
+	if info, err := os.Stat(src); err != nil || info.IsDir() {
+		return ErrSrcDoesNotExistOrIsDirectory.WithParams(src)
+	}
+	return s.sync(ctx, src, dest, chown, options)
+}
+
+// SyncFolder copies the local folder src and its content to the absolute path dest in the container of a started instance,
+// in all its replicas, with the given owner (user:group ids) and the permissions of the local files.
+// The files are streamed as a tar archive over exec, so the container must have a tar binary.
+// This function can only be called in the state 'Started'
+func (s *storage) SyncFolder(ctx context.Context, src, dest, chown string, options ...SyncOption) error {
+	if err := s.validateSyncArgs(src, dest, chown); err != nil {
+		return err
+	}
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return ErrSrcDoesNotExistOrIsNotDirectory.WithParams(src)
+	}
+	return s.sync(ctx, src, dest, chown, options)
+}
+
+func (s *storage) validateSyncArgs(src, dest, chown string) error {
+	if !s.instance.IsInState(StateStarted) {
+		return ErrSyncingFilesNotAllowed.WithParams(s.instance.state.String())
+	}
+	if err := s.validateFileArgs(src, dest, chown); err != nil {
+		return err
+	}
+	if !path.IsAbs(dest) {
+		return ErrSyncDestMustBeAbsolute.WithParams(dest)
+	}
+	return nil
+}
+
+func (s *storage) sync(ctx context.Context, src, dest, chown string, options []SyncOption) error {
+	var opts syncOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	entries, err := collectSyncEntries(src, dest)
+	if err != nil {
+		return ErrSyncingFiles.WithParams(src, s.instance.name).Wrap(err)
+	}
+	if err := s.pushSyncEntries(ctx, entries, chown); err != nil {
+		return ErrSyncingFiles.WithParams(src, s.instance.name).Wrap(err)
+	}
+	s.instance.Logger.WithFields(logrus.Fields{
+		"instance": s.instance.name,
+		"src":      src,
+		"dest":     dest,
+		"files":    len(entries),
+	}).Debug("synced files into instance")
+
+	if opts.watchInterval > 0 {
+		go s.watchSync(ctx, src, dest, chown, opts.watchInterval, entries)
+	}
+	return nil
+}
+
+// watchSync synchronizes the new and modified local files at every interval
+func (s *storage) watchSync(ctx context.Context, src, dest, chown string, interval time.Duration, synced []syncEntry) {
+	known := make(map[string]syncEntry, len(synced))
+	for _, e := range synced {
+		known[e.src] = e
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !s.instance.IsInState(StateStarted) {
+			return
+		}
+
+		entries, err := collectSyncEntries(src, dest)
+		if err != nil {
+			s.instance.Logger.WithField("instance", s.instance.name).Errorf("cannot watch %s: %v", src, err)
+			continue
+		}
+
+		var changed []syncEntry
+		for _, e := range entries {
+			prev, ok := known[e.src]
+			if !ok || !prev.modTime.Equal(e.modTime) || prev.size != e.size || prev.info.Mode() != e.info.Mode() {
+				changed = append(changed, e)
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+
+		if err := s.pushSyncEntries(ctx, changed, chown); err != nil {
+			s.instance.Logger.WithField("instance", s.instance.name).Errorf("cannot sync the changes of %s: %v", src, err)
+			continue
+		}
+		for _, e := range changed {
+			known[e.src] = e
+		}
+		s.instance.Logger.WithFields(logrus.Fields{
+			"instance": s.instance.name,
+			"src":      src,
+			"files":    len(changed),
+		}).Debug("synced changed files into instance")
+	}
+}
+
+// pushSyncEntries extracts the entries into the container of every pod of the instance
+func (s *storage) pushSyncEntries(ctx context.Context, entries []syncEntry, chown string) error {
+	uid, gid, err := parseSyncOwner(chown)
+	if err != nil {
+		return err
+	}
+
+	pods, err := s.instance.execution.workloadPods(ctx)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		archive := createSyncArchive(entries, uid, gid)
+		_, err := s.instance.K8sClient.RunCommandInPodWithStdin(ctx, pod.Name, s.instance.name, syncExtractCommand, archive)
+		archive.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectSyncEntries returns the file src, or the folder src and all its content, with their path under dest
+func collectSyncEntries(src, dest string) ([]syncEntry, error) {
+	var entries []syncEntry
+	err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			// symlinks and special files are not synced
+			return nil
+		}
+		relPath, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, syncEntry{
+			src:     p,
+			dest:    path.Join(dest, filepath.ToSlash(relPath)),
+			info:    info,
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// parseSyncOwner returns the user and group ids of chown (user:group ids)
+func parseSyncOwner(chown string) (uid, gid int, err error) {
+	uidStr, gidStr, _ := strings.Cut(chown, ":")
+	uid, err = strconv.Atoi(uidStr)
+	if err != nil {
+		return 0, 0, ErrFailedToConvertToInt64.WithParams(uidStr).Wrap(err)
+	}
+	gid, err = strconv.Atoi(gidStr)
+	if err != nil {
+		return 0, 0, ErrFailedToConvertToInt64.WithParams(gidStr).Wrap(err)
+	}
+	return uid, gid, nil
+}
+
+// createSyncArchive streams a tar archive of the entries, owned by the given user and group ids
+// The archive is written by a goroutine while it is read, so the synced files are not held in memory
+// The reader must be closed, which stops the goroutine if the archive is not read until the end
+func createSyncArchive(entries []syncEntry, uid, gid int) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeSyncArchive(pw, entries, uid, gid))
+	}()
+	return pr
+}
+
+// writeSyncArchive writes the tar archive of the entries into w
+func writeSyncArchive(w io.Writer, entries []syncEntry, uid, gid int) error {
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		hdr, err := tar.FileInfoHeader(e.info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = strings.TrimPrefix(e.dest, "/")
+		if e.info.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uid, hdr.Gid = uid, gid
+		hdr.Uname, hdr.Gname = "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if e.info.IsDir() {
+			continue
+		}
+		if err := copyFileToTar(tw, e.src); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func copyFileToTar(tw *tar.Writer, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
@@ -0,0 +1,136 @@
+package instance
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+// execRecorder records the commands run in the pods instead of executing them
+type execRecorder struct {
+	k8s.KubeManager
+	mu     sync.Mutex
+	pods   []string
+	stdins [][]byte
+}
+
+func (r *execRecorder) RunCommandInPodWithStdin(_ context.Context, podName, _ string, _ []string, stdin io.Reader) (string, error) {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pods = append(r.pods, podName)
+	r.stdins = append(r.stdins, data)
+	return "", nil
+}
+
+// addTestReplicaSetPods creates pods selected by the replica set of a started instance
+func addTestReplicaSetPods(t *testing.T, i *Instance, names ...string) {
+	ctx := context.Background()
+	clientset := i.K8sClient.Clientset()
+	rs, err := clientset.AppsV1().ReplicaSets(i.K8sClient.Namespace()).Get(ctx, i.execution.workloadName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, name := range names {
+		_, err := clientset.CoreV1().Pods(i.K8sClient.Namespace()).Create(ctx, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: rs.Spec.Selector.MatchLabels},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+}
+
+// syncArchiveBytes reads the whole archive streamed by createSyncArchive
+func syncArchiveBytes(t *testing.T, entries []syncEntry, uid, gid int) []byte {
+	archive := createSyncArchive(entries, uid, gid)
+	defer archive.Close()
+	data, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	return data
+}
+
+func readSyncArchive(t *testing.T, archive []byte) map[string]*tar.Header {
+	headers := map[string]*tar.Header{}
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return headers
+		}
+		require.NoError(t, err)
+		headers[hdr.Name] = hdr
+	}
+}
+
+func TestCreateSyncArchive(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "run.sh"), []byte("#!/bin/sh"), 0o755))
+
+	entries, err := collectSyncEntries(src, "/app")
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	uid, gid, err := parseSyncOwner("1000:2000")
+	require.NoError(t, err)
+
+	headers := readSyncArchive(t, syncArchiveBytes(t, entries, uid, gid))
+	require.Len(t, headers, 4)
+	assert.Contains(t, headers, "app/")
+	assert.Contains(t, headers, "app/sub/")
+	require.Contains(t, headers, "app/a.txt")
+	require.Contains(t, headers, "app/sub/run.sh")
+
+	assert.Equal(t, int64(0o644), headers["app/a.txt"].Mode&0o777)
+	assert.Equal(t, int64(0o755), headers["app/sub/run.sh"].Mode&0o777)
+	for name, hdr := range headers {
+		assert.Equal(t, 1000, hdr.Uid, name)
+		assert.Equal(t, 2000, hdr.Gid, name)
+	}
+
+	_, _, err = parseSyncOwner("user:2000")
+	assert.ErrorIs(t, err, ErrFailedToConvertToInt64)
+}
+
+func TestSyncFile(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	recorder := &execRecorder{KubeManager: sysDeps.K8sClient}
+	sysDeps.K8sClient = recorder
+
+	src := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(src, []byte("key = 1"), 0o600))
+
+	committed := newCommittedTestInstance(t, sysDeps, "committed")
+	err := committed.Storage().SyncFile(ctx, src, "/etc/config.toml", "0:0")
+	assert.ErrorIs(t, err, ErrSyncingFilesNotAllowed)
+
+	i := newStartedTestInstance(t, sysDeps, "started")
+	err = i.Storage().SyncFile(ctx, src, "etc/config.toml", "0:0")
+	assert.ErrorIs(t, err, ErrSyncDestMustBeAbsolute)
+	err = i.Storage().SyncFile(ctx, filepath.Dir(src), "/etc/config.toml", "0:0")
+	assert.ErrorIs(t, err, ErrSrcDoesNotExistOrIsDirectory)
+
+	addTestReplicaSetPods(t, i, "started-0", "started-1")
+
+	require.NoError(t, i.Storage().SyncFile(ctx, src, "/etc/config.toml", "0:0"))
+	assert.ElementsMatch(t, []string{"started-0", "started-1"}, recorder.pods)
+	for _, stdin := range recorder.stdins {
+		headers := readSyncArchive(t, stdin)
+		require.Contains(t, headers, "etc/config.toml")
+		assert.Equal(t, int64(0o600), headers["etc/config.toml"].Mode&0o777)
+	}
+}
@@ -23,3 +23,13 @@ func (s InstanceType) String() string {
 	return "Unknown"
 
 }
+
+// instanceTypeFromString returns the type represented by the given string
+func instanceTypeFromString(s string) InstanceType {
+	for _, t := range []InstanceType{BasicInstance, TimeoutHandlerInstance} {
+		if t.String() == s {
+			return t
+		}
+	}
+	return UnknownInstance
+}
@@ -0,0 +1,95 @@
+package instance
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+// VolumeOption configures a volume added by AddVolume or AddVolumeWithOwner
+type VolumeOption func(*k8s.Volume)
+
+// WithStorageClass sets the StorageClass of the volume, e.g. a fast local SSD class for validators
+// The default StorageClass of the cluster is used otherwise
+func WithStorageClass(storageClass string) VolumeOption {
+	return func(v *k8s.Volume) {
+		v.StorageClass = storageClass
+	}
+}
+
+// WithAccessMode sets the access mode of the volume, ReadWriteOnce by default
+func WithAccessMode(accessMode v1.PersistentVolumeAccessMode) VolumeOption {
+	return func(v *k8s.Volume) {
+		v.AccessMode = accessMode
+	}
+}
+
+// WithVolumeMode sets the volume mode of the volume, Filesystem by default
+// A Block volume is attached as a raw device at the path of the volume, so it cannot be combined with files
+func WithVolumeMode(volumeMode v1.PersistentVolumeMode) VolumeOption {
+	return func(v *k8s.Volume) {
+		v.VolumeMode = volumeMode
+	}
+}
+
+// WithEmptyDir backs the volume by an emptyDir limited to its size instead of a PersistentVolumeClaim
+// It suits scratch data, which is lost when the instance is stopped
+func WithEmptyDir() VolumeOption {
+	return func(v *k8s.Volume) {
+		v.EmptyDir = true
+	}
+}
+
+// WithTmpfs backs the volume by a memory-backed emptyDir limited to its size
+// The data written to it counts against the memory limit of the instance
+func WithTmpfs() VolumeOption {
+	return func(v *k8s.Volume) {
+		v.EmptyDir = true
+		v.Medium = v1.StorageMediumMemory
+	}
+}
+
+// isEphemeral returns true if the volumes of the instance are emptyDirs, which have no PersistentVolumeClaim
+func (s *storage) isEphemeral() bool {
+	return len(s.volumes) != 0 && s.volumes[0].EmptyDir
+}
+
+// hasSingleNodeClaim returns true if the volumes of the instance are backed by a PersistentVolumeClaim
+// that only the pods of one node can mount, which the replicas of a ReplicaSet cannot share
+func (s *storage) hasSingleNodeClaim() bool {
+	if len(s.volumes) == 0 || s.isEphemeral() {
+		return false
+	}
+	switch s.volumes[0].AccessMode {
+	case v1.ReadWriteMany, v1.ReadOnlyMany:
+		return false
+	}
+	return true
+}
+
+// attachVolumeClaim restores the access mode of the volumes of an attached instance from their PersistentVolumeClaim
+// The pod spec only refers to the claim by name
+func (s *storage) attachVolumeClaim(ctx context.Context) error {
+	if len(s.volumes) == 0 || s.isEphemeral() || s.instance.execution.WorkloadType() == StatefulSetWorkload {
+		return nil
+	}
+	pvc, err := s.instance.K8sClient.GetPersistentVolumeClaim(ctx, s.instance.name)
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return ErrGettingPersistentVolumeClaim.WithParams(s.instance.name).Wrap(err)
+	}
+	if len(pvc.Spec.AccessModes) != 0 {
+		s.volumes[0].AccessMode = pvc.Spec.AccessModes[0]
+	}
+	s.instance.Logger.WithFields(logrus.Fields{
+		"instance":    s.instance.name,
+		"access_mode": s.volumes[0].AccessMode,
+	}).Debug("attached persistent volume claim")
+	return nil
+}
@@ -0,0 +1,82 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddVolumeWithOptions(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	clientset := sysDeps.K8sClient.Clientset()
+	namespace := sysDeps.K8sClient.Namespace()
+
+	validator := newCommittedTestInstance(t, sysDeps, "validator")
+	require.NoError(t, validator.Storage().AddVolume("/home/celestia", resource.MustParse("10Gi"),
+		WithStorageClass("local-ssd"), WithAccessMode(v1.ReadWriteOncePod)))
+	require.NoError(t, validator.Execution().StartAsync(ctx))
+
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, "validator", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, pvc.Spec.StorageClassName)
+	assert.Equal(t, "local-ssd", *pvc.Spec.StorageClassName)
+	assert.Equal(t, []v1.PersistentVolumeAccessMode{v1.ReadWriteOncePod}, pvc.Spec.AccessModes)
+
+	scratch := newCommittedTestInstance(t, sysDeps, "scratch")
+	require.NoError(t, scratch.Storage().AddVolume("/scratch", resource.MustParse("256Mi"), WithTmpfs()))
+	require.NoError(t, scratch.Execution().StartAsync(ctx))
+
+	// the tmpfs volume has no PersistentVolumeClaim
+	_, err = clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, "scratch", metav1.GetOptions{})
+	assert.Error(t, err)
+	rs, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, scratch.execution.workloadName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, rs.Spec.Template.Spec.Volumes, 1)
+	emptyDir := rs.Spec.Template.Spec.Volumes[0].EmptyDir
+	require.NotNil(t, emptyDir)
+	assert.Equal(t, v1.StorageMediumMemory, emptyDir.Medium)
+	assert.Equal(t, resource.MustParse("256Mi"), *emptyDir.SizeLimit)
+
+	require.NoError(t, scratch.Execution().Stop(ctx))
+	assert.ErrorIs(t, scratch.Storage().Snapshot(ctx, "scratch"), ErrSnapshotRequiresVolume)
+}
+
+func TestReplicasShareVolume(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+
+	validator := newCommittedTestInstance(t, sysDeps, "validator")
+	require.NoError(t, validator.Storage().AddVolume("/home/celestia", resource.MustParse("10Gi")))
+	assert.ErrorIs(t, validator.Resources().SetReplicas(3), ErrReplicasShareVolume)
+
+	// the volume added after the replicas is rejected at start
+	bridge := newCommittedTestInstance(t, sysDeps, "bridge")
+	require.NoError(t, bridge.Resources().SetReplicas(3))
+	require.NoError(t, bridge.Storage().AddVolume("/home/celestia", resource.MustParse("10Gi")))
+	assert.ErrorIs(t, bridge.Execution().StartAsync(ctx), ErrReplicasShareVolume)
+	assert.True(t, bridge.IsInState(StateCommitted))
+
+	require.NoError(t, bridge.Execution().SetWorkloadType(StatefulSetWorkload))
+	require.NoError(t, bridge.Execution().StartAsync(ctx))
+
+	shared := newCommittedTestInstance(t, sysDeps, "shared")
+	require.NoError(t, shared.Storage().AddVolume("/data", resource.MustParse("1Gi"), WithAccessMode(v1.ReadWriteMany)))
+	require.NoError(t, shared.Resources().SetReplicas(3))
+
+	scratch := newCommittedTestInstance(t, sysDeps, "scratch")
+	require.NoError(t, scratch.Storage().AddVolume("/scratch", resource.MustParse("256Mi"), WithEmptyDir()))
+	require.NoError(t, scratch.Resources().SetReplicas(3))
+	require.NoError(t, scratch.Execution().StartAsync(ctx))
+
+	light := newCommittedTestInstance(t, sysDeps, "light")
+	require.NoError(t, light.Storage().AddVolume("/home/celestia", resource.MustParse("1Gi")))
+	require.NoError(t, light.Execution().StartAsync(ctx))
+	assert.ErrorIs(t, light.Execution().Scale(ctx, 2), ErrReplicasShareVolume)
+	assert.Equal(t, int32(1), light.Resources().Replicas())
+}
@@ -0,0 +1,45 @@
+package instance
+
+// WorkloadType represents the kind of kubernetes workload the instance is deployed as
+type WorkloadType int
+
+// Possible workload types of the instance
+const (
+	// ReplicaSetWorkload deploys the instance as a ReplicaSet (default)
+	ReplicaSetWorkload WorkloadType = iota
+	// StatefulSetWorkload deploys the instance as a StatefulSet.
+	// The pods get stable names (<instance>-<ordinal>), are started in order
+	// and every replica gets its own volume that survives restarts of the instance.
+	StatefulSetWorkload
+	// JobWorkload deploys the instance as a Job that runs to completion.
+	// Use Execution().WaitForCompletion() to retrieve the exit code, the termination message and the logs.
+	// Sidecars of a Job instance must exit on their own, otherwise the Job never completes.
+	JobWorkload
+)
+
+// JobResult represents the result of an instance deployed with the JobWorkload type
+type JobResult struct {
+	// Succeeded is true if all the replicas of the Job completed successfully
+	Succeeded bool
+	// ExitCode is the exit code of the instance container
+	ExitCode int32
+	// Message is the termination message of the instance container
+	Message string
+	// Logs are the final logs of the instance container
+	Logs string
+	// PodName is the name of the pod the result is taken from
+	PodName string
+}
+
+// String returns the string representation of the workload type
+func (w WorkloadType) String() string {
+	switch w {
+	case ReplicaSetWorkload:
+		return "ReplicaSet"
+	case StatefulSetWorkload:
+		return "StatefulSet"
+	case JobWorkload:
+		return "Job"
+	}
+	return "Unknown"
+}
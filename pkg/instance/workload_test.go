@@ -0,0 +1,75 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   WorkloadType
+		want string
+	}{
+		{
+			name: "ReplicaSetWorkload",
+			in:   ReplicaSetWorkload,
+			want: "ReplicaSet",
+		},
+		{
+			name: "StatefulSetWorkload",
+			in:   StatefulSetWorkload,
+			want: "StatefulSet",
+		},
+		{
+			name: "JobWorkload",
+			in:   JobWorkload,
+			want: "Job",
+		},
+		{
+			name: "42",
+			in:   42,
+			want: "Unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in.String()
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatefulSetHeadlessService(t *testing.T) {
+	ctx := context.Background()
+	sysDeps := newTestSystemDependencies(t)
+	clientset := sysDeps.K8sClient.Clientset()
+	namespace := sysDeps.K8sClient.Namespace()
+
+	// the instance has no ports, so it has no regular service
+	validator := newCommittedTestInstance(t, sysDeps, "validator")
+	require.NoError(t, validator.Execution().SetWorkloadType(StatefulSetWorkload))
+	require.NoError(t, validator.Execution().StartAsync(ctx))
+
+	svc, err := clientset.CoreV1().Services(namespace).Get(ctx, "validator-headless", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1.ClusterIPNone, svc.Spec.ClusterIP)
+	assert.Equal(t, validator.Execution().Labels(), svc.Spec.Selector)
+
+	ss, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, "validator", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "validator-headless", ss.Spec.ServiceName)
+
+	require.NoError(t, validator.Execution().Destroy(ctx))
+	_, err = clientset.CoreV1().Services(namespace).Get(ctx, "validator-headless", metav1.GetOptions{})
+	assert.Error(t, err)
+}
@@ -23,6 +23,9 @@ var (
 	ErrCreatingNetworkPolicy              = errors.New("ErrorCreatingNetworkPolicy", "error creating network policy %s")
 	ErrDeletingNetworkPolicy              = errors.New("ErrorDeletingNetworkPolicy", "error deleting network policy %s")
 	ErrGettingNetworkPolicy               = errors.New("ErrorGettingNetworkPolicy", "error getting network policy %s")
+	ErrUpdatingNetworkPolicy              = errors.New("ErrorUpdatingNetworkPolicy", "error updating network policy %s")
+	ErrPatchingNetworkPolicy              = errors.New("ErrorPatchingNetworkPolicy", "error patching network policy %s")
+	ErrNoBlockedValuesForPartition        = errors.New("NoBlockedValuesForPartition", "no blocked values given for partition network policy %s")
 	ErrGettingPod                         = errors.New("ErrorGettingPod", "failed to get pod %s")
 	ErrPreparingPod                       = errors.New("ErrorPreparingPod", "error preparing pod")
 	ErrCreatingPod                        = errors.New("ErrorCreatingPod", "failed to create pod")
@@ -32,6 +35,7 @@ var (
 	ErrCreatingExecutor                   = errors.New("ErrorCreatingExecutor", "failed to create Executor")
 	ErrExecutingCommand                   = errors.New("ErrorExecutingCommand", "failed to execute command, stdout: `%v`, stderr: `%v`")
 	ErrCommandExecution                   = errors.New("ErrorCommandExecution", "error while executing command")
+	ErrStreamingCommand                   = errors.New("ErrorStreamingCommand", "failed to stream command, stderr: `%v`")
 	ErrDeletingPodFailed                  = errors.New("ErrorDeletingPodFailed", "failed to delete pod %s")
 	ErrParsingMemoryRequest               = errors.New("ErrorParsingMemoryRequest", "failed to parse memory request quantity '%s'")
 	ErrParsingMemoryLimit                 = errors.New("ErrorParsingMemoryLimit", "failed to parse memory limit quantity '%s'")
@@ -112,14 +116,21 @@ var (
 	ErrInvalidContainerName               = errors.New("InvalidContainerName", "invalid container name %s: %v")
 	ErrInvalidNetworkPolicyName           = errors.New("InvalidNetworkPolicyName", "invalid network policy name %s: %v")
 	ErrInvalidPodName                     = errors.New("InvalidPodName", "invalid pod name %s: %v")
+	ErrInvalidNetworkPolicyProtocol       = errors.New("InvalidNetworkPolicyProtocol", "invalid network policy protocol %s")
+	ErrInvalidNetworkPolicyPortRange      = errors.New("InvalidNetworkPolicyPortRange", "invalid network policy port range %d-%d")
+	ErrInvalidCIDR                        = errors.New("InvalidCIDR", "invalid CIDR %s")
+	ErrNetworkPolicyPeerCIDRWithSelector  = errors.New("NetworkPolicyPeerCIDRWithSelector", "network policy peer with CIDR %s can not have pod or namespace selectors")
 	ErrEmptyCommand                       = errors.New("EmptyCommand", "command cannot be empty")
 	ErrInvalidPort                        = errors.New("InvalidPort", "port number %d is out of valid range (1-65535)")
 	ErrInvalidPodAnnotationKey            = errors.New("InvalidPodAnnotationKey", "invalid annotation key %s: %v")
 	ErrAnnotationValueTooLarge            = errors.New("AnnotationValueTooLarge", "annotation value for key %s exceeds maximum size. %d must be less than 253 characters")
 	ErrContainerImageEmpty                = errors.New("ContainerImageEmpty", "container image cannot be empty for container %s")
 	ErrVolumePathEmpty                    = errors.New("VolumePathEmpty", "volume path cannot be empty")
+	ErrInvalidSharedVolumeName            = errors.New("InvalidSharedVolumeName", "invalid shared volume name %s: %v")
 	ErrVolumeSizeZero                     = errors.New("VolumeSizeZero", "volume size must be greater than zero")
 	ErrFileSourceDestEmpty                = errors.New("FileSourceDestEmpty", "file source and destination cannot be empty")
+	ErrInvalidFileURL                     = errors.New("InvalidFileURL", "invalid file url: %s")
+	ErrInvalidFileChecksum                = errors.New("InvalidFileChecksum", "invalid file checksum, expected a sha256 hex digest: %s")
 	ErrInvalidPVCName                     = errors.New("InvalidPVCName", "invalid PVC name %s: %v")
 	ErrPVCSizeZero                        = errors.New("PVCSizeZero", "PVC size must be greater than zero")
 	ErrInvalidReplicaSetName              = errors.New("InvalidReplicaSetName", "invalid ReplicaSet name %s: %v")
@@ -147,4 +158,47 @@ var (
 	ErrNoPortsFoundForService             = errors.New("NoPortsFoundForService", "no ports found for service %s")
 	ErrNoValidNodeIPFound                 = errors.New("NoValidNodeIPFound", "no valid node IP found for service %s")
 	ErrInvalidClusterDomain               = errors.New("InvalidClusterDomain", "invalid cluster domain `%s`")
+	ErrScalingReplicaSet                  = errors.New("ScalingReplicaSet", "failed to scale ReplicaSet %s")
+	ErrGettingPodLogs                     = errors.New("GettingPodLogs", "failed to get logs of pod %s")
+	ErrCreatingStatefulSet                = errors.New("CreatingStatefulSet", "failed to create StatefulSet")
+	ErrGettingStatefulSet                 = errors.New("GettingStatefulSet", "failed to get StatefulSet %s")
+	ErrDeletingStatefulSet                = errors.New("DeletingStatefulSet", "failed to delete StatefulSet %s")
+	ErrCheckingStatefulSetExists          = errors.New("CheckingStatefulSetExists", "failed to check if StatefulSet %s exists")
+	ErrListingPodsForStatefulSet          = errors.New("ListingPodsForStatefulSet", "failed to list pods for StatefulSet %s")
+	ErrScalingStatefulSet                 = errors.New("ScalingStatefulSet", "failed to scale StatefulSet %s")
+	ErrInvalidStatefulSetName             = errors.New("InvalidStatefulSetName", "invalid StatefulSet name %s: %v")
+	ErrStatefulSetReplicasNegative        = errors.New("StatefulSetReplicasNegative", "number of replicas cannot be negative: %d")
+	ErrDeletingPersistentVolumeClaims     = errors.New("DeletingPersistentVolumeClaims", "error deleting PersistentVolumeClaims with selector %s")
+	ErrCreatingJob                        = errors.New("CreatingJob", "failed to create Job %s")
+	ErrGettingJob                         = errors.New("GettingJob", "failed to get Job %s")
+	ErrDeletingJob                        = errors.New("DeletingJob", "failed to delete Job %s")
+	ErrWatchingJob                        = errors.New("WatchingJob", "failed to watch Job %s")
+	ErrWaitingForJobCompletion            = errors.New("WaitingForJobCompletion", "error waiting for Job %s to complete")
+	ErrListingPodsForJob                  = errors.New("ListingPodsForJob", "failed to list pods for Job %s")
+	ErrNoPodsForJob                       = errors.New("NoPodsForJob", "no pods found for Job %s")
+	ErrInvalidJobName                     = errors.New("InvalidJobName", "invalid Job name %s: %v")
+	ErrJobCompletionsNegative             = errors.New("JobCompletionsNegative", "number of completions cannot be negative: %d")
+	ErrJobBackoffLimitNegative            = errors.New("JobBackoffLimitNegative", "backoff limit cannot be negative: %d")
+	ErrListingReplicaSets                 = errors.New("ListingReplicaSets", "failed to list ReplicaSets with selector %s")
+	ErrListingStatefulSets                = errors.New("ListingStatefulSets", "failed to list StatefulSets with selector %s")
+	ErrListingJobs                        = errors.New("ListingJobs", "failed to list Jobs with selector %s")
+	ErrContainerNotFoundInPodSpec         = errors.New("ContainerNotFoundInPodSpec", "container %s not found in pod spec")
+	ErrGettingSecret                      = errors.New("ErrorGettingSecret", "error getting secret %s")
+	ErrCreatingSecret                     = errors.New("ErrorCreatingSecret", "error creating secret %s")
+	ErrUpdatingSecret                     = errors.New("ErrorUpdatingSecret", "error updating secret %s")
+	ErrDeletingSecret                     = errors.New("ErrorDeletingSecret", "error deleting secret %s")
+	ErrInvalidSecretName                  = errors.New("InvalidSecretName", "invalid secret name %s: %v")
+	ErrInvalidSecretKey                   = errors.New("InvalidSecretKey", "invalid secret key %s: %v")
+	ErrCreatingVolumeSnapshot             = errors.New("CreatingVolumeSnapshot", "failed to create VolumeSnapshot %s")
+	ErrGettingVolumeSnapshot              = errors.New("GettingVolumeSnapshot", "failed to get VolumeSnapshot %s")
+	ErrDeletingVolumeSnapshot             = errors.New("DeletingVolumeSnapshot", "failed to delete VolumeSnapshot %s")
+	ErrWaitingForVolumeSnapshot           = errors.New("WaitingForVolumeSnapshot", "error waiting for VolumeSnapshot %s to be ready")
+	ErrVolumeSnapshotFailed               = errors.New("VolumeSnapshotFailed", "VolumeSnapshot %s failed: %s")
+	ErrInvalidVolumeSnapshotName          = errors.New("InvalidVolumeSnapshotName", "invalid VolumeSnapshot name %s: %v")
+	ErrInvalidVolumeAccessMode            = errors.New("InvalidVolumeAccessMode", "invalid access mode %s of volume %s")
+	ErrInvalidVolumeMode                  = errors.New("InvalidVolumeMode", "invalid volume mode %s of volume %s")
+	ErrEmptyDirVolumeWithClaimOptions     = errors.New("EmptyDirVolumeWithClaimOptions", "the emptyDir volume %s cannot have a storage class, an access mode or a volume mode")
+	ErrVolumeMediumWithoutEmptyDir        = errors.New("VolumeMediumWithoutEmptyDir", "the medium of volume %s is only supported by emptyDir volumes")
+	ErrVolumesKindMismatch                = errors.New("VolumesKindMismatch", "the volumes of container %s must all be emptyDir or persistent volumes of the same volume mode")
+	ErrBlockVolumeWithFiles               = errors.New("BlockVolumeWithFiles", "the block volume %s cannot be combined with files")
 )
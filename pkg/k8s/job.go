@@ -0,0 +1,267 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/utils/ptr"
+)
+
+// jobNameLabel is the label kubernetes sets on the pods created by a Job
+const jobNameLabel = "job-name"
+
+type JobConfig struct {
+	Name         string            // Name of the Job
+	Namespace    string            // Namespace of the Job
+	Labels       map[string]string // Labels to apply to the Job and its pods
+	Completions  int32             // Completions is the number of pods that must terminate successfully
+	BackoffLimit int32             // BackoffLimit is the number of retries before the Job is marked as failed
+	PodConfig    PodConfig         // PodConfig represents the pod configuration
+}
+
+// CreateJob creates a new Job in the namespace that k8s is initialized with.
+// The pods of the Job are never restarted, a failed pod is retried with a new pod up to BackoffLimit times.
+func (c *Client) CreateJob(ctx context.Context, jobConfig JobConfig, init bool) (*batchv1.Job, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateJobConfig(jobConfig); err != nil {
+		return nil, err
+	}
+	jobConfig.Namespace = c.namespace
+	job := c.prepareJob(jobConfig, init)
+
+	createdJob, err := c.clientset.BatchV1().Jobs(c.namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return nil, ErrCreatingJob.WithParams(jobConfig.Name).Wrap(err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":      jobConfig.Name,
+		"namespace": c.namespace,
+	}).Debug("job created")
+	return createdJob, nil
+}
+
+// IsJobStarted returns true if a pod of the Job is ready or if the Job has already finished.
+func (c *Client) IsJobStarted(ctx context.Context, name string) (bool, error) {
+	job, err := c.getJob(ctx, name)
+	if err != nil {
+		return false, ErrGettingJob.WithParams(name).Wrap(err)
+	}
+
+	if IsJobFinished(job) {
+		return true, nil
+	}
+	return job.Status.Ready != nil && *job.Status.Ready > 0, nil
+}
+
+// WaitForJobCompletion waits until the given Job has completed, either successfully or not,
+// and returns the final state of the Job.
+// The API server closes watches routinely, in that case the watch is established again.
+func (c *Client) WaitForJobCompletion(ctx context.Context, name string) (*batchv1.Job, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, ErrWaitingForJobCompletion.WithParams(name).Wrap(err)
+		}
+
+		job, err := c.getJob(ctx, name)
+		if err != nil {
+			return nil, ErrGettingJob.WithParams(name).Wrap(err)
+		}
+		if IsJobFinished(job) {
+			return job, nil
+		}
+
+		job, err = c.watchJobCompletion(ctx, name, job.ResourceVersion)
+		if err != nil || job != nil {
+			return job, err
+		}
+		c.logger.WithField("job", name).Debug("watch of job closed, watching again")
+	}
+}
+
+// watchJobCompletion watches the given Job from the given resource version until it has completed
+// It returns a nil Job without error if the watch is closed before, e.g. by the API server
+func (c *Client) watchJobCompletion(ctx context.Context, name, resourceVersion string) (*batchv1.Job, error) {
+	watcher, err := c.clientset.BatchV1().Jobs(c.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fmt.Sprintf("metadata.name=%s", name),
+		ResourceVersion: resourceVersion,
+	})
+	if err != nil {
+		return nil, ErrWatchingJob.WithParams(name).Wrap(err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok || event.Type == watch.Error {
+				// e.g. the resource version is too old, the Job is fetched again
+				return nil, nil
+			}
+
+			j, ok := event.Object.(*batchv1.Job)
+			if !ok {
+				continue
+			}
+
+			if IsJobFinished(j) {
+				return j, nil
+			}
+		case <-ctx.Done():
+			return nil, ErrWaitingForJobCompletion.WithParams(name).Wrap(ctx.Err())
+		}
+	}
+}
+
+// GetPodsFromJob returns the pods created by the given Job sorted by their creation time.
+func (c *Client) GetPodsFromJob(ctx context.Context, name string) ([]v1.Pod, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	pods, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", jobNameLabel, name),
+	})
+	if err != nil {
+		return nil, ErrListingPodsForJob.WithParams(name).Wrap(err)
+	}
+
+	sortPods(pods.Items)
+	return pods.Items, nil
+}
+
+// GetFirstPodFromJob returns the first pod created by the given Job.
+func (c *Client) GetFirstPodFromJob(ctx context.Context, name string) (*v1.Pod, error) {
+	pods, err := c.GetPodsFromJob(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return nil, ErrNoPodsForJob.WithParams(name)
+	}
+
+	return &pods[0], nil
+}
+
+// DeleteJob deletes the given Job together with its pods.
+// If the Job does not exist, it returns nil.
+func (c *Client) DeleteJob(ctx context.Context, name string) error {
+	exists, err := c.JobExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	delOpts := metav1.DeleteOptions{
+		GracePeriodSeconds: ptr.To[int64](0),
+		PropagationPolicy:  ptr.To(metav1.DeletePropagationBackground),
+	}
+	if err := c.clientset.BatchV1().Jobs(c.namespace).Delete(ctx, name, delOpts); err != nil {
+		return ErrDeletingJob.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":      name,
+		"namespace": c.namespace,
+	}).Debug("job deleted")
+	return nil
+}
+
+// JobExists checks if a Job exists in the namespace that k8s is initialized with.
+func (c *Client) JobExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.getJob(ctx, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, ErrGettingJob.WithParams(name).Wrap(err)
+	}
+
+	return true, nil
+}
+
+// ListJobs returns the Jobs matching the given labels.
+func (c *Client) ListJobs(ctx context.Context, labels map[string]string) ([]batchv1.Job, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateLabels(labels); err != nil {
+		return nil, err
+	}
+
+	selector := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: labels})
+	jobList, err := c.clientset.BatchV1().Jobs(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, ErrListingJobs.WithParams(selector).Wrap(err)
+	}
+	return jobList.Items, nil
+}
+
+// IsJobFinished returns true if the Job has completed successfully or has failed.
+func IsJobFinished(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	return job.Status.Succeeded >= completions
+}
+
+func (c *Client) getJob(ctx context.Context, name string) (*batchv1.Job, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	return c.clientset.BatchV1().Jobs(c.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// prepareJob prepares a Job configuration.
+func (c *Client) prepareJob(jobConf JobConfig, init bool) *batchv1.Job {
+	podSpec := c.preparePodSpec(jobConf.PodConfig, init)
+	podSpec.RestartPolicy = v1.RestartPolicyNever
+
+	completions := jobConf.Completions
+	if completions == 0 {
+		completions = 1
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: jobConf.Namespace,
+			Name:      jobConf.Name,
+			Labels:    jobConf.Labels,
+		},
+		Spec: batchv1.JobSpec{
+			Completions:  &completions,
+			Parallelism:  &completions,
+			BackoffLimit: &jobConf.BackoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   jobConf.Namespace,
+					Labels:      jobConf.Labels,
+					Annotations: jobConf.PodConfig.Annotations,
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":      jobConf.Name,
+		"namespace": jobConf.Namespace,
+	}).Debug("prepared job")
+	return job
+}
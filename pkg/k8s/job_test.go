@@ -0,0 +1,353 @@
+package k8s_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+func (s *TestSuite) TestCreateJob() {
+	tests := []struct {
+		name        string
+		jobConfig   k8s.JobConfig
+		setupMock   func()
+		expectedErr error
+	}{
+		{
+			name: "successful creation",
+			jobConfig: k8s.JobConfig{
+				Name:        "test-job",
+				Namespace:   s.namespace,
+				Labels:      map[string]string{"app": "test"},
+				Completions: 2,
+				PodConfig: k8s.PodConfig{
+					Namespace:       s.namespace,
+					Name:            "test-pod",
+					Labels:          map[string]string{"app": "test"},
+					ContainerConfig: testContainerConfig,
+				},
+			},
+			setupMock: func() {},
+		},
+		{
+			name: "negative backoff limit",
+			jobConfig: k8s.JobConfig{
+				Name:         "test-job",
+				Namespace:    s.namespace,
+				Labels:       map[string]string{"app": "test"},
+				BackoffLimit: -1,
+			},
+			setupMock:   func() {},
+			expectedErr: k8s.ErrJobBackoffLimitNegative,
+		},
+		{
+			name: "client error",
+			jobConfig: k8s.JobConfig{
+				Name:      "error-job",
+				Namespace: s.namespace,
+				Labels:    map[string]string{"app": "error"},
+				PodConfig: k8s.PodConfig{
+					Namespace:       s.namespace,
+					Name:            "error-pod",
+					Labels:          map[string]string{"app": "error"},
+					ContainerConfig: testContainerConfig,
+				},
+			},
+			setupMock: func() {
+				s.client.Clientset().(*fake.Clientset).
+					PrependReactor("create", "jobs",
+						func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+							return true, nil, errInternalServerError
+						})
+			},
+			expectedErr: k8s.ErrCreatingJob.WithParams("error-job").Wrap(errInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			job, err := s.client.CreateJob(context.Background(), tt.jobConfig, false)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.jobConfig.Name, job.Name)
+			s.Assert().Equal(tt.jobConfig.Completions, *job.Spec.Completions)
+			s.Assert().Equal(v1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+		})
+	}
+}
+
+func (s *TestSuite) TestIsJobStarted() {
+	tests := []struct {
+		name        string
+		job         *batchv1.Job
+		expected    bool
+		expectedErr error
+	}{
+		{
+			name: "pod is ready",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "ready-job", Namespace: s.namespace},
+				Status:     batchv1.JobStatus{Ready: ptr.To[int32](1)},
+			},
+			expected: true,
+		},
+		{
+			name: "job has completed",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "completed-job", Namespace: s.namespace},
+				Status:     batchv1.JobStatus{Succeeded: 1},
+			},
+			expected: true,
+		},
+		{
+			name: "job is pending",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "pending-job", Namespace: s.namespace},
+			},
+			expected: false,
+		},
+		{
+			name:        "job not found",
+			job:         &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "missing-job"}},
+			expectedErr: k8s.ErrGettingJob,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			if tt.expectedErr == nil {
+				_, err := s.client.Clientset().BatchV1().Jobs(s.namespace).Create(context.Background(), tt.job, metav1.CreateOptions{})
+				s.Require().NoError(err)
+			}
+
+			started, err := s.client.IsJobStarted(context.Background(), tt.job.Name)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.expected, started)
+		})
+	}
+}
+
+func (s *TestSuite) TestWaitForJobCompletion() {
+	s.Run("already completed", func() {
+		s.Require().NoError(s.createJob("done-job", batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}},
+		}))
+
+		job, err := s.client.WaitForJobCompletion(context.Background(), "done-job")
+		s.Require().NoError(err)
+		s.Assert().Equal("done-job", job.Name)
+	})
+
+	s.Run("completes while waiting", func() {
+		s.Require().NoError(s.createJob("running-job", batchv1.JobStatus{}))
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			job, err := s.client.Clientset().BatchV1().Jobs(s.namespace).Get(context.Background(), "running-job", metav1.GetOptions{})
+			if err != nil {
+				return
+			}
+			job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}
+			_, _ = s.client.Clientset().BatchV1().Jobs(s.namespace).Update(context.Background(), job, metav1.UpdateOptions{})
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		job, err := s.client.WaitForJobCompletion(ctx, "running-job")
+		s.Require().NoError(err)
+		s.Assert().True(k8s.IsJobFinished(job))
+	})
+
+	s.Run("watch closed by the API server", func() {
+		s.Require().NoError(s.createJob("rewatched-job", batchv1.JobStatus{}))
+
+		var watches int32
+		s.client.Clientset().(*fake.Clientset).PrependWatchReactor("jobs",
+			func(action k8stesting.Action) (bool, watch.Interface, error) {
+				if atomic.AddInt32(&watches, 1) > 1 {
+					return false, nil, nil
+				}
+				// the first watch is closed right away
+				w := watch.NewFake()
+				w.Stop()
+				return true, w, nil
+			})
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			job, err := s.client.Clientset().BatchV1().Jobs(s.namespace).Get(context.Background(), "rewatched-job", metav1.GetOptions{})
+			if err != nil {
+				return
+			}
+			job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			_, _ = s.client.Clientset().BatchV1().Jobs(s.namespace).Update(context.Background(), job, metav1.UpdateOptions{})
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		job, err := s.client.WaitForJobCompletion(ctx, "rewatched-job")
+		s.Require().NoError(err)
+		s.Assert().True(k8s.IsJobFinished(job))
+		s.Assert().GreaterOrEqual(atomic.LoadInt32(&watches), int32(2))
+	})
+
+	s.Run("context cancelled", func() {
+		s.Require().NoError(s.createJob("stuck-job", batchv1.JobStatus{}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.client.WaitForJobCompletion(ctx, "stuck-job")
+		s.Require().Error(err)
+		s.Assert().ErrorIs(err, k8s.ErrWaitingForJobCompletion)
+	})
+
+	s.Run("job not found", func() {
+		_, err := s.client.WaitForJobCompletion(context.Background(), "missing-job")
+		s.Require().Error(err)
+		s.Assert().ErrorIs(err, k8s.ErrGettingJob)
+	})
+}
+
+func (s *TestSuite) TestGetFirstPodFromJob() {
+	tests := []struct {
+		name        string
+		jobName     string
+		setupMock   func()
+		expectedPod string
+		expectedErr error
+	}{
+		{
+			name:    "oldest pod is returned",
+			jobName: "test-job",
+			setupMock: func() {
+				labels := map[string]string{"job-name": "test-job"}
+				now := time.Now()
+				s.Require().NoError(s.createReplicaSetPod("test-job-retry", labels, now.Add(time.Minute), false))
+				s.Require().NoError(s.createReplicaSetPod("test-job-first", labels, now, false))
+			},
+			expectedPod: "test-job-first",
+		},
+		{
+			name:        "no pods",
+			jobName:     "empty-job",
+			setupMock:   func() {},
+			expectedErr: k8s.ErrNoPodsForJob,
+		},
+		{
+			name:    "client error",
+			jobName: "error-job",
+			setupMock: func() {
+				s.client.Clientset().(*fake.Clientset).
+					PrependReactor("list", "pods",
+						func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+							return true, nil, errInternalServerError
+						})
+			},
+			expectedErr: k8s.ErrListingPodsForJob,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			pod, err := s.client.GetFirstPodFromJob(context.Background(), tt.jobName)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.expectedPod, pod.Name)
+		})
+	}
+}
+
+func (s *TestSuite) TestDeleteJob() {
+	tests := []struct {
+		name        string
+		jobName     string
+		setupMock   func()
+		expectedErr error
+	}{
+		{
+			name:    "successful deletion",
+			jobName: "test-job",
+			setupMock: func() {
+				s.Require().NoError(s.createJob("test-job", batchv1.JobStatus{}))
+			},
+		},
+		{
+			name:      "job does not exist",
+			jobName:   "missing-job",
+			setupMock: func() {},
+		},
+		{
+			name:    "client error on delete",
+			jobName: "error-job",
+			setupMock: func() {
+				s.Require().NoError(s.createJob("error-job", batchv1.JobStatus{}))
+				s.client.Clientset().(*fake.Clientset).
+					PrependReactor("delete", "jobs",
+						func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+							return true, nil, errInternalServerError
+						})
+			},
+			expectedErr: k8s.ErrDeletingJob.WithParams("error-job").Wrap(errInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			err := s.client.DeleteJob(context.Background(), tt.jobName)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			exists, err := s.client.JobExists(context.Background(), tt.jobName)
+			s.Require().NoError(err)
+			s.Assert().False(exists)
+		})
+	}
+}
+
+func (s *TestSuite) createJob(name string, status batchv1.JobStatus) error {
+	_, err := s.client.Clientset().BatchV1().Jobs(s.namespace).Create(context.Background(), &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.namespace,
+		},
+		Status: status,
+	}, metav1.CreateOptions{})
+	return err
+}
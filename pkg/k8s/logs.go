@@ -8,16 +8,39 @@ import (
 )
 
 func (c *Client) GetLogStream(ctx context.Context, replicaSetName string, containerName string) (io.ReadCloser, error) {
-	logOptions := &v1.PodLogOptions{}
+	pod, err := c.GetFirstPodFromReplicaSet(ctx, replicaSetName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetPodLogStream(ctx, pod.Name, containerName)
+}
+
+// GetPodLogStream returns the log stream of the given container of a specific pod.
+func (c *Client) GetPodLogStream(ctx context.Context, podName string, containerName string) (io.ReadCloser, error) {
+	return c.podLogStream(ctx, podName, containerName, false)
+}
+
+// FollowPodLogStream returns the log stream of the given container of a specific pod,
+// which follows the new logs until the container terminates or the context is cancelled.
+func (c *Client) FollowPodLogStream(ctx context.Context, podName string, containerName string) (io.ReadCloser, error) {
+	return c.podLogStream(ctx, podName, containerName, true)
+}
+
+func (c *Client) podLogStream(ctx context.Context, podName string, containerName string, follow bool) (io.ReadCloser, error) {
+	if err := validatePodName(podName); err != nil {
+		return nil, err
+	}
+
+	logOptions := &v1.PodLogOptions{Follow: follow}
 	if containerName != "" {
 		logOptions.Container = containerName
 	}
 
-	pod, err := c.GetFirstPodFromReplicaSet(ctx, replicaSetName)
+	req := c.Clientset().CoreV1().Pods(c.Namespace()).GetLogs(podName, logOptions)
+	stream, err := req.Stream(ctx)
 	if err != nil {
-		return nil, err
+		return nil, ErrGettingPodLogs.WithParams(podName).Wrap(err)
 	}
-
-	req := c.Clientset().CoreV1().Pods(c.Namespace()).GetLogs(pod.Name, logOptions)
-	return req.Stream(ctx)
+	return stream, nil
 }
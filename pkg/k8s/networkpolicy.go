@@ -3,8 +3,20 @@ package k8s
 import (
 	"context"
 
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// NamespaceNameLabel is the label kubernetes sets on every namespace with its name
+	NamespaceNameLabel = "kubernetes.io/metadata.name"
+	anyIPv4CIDR        = "0.0.0.0/0"
 )
 
 func (c *Client) CreateNetworkPolicy(
@@ -106,3 +118,371 @@ func (c *Client) NetworkPolicyExists(ctx context.Context, name string) bool {
 
 	return true
 }
+
+// CreateOrUpdatePartitionNetworkPolicy creates or updates a network policy that prevents the pods matching the selector
+// from exchanging traffic with the pods of the namespace whose label 'key' has one of the blocked values.
+// All the other traffic, e.g. to pods without the label, to other namespaces or outside of the cluster, stays allowed.
+// To fully isolate two sets of pods, a policy must be applied on both sides.
+func (c *Client) CreateOrUpdatePartitionNetworkPolicy(
+	ctx context.Context,
+	name string,
+	selectorMap map[string]string,
+	key string,
+	blockedValues []string,
+) error {
+	if c.terminated {
+		return ErrClientTerminated
+	}
+	if err := validateNetworkPolicyName(name); err != nil {
+		return err
+	}
+	if err := validateSelectorMap(selectorMap); err != nil {
+		return err
+	}
+	if len(blockedValues) == 0 {
+		return ErrNoBlockedValuesForPartition.WithParams(name)
+	}
+
+	peers := []v1.NetworkPolicyPeer{
+		{
+			// pods of the same namespace, except the blocked ones
+			PodSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      key,
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   blockedValues,
+					},
+				},
+			},
+		},
+		{
+			// pods of all the other namespaces, e.g. kube-dns
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      NamespaceNameLabel,
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   []string{c.namespace},
+					},
+				},
+			},
+		},
+	}
+
+	np := &v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: c.namespace,
+			Name:      name,
+		},
+		Spec: v1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: selectorMap,
+			},
+			PolicyTypes: []v1.PolicyType{
+				v1.PolicyTypeIngress,
+				v1.PolicyTypeEgress,
+			},
+			Ingress: []v1.NetworkPolicyIngressRule{{From: peers}},
+			Egress: []v1.NetworkPolicyEgressRule{
+				{To: peers},
+				{
+					// traffic leaving the cluster
+					To: []v1.NetworkPolicyPeer{{IPBlock: &v1.IPBlock{CIDR: anyIPv4CIDR}}},
+				},
+			},
+		},
+	}
+
+	existing, err := c.clientset.NetworkingV1().NetworkPolicies(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return ErrGettingNetworkPolicy.WithParams(name).Wrap(err)
+		}
+		if _, err := c.clientset.NetworkingV1().NetworkPolicies(c.namespace).Create(ctx, np, metav1.CreateOptions{}); err != nil {
+			return ErrCreatingNetworkPolicy.WithParams(name).Wrap(err)
+		}
+		return nil
+	}
+
+	np.ResourceVersion = existing.ResourceVersion
+	if _, err := c.clientset.NetworkingV1().NetworkPolicies(c.namespace).Update(ctx, np, metav1.UpdateOptions{}); err != nil {
+		return ErrUpdatingNetworkPolicy.WithParams(name).Wrap(err)
+	}
+	return nil
+}
+
+// NetworkPolicyOptions describes a NetworkPolicy applied to the pods matching SelectorMap
+// NetworkPolicies are allow-lists: once a direction is restricted, only the traffic matching one of its rules is allowed
+type NetworkPolicyOptions struct {
+	Labels      map[string]string // Labels of the NetworkPolicy itself
+	SelectorMap map[string]string // SelectorMap selects the pods the policy applies to
+	// RestrictIngress denies all the incoming traffic that does not match one of the Ingress rules
+	// It is implied if there is at least one Ingress rule
+	RestrictIngress bool
+	Ingress         []NetworkPolicyRule
+	// RestrictEgress denies all the outgoing traffic that does not match one of the Egress rules
+	// It is implied if there is at least one Egress rule
+	RestrictEgress bool
+	Egress         []NetworkPolicyRule
+}
+
+// NetworkPolicyRule allows the traffic from (ingress) or to (egress) the peers on the given ports
+type NetworkPolicyRule struct {
+	Ports []NetworkPolicyPort // Ports of the rule, empty means all ports
+	Peers []NetworkPolicyPeer // Peers of the rule, empty means all peers
+}
+
+// NetworkPolicyPort is a port or a range of ports, a Port of 0 means all the ports of the protocol
+type NetworkPolicyPort struct {
+	Protocol corev1.Protocol
+	Port     int
+	EndPort  int // EndPort makes the port a range from Port to EndPort, 0 means a single port
+}
+
+// NetworkPolicyPeer selects pods, namespaces or an IP block
+// A peer with a CIDR can not have pod or namespace selectors
+type NetworkPolicyPeer struct {
+	PodSelector map[string]string // PodSelector selects pods by labels, nil with a nil NamespaceSelector means all pods of the namespace
+	// NamespaceSelector selects namespaces by labels, nil means the namespace of the policy and an empty map means all namespaces
+	NamespaceSelector map[string]string
+	CIDR              string   // CIDR selects an IP block, e.g. 10.0.0.0/8
+	Except            []string // Except excludes CIDRs from the IP block
+}
+
+// CreateNetworkPolicyWithOptions creates a NetworkPolicy from the given options.
+func (c *Client) CreateNetworkPolicyWithOptions(ctx context.Context, name string, opts NetworkPolicyOptions) (*v1.NetworkPolicy, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateNetworkPolicyName(name); err != nil {
+		return nil, err
+	}
+	if err := validateNetworkPolicyOptions(opts); err != nil {
+		return nil, err
+	}
+
+	np, err := c.clientset.NetworkingV1().NetworkPolicies(c.namespace).
+		Create(ctx, prepareNetworkPolicy(c.namespace, name, opts), metav1.CreateOptions{})
+	if err != nil {
+		return nil, ErrCreatingNetworkPolicy.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":      name,
+		"namespace": c.namespace,
+	}).Debug("network policy created")
+	return np, nil
+}
+
+// UpdateNetworkPolicy replaces the spec of an existing NetworkPolicy with the given options.
+func (c *Client) UpdateNetworkPolicy(ctx context.Context, name string, opts NetworkPolicyOptions) (*v1.NetworkPolicy, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateNetworkPolicyName(name); err != nil {
+		return nil, err
+	}
+	if err := validateNetworkPolicyOptions(opts); err != nil {
+		return nil, err
+	}
+
+	existing, err := c.GetNetworkPolicy(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	np := prepareNetworkPolicy(c.namespace, name, opts)
+	np.ResourceVersion = existing.ResourceVersion
+
+	updated, err := c.clientset.NetworkingV1().NetworkPolicies(c.namespace).Update(ctx, np, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, ErrUpdatingNetworkPolicy.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":      name,
+		"namespace": c.namespace,
+	}).Debug("network policy updated")
+	return updated, nil
+}
+
+// CreateOrUpdateNetworkPolicy creates the NetworkPolicy or updates it if it already exists.
+func (c *Client) CreateOrUpdateNetworkPolicy(ctx context.Context, name string, opts NetworkPolicyOptions) (*v1.NetworkPolicy, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	_, err := c.clientset.NetworkingV1().NetworkPolicies(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return nil, ErrGettingNetworkPolicy.WithParams(name).Wrap(err)
+		}
+		return c.CreateNetworkPolicyWithOptions(ctx, name, opts)
+	}
+	return c.UpdateNetworkPolicy(ctx, name, opts)
+}
+
+// PatchNetworkPolicy applies a JSON merge patch (RFC 7386) to an existing NetworkPolicy.
+func (c *Client) PatchNetworkPolicy(ctx context.Context, name string, patch []byte) (*v1.NetworkPolicy, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateNetworkPolicyName(name); err != nil {
+		return nil, err
+	}
+
+	np, err := c.clientset.NetworkingV1().NetworkPolicies(c.namespace).
+		Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, ErrPatchingNetworkPolicy.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":      name,
+		"namespace": c.namespace,
+	}).Debug("network policy patched")
+	return np, nil
+}
+
+func prepareNetworkPolicy(namespace, name string, opts NetworkPolicyOptions) *v1.NetworkPolicy {
+	np := &v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    opts.Labels,
+		},
+		Spec: v1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: opts.SelectorMap,
+			},
+		},
+	}
+
+	if opts.RestrictIngress || len(opts.Ingress) > 0 {
+		np.Spec.PolicyTypes = append(np.Spec.PolicyTypes, v1.PolicyTypeIngress)
+		for _, rule := range opts.Ingress {
+			np.Spec.Ingress = append(np.Spec.Ingress, v1.NetworkPolicyIngressRule{
+				Ports: prepareNetworkPolicyPorts(rule.Ports),
+				From:  prepareNetworkPolicyPeers(rule.Peers),
+			})
+		}
+	}
+	if opts.RestrictEgress || len(opts.Egress) > 0 {
+		np.Spec.PolicyTypes = append(np.Spec.PolicyTypes, v1.PolicyTypeEgress)
+		for _, rule := range opts.Egress {
+			np.Spec.Egress = append(np.Spec.Egress, v1.NetworkPolicyEgressRule{
+				Ports: prepareNetworkPolicyPorts(rule.Ports),
+				To:    prepareNetworkPolicyPeers(rule.Peers),
+			})
+		}
+	}
+	return np
+}
+
+func prepareNetworkPolicyPorts(ports []NetworkPolicyPort) []v1.NetworkPolicyPort {
+	var npPorts []v1.NetworkPolicyPort
+	for _, p := range ports {
+		npPort := v1.NetworkPolicyPort{Protocol: ptr.To(p.Protocol)}
+		if p.Port != 0 {
+			npPort.Port = ptr.To(intstr.FromInt32(int32(p.Port)))
+		}
+		if p.EndPort != 0 {
+			npPort.EndPort = ptr.To(int32(p.EndPort))
+		}
+		npPorts = append(npPorts, npPort)
+	}
+	return npPorts
+}
+
+func prepareNetworkPolicyPeers(peers []NetworkPolicyPeer) []v1.NetworkPolicyPeer {
+	var npPeers []v1.NetworkPolicyPeer
+	for _, p := range peers {
+		if p.CIDR != "" {
+			npPeers = append(npPeers, v1.NetworkPolicyPeer{
+				IPBlock: &v1.IPBlock{CIDR: p.CIDR, Except: p.Except},
+			})
+			continue
+		}
+
+		npPeer := v1.NetworkPolicyPeer{}
+		if p.PodSelector != nil || p.NamespaceSelector == nil {
+			npPeer.PodSelector = &metav1.LabelSelector{MatchLabels: p.PodSelector}
+		}
+		if p.NamespaceSelector != nil {
+			npPeer.NamespaceSelector = &metav1.LabelSelector{MatchLabels: p.NamespaceSelector}
+		}
+		npPeers = append(npPeers, npPeer)
+	}
+	return npPeers
+}
+
+// NetworkPolicyOptionsFromSpec returns the options of a NetworkPolicy created from NetworkPolicyOptions,
+// e.g. to restore the rules of a policy deployed by another process
+func NetworkPolicyOptionsFromSpec(np *v1.NetworkPolicy) NetworkPolicyOptions {
+	opts := NetworkPolicyOptions{
+		Labels:      np.Labels,
+		SelectorMap: np.Spec.PodSelector.MatchLabels,
+	}
+	for _, policyType := range np.Spec.PolicyTypes {
+		switch policyType {
+		case v1.PolicyTypeIngress:
+			opts.RestrictIngress = true
+		case v1.PolicyTypeEgress:
+			opts.RestrictEgress = true
+		}
+	}
+	for _, rule := range np.Spec.Ingress {
+		opts.Ingress = append(opts.Ingress, NetworkPolicyRule{
+			Ports: networkPolicyPortsFromSpec(rule.Ports),
+			Peers: networkPolicyPeersFromSpec(rule.From),
+		})
+	}
+	for _, rule := range np.Spec.Egress {
+		opts.Egress = append(opts.Egress, NetworkPolicyRule{
+			Ports: networkPolicyPortsFromSpec(rule.Ports),
+			Peers: networkPolicyPeersFromSpec(rule.To),
+		})
+	}
+	return opts
+}
+
+func networkPolicyPortsFromSpec(npPorts []v1.NetworkPolicyPort) []NetworkPolicyPort {
+	var ports []NetworkPolicyPort
+	for _, npPort := range npPorts {
+		port := NetworkPolicyPort{Protocol: corev1.ProtocolTCP}
+		if npPort.Protocol != nil {
+			port.Protocol = *npPort.Protocol
+		}
+		if npPort.Port != nil {
+			port.Port = npPort.Port.IntValue()
+		}
+		if npPort.EndPort != nil {
+			port.EndPort = int(*npPort.EndPort)
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+func networkPolicyPeersFromSpec(npPeers []v1.NetworkPolicyPeer) []NetworkPolicyPeer {
+	var peers []NetworkPolicyPeer
+	for _, npPeer := range npPeers {
+		if npPeer.IPBlock != nil {
+			peers = append(peers, NetworkPolicyPeer{CIDR: npPeer.IPBlock.CIDR, Except: npPeer.IPBlock.Except})
+			continue
+		}
+
+		var peer NetworkPolicyPeer
+		if npPeer.PodSelector != nil {
+			peer.PodSelector = npPeer.PodSelector.MatchLabels
+		}
+		if npPeer.NamespaceSelector != nil {
+			// an empty selector selects all the namespaces, unlike a nil one
+			peer.NamespaceSelector = npPeer.NamespaceSelector.MatchLabels
+			if peer.NamespaceSelector == nil {
+				peer.NamespaceSelector = map[string]string{}
+			}
+		}
+		peers = append(peers, peer)
+	}
+	return peers
+}
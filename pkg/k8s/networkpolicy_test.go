@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -62,6 +63,49 @@ func (s *TestSuite) TestCreateNetworkPolicy() {
 	}
 }
 
+func (s *TestSuite) TestCreateOrUpdatePartitionNetworkPolicy() {
+	ctx := context.Background()
+	selector := map[string]string{"knuu.sh/name": "validator-0"}
+
+	s.Run("creates the policy", func() {
+		err := s.client.CreateOrUpdatePartitionNetworkPolicy(ctx, "validator-0-partition", selector, "knuu.sh/name", []string{"validator-1"})
+		s.Require().NoError(err)
+
+		np, err := s.client.GetNetworkPolicy(ctx, "validator-0-partition")
+		s.Require().NoError(err)
+		s.Assert().Equal(selector, np.Spec.PodSelector.MatchLabels)
+		s.Require().Len(np.Spec.Ingress, 1)
+		expr := np.Spec.Ingress[0].From[0].PodSelector.MatchExpressions[0]
+		s.Assert().Equal(metav1.LabelSelectorOpNotIn, expr.Operator)
+		s.Assert().Equal([]string{"validator-1"}, expr.Values)
+	})
+
+	s.Run("updates the policy", func() {
+		err := s.client.CreateOrUpdatePartitionNetworkPolicy(ctx, "validator-0-partition", selector, "knuu.sh/name", []string{"validator-1", "validator-2"})
+		s.Require().NoError(err)
+
+		np, err := s.client.GetNetworkPolicy(ctx, "validator-0-partition")
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"validator-1", "validator-2"}, np.Spec.Ingress[0].From[0].PodSelector.MatchExpressions[0].Values)
+		s.Assert().Equal(np.Spec.Ingress[0].From, np.Spec.Egress[0].To)
+	})
+
+	s.Run("no blocked values", func() {
+		err := s.client.CreateOrUpdatePartitionNetworkPolicy(ctx, "validator-0-partition", selector, "knuu.sh/name", nil)
+		s.Assert().ErrorIs(err, k8s.ErrNoBlockedValuesForPartition)
+	})
+
+	s.Run("client error on update", func() {
+		s.client.Clientset().(*fake.Clientset).
+			PrependReactor("update", "networkpolicies",
+				func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+					return true, nil, errInternalServerError
+				})
+		err := s.client.CreateOrUpdatePartitionNetworkPolicy(ctx, "validator-0-partition", selector, "knuu.sh/name", []string{"validator-1"})
+		s.Assert().ErrorIs(err, k8s.ErrUpdatingNetworkPolicy)
+	})
+}
+
 func (s *TestSuite) TestDeleteNetworkPolicy() {
 	tests := []struct {
 		name        string
@@ -231,3 +275,101 @@ func (s *TestSuite) TestNetworkPolicyExists() {
 		})
 	}
 }
+
+func (s *TestSuite) TestCreateOrUpdateNetworkPolicy() {
+	ctx := context.Background()
+	opts := k8s.NetworkPolicyOptions{
+		SelectorMap: map[string]string{"app": "validator"},
+		Ingress: []k8s.NetworkPolicyRule{
+			{
+				Ports: []k8s.NetworkPolicyPort{{Protocol: corev1.ProtocolTCP, Port: 26656}},
+				Peers: []k8s.NetworkPolicyPeer{{PodSelector: map[string]string{"role": "peer"}}},
+			},
+		},
+		RestrictEgress: true,
+	}
+
+	s.Run("creates the policy", func() {
+		np, err := s.client.CreateOrUpdateNetworkPolicy(ctx, "validator-policy", opts)
+		s.Require().NoError(err)
+		s.Assert().Equal([]v1.PolicyType{v1.PolicyTypeIngress, v1.PolicyTypeEgress}, np.Spec.PolicyTypes)
+		s.Require().Len(np.Spec.Ingress, 1)
+		s.Assert().Equal(int32(26656), np.Spec.Ingress[0].Ports[0].Port.IntVal)
+		s.Assert().Equal(map[string]string{"role": "peer"}, np.Spec.Ingress[0].From[0].PodSelector.MatchLabels)
+		s.Assert().Nil(np.Spec.Ingress[0].From[0].NamespaceSelector)
+		s.Assert().Empty(np.Spec.Egress)
+	})
+
+	s.Run("updates the policy", func() {
+		updated := opts
+		updated.Egress = []k8s.NetworkPolicyRule{
+			{Peers: []k8s.NetworkPolicyPeer{{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}}},
+			{Peers: []k8s.NetworkPolicyPeer{{NamespaceSelector: map[string]string{}}}},
+		}
+		_, err := s.client.CreateOrUpdateNetworkPolicy(ctx, "validator-policy", updated)
+		s.Require().NoError(err)
+
+		np, err := s.client.GetNetworkPolicy(ctx, "validator-policy")
+		s.Require().NoError(err)
+		s.Require().Len(np.Spec.Egress, 2)
+		s.Assert().Equal("10.0.0.0/8", np.Spec.Egress[0].To[0].IPBlock.CIDR)
+		s.Assert().Nil(np.Spec.Egress[1].To[0].PodSelector)
+		s.Assert().NotNil(np.Spec.Egress[1].To[0].NamespaceSelector)
+	})
+
+	s.Run("patches the policy", func() {
+		np, err := s.client.PatchNetworkPolicy(ctx, "validator-policy", []byte(`{"metadata":{"labels":{"patched":"true"}}}`))
+		s.Require().NoError(err)
+		s.Assert().Equal("true", np.Labels["patched"])
+	})
+
+	s.Run("client error on update", func() {
+		s.client.Clientset().(*fake.Clientset).
+			PrependReactor("update", "networkpolicies",
+				func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+					return true, nil, errInternalServerError
+				})
+		_, err := s.client.CreateOrUpdateNetworkPolicy(ctx, "validator-policy", opts)
+		s.Assert().ErrorIs(err, k8s.ErrUpdatingNetworkPolicy)
+	})
+}
+
+func (s *TestSuite) TestCreateNetworkPolicyWithOptionsValidation() {
+	tests := []struct {
+		name        string
+		rule        k8s.NetworkPolicyRule
+		expectedErr error
+	}{
+		{
+			name:        "invalid protocol",
+			rule:        k8s.NetworkPolicyRule{Ports: []k8s.NetworkPolicyPort{{Protocol: "ICMP", Port: 1}}},
+			expectedErr: k8s.ErrInvalidNetworkPolicyProtocol,
+		},
+		{
+			name:        "invalid port range",
+			rule:        k8s.NetworkPolicyRule{Ports: []k8s.NetworkPolicyPort{{Protocol: corev1.ProtocolTCP, Port: 100, EndPort: 10}}},
+			expectedErr: k8s.ErrInvalidNetworkPolicyPortRange,
+		},
+		{
+			name:        "invalid CIDR",
+			rule:        k8s.NetworkPolicyRule{Peers: []k8s.NetworkPolicyPeer{{CIDR: "10.0.0.0/33"}}},
+			expectedErr: k8s.ErrInvalidCIDR,
+		},
+		{
+			name: "CIDR with selector",
+			rule: k8s.NetworkPolicyRule{Peers: []k8s.NetworkPolicyPeer{
+				{CIDR: "10.0.0.0/8", PodSelector: map[string]string{"app": "test"}},
+			}},
+			expectedErr: k8s.ErrNetworkPolicyPeerCIDRWithSelector,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := s.client.CreateNetworkPolicyWithOptions(context.Background(), "invalid-np", k8s.NetworkPolicyOptions{
+				Ingress: []k8s.NetworkPolicyRule{tt.rule},
+			})
+			s.Assert().ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,10 +30,15 @@ const (
 	knuuPath = "/knuu"
 
 	podFilesConfigmapNameSuffix = "-config"
+	// podSecretNameSuffix is the suffix of the secret holding the secret files and environment variables of a container
+	podSecretNameSuffix = "-secret"
 
 	initContainerNameSuffix = "-init"
 	initContainerImage      = "nicolaka/netshoot"
 	defaultContainerUser    = 0
+
+	// s3SigV4Provider signs the downloads of the files with credentials for the S3 API, minio uses the region us-east-1
+	s3SigV4Provider = "aws:amz:us-east-1:s3"
 )
 
 type ContainerConfig struct {
@@ -40,6 +48,7 @@ type ContainerConfig struct {
 	Command         []string            // Command to run in the container
 	Args            []string            // Arguments to pass to the command in the container
 	Env             map[string]string   // Environment variables to set in the container
+	SecretEnv       []string            // Environment variables read from the secret of the container, keyed by their name
 	Volumes         []*Volume           // Volumes to mount in the Pod
 	MemoryRequest   resource.Quantity   // Memory request for the container
 	MemoryLimit     resource.Quantity   // Memory limit for the container
@@ -48,6 +57,7 @@ type ContainerConfig struct {
 	ReadinessProbe  *v1.Probe           // Readiness probe for the container
 	StartupProbe    *v1.Probe           // Startup probe for the container
 	Files           []*File             // Files to add to the Pod
+	SharedVolumes   []*SharedVolume     // Volumes of the scope shared with other Pods
 	SecurityContext *v1.SecurityContext // Security context for the container
 	TCPPorts        []int               // TCP ports to expose on the Pod
 	UDPPorts        []int               // UDP ports to expose on the Pod
@@ -63,12 +73,85 @@ type PodConfig struct {
 	SidecarConfigs     []ContainerConfig // SideCarConfigs for the Pod
 	Annotations        map[string]string // Annotations to apply to the Pod
 	NodeSelector       map[string]string // NodeSelector to apply to the Pod
+	ImagePullSecrets   []string          // ImagePullSecrets are the names of the secrets used to pull the images of the Pod
 }
 
 type Volume struct {
 	Path  string
 	Size  resource.Quantity
 	Owner int64
+	// StorageClass is the StorageClass of the PersistentVolumeClaim, the default class of the cluster if empty
+	StorageClass string
+	// AccessMode is the access mode of the PersistentVolumeClaim, ReadWriteOnce if empty
+	AccessMode v1.PersistentVolumeAccessMode
+	// VolumeMode is the volume mode of the PersistentVolumeClaim, a Block volume is attached as a raw device at its path
+	VolumeMode v1.PersistentVolumeMode
+	// EmptyDir, if true, backs the volume by an emptyDir limited to its size instead of a PersistentVolumeClaim,
+	// its data is lost when the pod is deleted
+	EmptyDir bool
+	// Medium is the storage medium of the emptyDir, Memory for a tmpfs counted against the memory limit of the container
+	Medium v1.StorageMedium
+	// Restored, if true, means the volume already holds its data, e.g. restored from a snapshot,
+	// so the init container does not copy the content of the image into it nor change its owner
+	Restored bool
+}
+
+// IsBlock returns true if the volume is attached as a raw block device instead of being mounted
+func (v *Volume) IsBlock() bool {
+	return v.VolumeMode == v1.PersistentVolumeBlock
+}
+
+// copiedVolumes returns the volumes the init container fills with the content of the image
+func copiedVolumes(volumes []*Volume) []*Volume {
+	var copied []*Volume
+	for _, volume := range volumes {
+		if !volume.Restored {
+			copied = append(copied, volume)
+		}
+	}
+	return copied
+}
+
+// filesystemVolumes returns the volumes that are mounted, i.e. all but the block volumes
+func filesystemVolumes(volumes []*Volume) []*Volume {
+	var filesystem []*Volume
+	for _, volume := range volumes {
+		if !volume.IsBlock() {
+			filesystem = append(filesystem, volume)
+		}
+	}
+	return filesystem
+}
+
+// VolumeClaimOptions returns the options of the PersistentVolumeClaim backing the given volumes
+// The volumes of a container share one claim, so the options are taken from the first volume
+func VolumeClaimOptions(volumes []*Volume) []PersistentVolumeClaimOption {
+	if len(volumes) == 0 {
+		return nil
+	}
+	var (
+		volume = volumes[0]
+		opts   []PersistentVolumeClaimOption
+	)
+	if volume.StorageClass != "" {
+		opts = append(opts, WithStorageClass(volume.StorageClass))
+	}
+	if volume.AccessMode != "" {
+		opts = append(opts, WithAccessModes(volume.AccessMode))
+	}
+	if volume.VolumeMode != "" {
+		opts = append(opts, WithVolumeMode(volume.VolumeMode))
+	}
+	return opts
+}
+
+// SharedVolume is a volume of the scope, backed by a PersistentVolumeClaim, a ConfigMap or a Secret,
+// that several pods mount, possibly at different paths
+type SharedVolume struct {
+	Name     string          // Name of the volume in the Pod
+	Path     string          // Path the volume is mounted at in the container
+	ReadOnly bool            // Mounts the volume read-only
+	Source   v1.VolumeSource // Source of the volume
 }
 
 type File struct {
@@ -76,6 +159,67 @@ type File struct {
 	Dest       string
 	Chown      string
 	Permission string
+	// URL, if set, is where the init container downloads the file from instead of the configmap
+	URL string
+	// Checksum is the sha256 of the downloaded file, verified before the containers start
+	Checksum string
+	// Signed, if true, means the download is signed with AWS signature v4, e.g. for a minio URL,
+	// with the credentials (user:password) read from the FileCredentialsKey of the secret of the container
+	Signed bool
+	// Secret, if true, means that the file is mounted from the secret of the container instead of the configmap
+	Secret bool
+}
+
+// FileCredentialsKey is the key of the secret of the container holding the credentials of the signed files,
+// the init container reads them from the environment variable of the same name
+const FileCredentialsKey = "KNUU_FILE_CREDENTIALS"
+
+// ContainerSecretName returns the name of the secret holding the secret files and environment variables of a container
+func ContainerSecretName(containerName string) string {
+	return containerName + podSecretNameSuffix
+}
+
+// SecretFileKey returns the key of the n-th secret file of a container in its secret
+func SecretFileKey(n int) string {
+	return fmt.Sprintf("file-%d", n)
+}
+
+// IsRemote returns true if the file is downloaded from its URL instead of being mounted from the configmap
+func (f *File) IsRemote() bool {
+	return f.URL != ""
+}
+
+// configMapFiles returns the files mounted from the configmap, their index is their key in the configmap
+func configMapFiles(files []*File) []*File {
+	var cmFiles []*File
+	for _, file := range files {
+		if !file.IsRemote() && !file.Secret {
+			cmFiles = append(cmFiles, file)
+		}
+	}
+	return cmFiles
+}
+
+// secretFiles returns the files mounted from the secret, their index is their key in the secret
+func secretFiles(files []*File) []*File {
+	var sFiles []*File
+	for _, file := range files {
+		if file.Secret {
+			sFiles = append(sFiles, file)
+		}
+	}
+	return sFiles
+}
+
+// signedFiles returns the files whose download is signed with the credentials of the secret of the container
+func signedFiles(files []*File) []*File {
+	var signed []*File
+	for _, file := range files {
+		if file.Signed {
+			signed = append(signed, file)
+		}
+	}
+	return signed
 }
 
 // DeployPod creates a new pod in the namespace that k8s client is initiate with if it doesn't already exist.
@@ -180,19 +324,95 @@ func (c *Client) RunCommandInPod(
 	containerName string,
 	cmd []string,
 ) (string, error) {
-	if err := validatePodName(podName); err != nil {
+	return c.RunCommandInPodWithStdin(ctx, podName, containerName, cmd, nil)
+}
+
+// RunCommandInPodWithStdin runs a command in a container within a pod with a context,
+// the given reader, if not nil, is streamed to the standard input of the command.
+func (c *Client) RunCommandInPodWithStdin(
+	ctx context.Context,
+	podName,
+	containerName string,
+	cmd []string,
+	stdin io.Reader,
+) (string, error) {
+	exec, err := c.newPodExecutor(ctx, podName, containerName, cmd, stdin != nil)
+	if err != nil {
 		return "", err
 	}
+
+	// Execute the command and capture the output and error streams
+	var stdout, stderr bytes.Buffer
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Tty:    false,
+	})
+
+	if err != nil {
+		return "", ErrExecutingCommand.WithParams(stdout.String(), stderr.String()).Wrap(err)
+	}
+
+	// Check if there were any errors on the error stream
+	if stderr.Len() != 0 {
+		return "", ErrCommandExecution.WithParams(stdout.String(), stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// StreamCommandInPod runs a command in a container within a pod with a context,
+// the given reader, if not nil, is streamed to the standard input of the command
+// and the standard output of the command is streamed to the given writer.
+// Unlike RunCommandInPod, the output on the error stream only fails the command if it exits with an error.
+func (c *Client) StreamCommandInPod(
+	ctx context.Context,
+	podName,
+	containerName string,
+	cmd []string,
+	stdin io.Reader,
+	stdout io.Writer,
+) error {
+	exec, err := c.newPodExecutor(ctx, podName, containerName, cmd, stdin != nil)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+		Tty:    false,
+	})
+	if err != nil {
+		return ErrStreamingCommand.WithParams(stderr.String()).Wrap(err)
+	}
+	return nil
+}
+
+// newPodExecutor creates an executor of the command in a container within a pod
+func (c *Client) newPodExecutor(
+	ctx context.Context,
+	podName,
+	containerName string,
+	cmd []string,
+	stdin bool,
+) (remotecommand.Executor, error) {
+	if err := validatePodName(podName); err != nil {
+		return nil, err
+	}
 	if err := validateContainerName(containerName); err != nil {
-		return "", err
+		return nil, err
 	}
 	if err := validateCommand(cmd); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	_, err := c.getPod(ctx, podName)
 	if err != nil {
-		return "", ErrGettingPod.WithParams(podName).Wrap(err)
+		return nil, ErrGettingPod.WithParams(podName).Wrap(err)
 	}
 
 	req := c.clientset.CoreV1().RESTClient().Post().
@@ -203,7 +423,7 @@ func (c *Client) RunCommandInPod(
 		VersionedParams(&v1.PodExecOptions{
 			Command:   cmd,
 			Container: containerName,
-			Stdin:     false,
+			Stdin:     stdin,
 			Stdout:    true,
 			Stderr:    true,
 			TTY:       false,
@@ -212,31 +432,13 @@ func (c *Client) RunCommandInPod(
 	// Create an executor for the command execution
 	k8sConfig, err := getClusterConfig()
 	if err != nil {
-		return "", ErrGettingK8sConfig.Wrap(err)
+		return nil, ErrGettingK8sConfig.Wrap(err)
 	}
 	exec, err := remotecommand.NewSPDYExecutor(k8sConfig, http.MethodPost, req.URL())
 	if err != nil {
-		return "", ErrCreatingExecutor.Wrap(err)
-	}
-
-	// Execute the command and capture the output and error streams
-	var stdout, stderr bytes.Buffer
-	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdout: &stdout,
-		Stderr: &stderr,
-		Tty:    false,
-	})
-
-	if err != nil {
-		return "", ErrExecutingCommand.WithParams(stdout.String(), stderr.String()).Wrap(err)
-	}
-
-	// Check if there were any errors on the error stream
-	if stderr.Len() != 0 {
-		return "", ErrCommandExecution.WithParams(stdout.String(), stderr.String())
+		return nil, ErrCreatingExecutor.Wrap(err)
 	}
-
-	return stdout.String(), nil
+	return exec, nil
 }
 
 func (c *Client) DeletePodWithGracePeriod(ctx context.Context, name string, gracePeriodSeconds *int64) error {
@@ -368,12 +570,45 @@ func buildEnv(envMap map[string]string) []v1.EnvVar {
 	return envVars
 }
 
+// buildSecretEnv generates the environment variables read from the secret of the container
+func buildSecretEnv(name string, keys []string) []v1.EnvVar {
+	envVars := make([]v1.EnvVar, 0, len(keys))
+	for _, key := range keys {
+		envVars = append(envVars, v1.EnvVar{
+			Name: key,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: ContainerSecretName(name)},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	return envVars
+}
+
 // buildPodVolumes generates a volume configuration for a pod based on the given name.
-// If the volumes amount is zero, returns an empty slice.
-func buildPodVolumes(name string, volumesAmount, filesAmount int) []v1.Volume {
+// If there are no volumes nor files, returns an empty slice.
+func buildPodVolumes(name string, volumes []*Volume, files []*File) []v1.Volume {
 	var podVolumes []v1.Volume
 
-	if volumesAmount != 0 {
+	if len(volumes) != 0 && volumes[0].EmptyDir {
+		// the ephemeral volumes share an emptyDir limited to their total size
+		totalSize := resource.Quantity{}
+		for _, volume := range volumes {
+			totalSize.Add(volume.Size)
+		}
+		podVolume := v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{
+					Medium:    volumes[0].Medium,
+					SizeLimit: &totalSize,
+				},
+			},
+		}
+		podVolumes = append(podVolumes, podVolume)
+	} else if len(volumes) != 0 {
 		podVolume := v1.Volume{
 			Name: name,
 			VolumeSource: v1.VolumeSource{
@@ -386,7 +621,7 @@ func buildPodVolumes(name string, volumesAmount, filesAmount int) []v1.Volume {
 		podVolumes = append(podVolumes, podVolume)
 	}
 
-	if volumesAmount == 0 && filesAmount != 0 {
+	if len(volumes) == 0 && len(files) != 0 {
 		podVolume := v1.Volume{
 			Name: name,
 			VolumeSource: v1.VolumeSource{
@@ -396,7 +631,7 @@ func buildPodVolumes(name string, volumesAmount, filesAmount int) []v1.Volume {
 		podVolumes = append(podVolumes, podVolume)
 	}
 
-	if filesAmount != 0 {
+	if len(configMapFiles(files)) != 0 {
 		podFiles := v1.Volume{
 			Name: name + podFilesConfigmapNameSuffix,
 			VolumeSource: v1.VolumeSource{
@@ -412,13 +647,26 @@ func buildPodVolumes(name string, volumesAmount, filesAmount int) []v1.Volume {
 		podVolumes = append(podVolumes, podFiles)
 	}
 
+	if len(secretFiles(files)) != 0 {
+		podSecretFiles := v1.Volume{
+			Name: name + podSecretNameSuffix,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName:  ContainerSecretName(name),
+					DefaultMode: ptr.To[int32](0600),
+				},
+			},
+		}
+		podVolumes = append(podVolumes, podSecretFiles)
+	}
+
 	return podVolumes
 }
 
 // buildContainerVolumes generates a volume mount configuration for a container based on the given name and volumes.
 func buildContainerVolumes(name string, volumes []*Volume, files []*File) []v1.VolumeMount {
 	var containerVolumes []v1.VolumeMount
-	for _, volume := range volumes {
+	for _, volume := range filesystemVolumes(volumes) {
 		containerVolumes = append(
 			containerVolumes,
 			v1.VolumeMount{
@@ -448,6 +696,20 @@ func buildContainerVolumes(name string, volumes []*Volume, files []*File) []v1.V
 	return append(containerVolumes, containerFiles...)
 }
 
+// buildContainerVolumeDevices generates the devices of the block volumes of a container
+func buildContainerVolumeDevices(name string, volumes []*Volume) []v1.VolumeDevice {
+	var devices []v1.VolumeDevice
+	for _, volume := range volumes {
+		if volume.IsBlock() {
+			devices = append(devices, v1.VolumeDevice{
+				Name:       name,
+				DevicePath: volume.Path,
+			})
+		}
+	}
+	return devices
+}
+
 // buildInitContainerVolumes generates a volume mount configuration for an init container based on the given name and volumes.
 func buildInitContainerVolumes(name string, volumes []*Volume, files []*File) []v1.VolumeMount {
 	if len(volumes) == 0 && len(files) == 0 {
@@ -478,13 +740,20 @@ func buildInitContainerVolumes(name string, volumes []*Volume, files []*File) []
 	}
 
 	var containerFiles []v1.VolumeMount
-	for n, file := range files {
+	for n, file := range configMapFiles(files) {
 		containerFiles = append(containerFiles, v1.VolumeMount{
 			Name:      name + podFilesConfigmapNameSuffix,
 			MountPath: file.Dest,
 			SubPath:   fmt.Sprintf("%d", n),
 		})
 	}
+	for n, file := range secretFiles(files) {
+		containerFiles = append(containerFiles, v1.VolumeMount{
+			Name:      name + podSecretNameSuffix,
+			MountPath: file.Dest,
+			SubPath:   SecretFileKey(n),
+		})
+	}
 
 	return append(containerVolumes, containerFiles...)
 }
@@ -514,6 +783,9 @@ func (c *Client) buildInitContainerCommand(volumes []*Volume, files []*File) []s
 		chown := file.Chown
 		permission := file.Permission
 		addFileToKnuu := fmt.Sprintf("cp %s %s && ", file.Dest, filepath.Join(knuuPath, file.Dest))
+		if file.IsRemote() {
+			addFileToKnuu = downloadFileCommand(file)
+		}
 		if chown != "" {
 			addFileToKnuu += fmt.Sprintf("chown %s %s && ", chown, filepath.Join(knuuPath, file.Dest))
 		}
@@ -524,7 +796,7 @@ func (c *Client) buildInitContainerCommand(volumes []*Volume, files []*File) []s
 	}
 
 	// for each volume, copy the contents of the volume to the knuu volume
-	for _, volume := range volumes {
+	for _, volume := range copiedVolumes(volumes) {
 		knuuVolumePath := fmt.Sprintf("%s%s", knuuPath, volume.Path)
 		cmd := fmt.Sprintf("if [ -d %s ] && [ \"$(ls -A %s)\" ]; then mkdir -p %s && cp -r %s/* %s && chown -R %d:%d %s",
 			volume.Path, volume.Path, knuuVolumePath, volume.Path,
@@ -543,6 +815,25 @@ func (c *Client) buildInitContainerCommand(volumes []*Volume, files []*File) []s
 	return commands
 }
 
+// downloadFileCommand generates the command downloading a remote file to the knuu path and verifying its checksum
+// The credentials of a signed download are only expanded by the shell with the tracing turned off,
+// so they are neither in the command nor in the logs of the init container
+func downloadFileCommand(file *File) string {
+	target := filepath.Join(knuuPath, file.Dest)
+	cmd := "curl -fsSL --retry 5 "
+	if file.Signed {
+		cmd = "set +x && " + cmd + fmt.Sprintf("--aws-sigv4 '%s' --user \"$%s\" ", s3SigV4Provider, FileCredentialsKey)
+	}
+	cmd += fmt.Sprintf("-o %s '%s' && ", target, file.URL)
+	if file.Signed {
+		cmd += "set -x && "
+	}
+	if file.Checksum != "" {
+		cmd += fmt.Sprintf("echo '%s  %s' | sha256sum -c - && ", file.Checksum, target)
+	}
+	return cmd
+}
+
 // buildResources generates a resource configuration for a container based on the given CPU and memory requests and limits.
 func buildResources(memoryRequest, memoryLimit, cpuRequest resource.Quantity) v1.ResourceRequirements {
 	return v1.ResourceRequirements{
@@ -583,8 +874,9 @@ func prepareContainer(config ContainerConfig) v1.Container {
 		ImagePullPolicy: config.ImagePullPolicy,
 		Command:         config.Command,
 		Args:            config.Args,
-		Env:             buildEnv(config.Env),
-		VolumeMounts:    buildContainerVolumes(config.Name, config.Volumes, config.Files),
+		Env:             append(buildEnv(config.Env), buildSecretEnv(config.Name, config.SecretEnv)...),
+		VolumeMounts:    append(buildContainerVolumes(config.Name, config.Volumes, config.Files), buildSharedVolumeMounts(config.SharedVolumes)...),
+		VolumeDevices:   buildContainerVolumeDevices(config.Name, config.Volumes),
 		Resources:       buildResources(config.MemoryRequest, config.MemoryLimit, config.CPURequest),
 		Ports:           buildPodPorts(config.TCPPorts, config.UDPPorts),
 		LivenessProbe:   config.LivenessProbe,
@@ -596,10 +888,17 @@ func prepareContainer(config ContainerConfig) v1.Container {
 
 // prepareInitContainers creates a slice of v1.Container as init containers.
 func (c *Client) prepareInitContainers(config ContainerConfig, init bool) []v1.Container {
-	if !init || (len(config.Volumes) == 0 && len(config.Files) == 0) {
+	// the block volumes are not mounted, so the init container cannot prepare them
+	volumes := filesystemVolumes(config.Volumes)
+	if !init || (len(copiedVolumes(volumes)) == 0 && len(config.Files) == 0) {
 		return nil
 	}
 
+	var env []v1.EnvVar
+	if len(signedFiles(config.Files)) != 0 {
+		env = buildSecretEnv(config.Name, []string{FileCredentialsKey})
+	}
+
 	return []v1.Container{
 		{
 			Name:  config.Name + initContainerNameSuffix,
@@ -607,15 +906,47 @@ func (c *Client) prepareInitContainers(config ContainerConfig, init bool) []v1.C
 			SecurityContext: &v1.SecurityContext{
 				RunAsUser: ptr.To[int64](defaultContainerUser),
 			},
-			Command:      c.buildInitContainerCommand(config.Volumes, config.Files),
-			VolumeMounts: buildInitContainerVolumes(config.Name, config.Volumes, config.Files),
+			Env:          env,
+			Command:      c.buildInitContainerCommand(volumes, config.Files),
+			VolumeMounts: buildInitContainerVolumes(config.Name, volumes, config.Files),
 		},
 	}
 }
 
+// buildSharedVolumeMounts generates the volume mounts of the shared volumes of a container
+func buildSharedVolumeMounts(sharedVolumes []*SharedVolume) []v1.VolumeMount {
+	var mounts []v1.VolumeMount
+	for _, sv := range sharedVolumes {
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      sv.Name,
+			MountPath: sv.Path,
+			ReadOnly:  sv.ReadOnly,
+		})
+	}
+	return mounts
+}
+
+// appendSharedVolumes appends the shared volumes of a container to the pod volumes
+// A shared volume mounted by several containers of the pod is added once
+func appendSharedVolumes(podVolumes []v1.Volume, sharedVolumes []*SharedVolume) []v1.Volume {
+	for _, sv := range sharedVolumes {
+		exists := false
+		for _, vol := range podVolumes {
+			if vol.Name == sv.Name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			podVolumes = append(podVolumes, v1.Volume{Name: sv.Name, VolumeSource: sv.Source})
+		}
+	}
+	return podVolumes
+}
+
 // preparePodVolumes prepares pod volumes
 func preparePodVolumes(config ContainerConfig) []v1.Volume {
-	return buildPodVolumes(config.Name, len(config.Volumes), len(config.Files))
+	return buildPodVolumes(config.Name, config.Volumes, config.Files)
 }
 
 func (c *Client) preparePodSpec(spec PodConfig, init bool) v1.PodSpec {
@@ -626,6 +957,9 @@ func (c *Client) preparePodSpec(spec PodConfig, init bool) v1.PodSpec {
 		Volumes:            preparePodVolumes(spec.ContainerConfig),
 		NodeSelector:       spec.NodeSelector,
 	}
+	for _, secret := range spec.ImagePullSecrets {
+		podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, v1.LocalObjectReference{Name: secret})
+	}
 
 	// Prepare sidecar containers and append to the pod spec
 	for _, sidecarConfig := range spec.SidecarConfigs {
@@ -638,6 +972,11 @@ func (c *Client) preparePodSpec(spec PodConfig, init bool) v1.PodSpec {
 		podSpec.Volumes = append(podSpec.Volumes, sidecarVolumes...)
 	}
 
+	podSpec.Volumes = appendSharedVolumes(podSpec.Volumes, spec.ContainerConfig.SharedVolumes)
+	for _, sidecarConfig := range spec.SidecarConfigs {
+		podSpec.Volumes = appendSharedVolumes(podSpec.Volumes, sidecarConfig.SharedVolumes)
+	}
+
 	return podSpec
 }
 
@@ -658,3 +997,211 @@ func (c *Client) preparePod(spec PodConfig, init bool) *v1.Pod {
 	}).Debug("prepared pod")
 	return pod
 }
+
+// ContainerConfigFromPodSpec rebuilds the ContainerConfig of the given container from a pod spec prepared by knuu.
+// It is used to reattach to workloads that are already running in the cluster.
+// The sources of the files and the sizes of the volumes are not part of the pod spec, so they are left empty.
+func ContainerConfigFromPodSpec(spec v1.PodSpec, name string) (*ContainerConfig, error) {
+	var container *v1.Container
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == name {
+			container = &spec.Containers[i]
+			break
+		}
+	}
+	if container == nil {
+		return nil, ErrContainerNotFoundInPodSpec.WithParams(name)
+	}
+
+	config := &ContainerConfig{
+		Name:            container.Name,
+		Image:           container.Image,
+		ImagePullPolicy: container.ImagePullPolicy,
+		Command:         container.Command,
+		Args:            container.Args,
+		Env:             make(map[string]string, len(container.Env)),
+		MemoryRequest:   container.Resources.Requests[v1.ResourceMemory],
+		MemoryLimit:     container.Resources.Limits[v1.ResourceMemory],
+		CPURequest:      container.Resources.Requests[v1.ResourceCPU],
+		LivenessProbe:   container.LivenessProbe,
+		ReadinessProbe:  container.ReadinessProbe,
+		StartupProbe:    container.StartupProbe,
+		SecurityContext: container.SecurityContext,
+	}
+	for _, env := range container.Env {
+		if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			config.SecretEnv = append(config.SecretEnv, env.Name)
+			continue
+		}
+		config.Env[env.Name] = env.Value
+	}
+	for _, port := range container.Ports {
+		switch port.Protocol {
+		case v1.ProtocolUDP:
+			config.UDPPorts = append(config.UDPPorts, int(port.ContainerPort))
+		default:
+			config.TCPPorts = append(config.TCPPorts, int(port.ContainerPort))
+		}
+	}
+
+	var initContainer *v1.Container
+	for i := range spec.InitContainers {
+		if spec.InitContainers[i].Name == name+initContainerNameSuffix {
+			initContainer = &spec.InitContainers[i]
+			break
+		}
+	}
+	initCommand := ""
+	if initContainer != nil && len(initContainer.Command) > 0 {
+		initCommand = initContainer.Command[len(initContainer.Command)-1]
+	}
+
+	// An emptyDir volume named after the container without a size limit means that only files are mounted,
+	// with a size limit it backs ephemeral volumes
+	var (
+		filesOnly bool
+		emptyDir  *v1.EmptyDirVolumeSource
+	)
+	for _, vol := range spec.Volumes {
+		if vol.Name == name && vol.EmptyDir != nil {
+			filesOnly = vol.EmptyDir.SizeLimit == nil
+			emptyDir = vol.EmptyDir
+		}
+	}
+	if !filesOnly {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name != name {
+				continue
+			}
+			volume := &Volume{
+				Path:  mount.MountPath,
+				Owner: parseVolumeOwner(initCommand, mount.MountPath),
+			}
+			if emptyDir != nil {
+				volume.EmptyDir = true
+				volume.Medium = emptyDir.Medium
+				volume.Size = *emptyDir.SizeLimit
+			}
+			config.Volumes = append(config.Volumes, volume)
+		}
+	}
+	for _, device := range container.VolumeDevices {
+		if device.Name == name {
+			config.Volumes = append(config.Volumes, &Volume{Path: device.DevicePath, VolumeMode: v1.PersistentVolumeBlock})
+		}
+	}
+
+	// The volumes that are not named after the container are shared volumes
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == name || mount.Name == name+podFilesConfigmapNameSuffix || mount.Name == name+podSecretNameSuffix {
+			continue
+		}
+		for _, vol := range spec.Volumes {
+			if vol.Name != mount.Name {
+				continue
+			}
+			config.SharedVolumes = append(config.SharedVolumes, &SharedVolume{
+				Name:     mount.Name,
+				Path:     mount.MountPath,
+				ReadOnly: mount.ReadOnly,
+				Source:   vol.VolumeSource,
+			})
+		}
+	}
+
+	if initContainer == nil {
+		return config, nil
+	}
+	files := make(map[int]*File)
+	for _, mount := range initContainer.VolumeMounts {
+		if mount.Name != name+podFilesConfigmapNameSuffix {
+			continue
+		}
+		index, err := strconv.Atoi(mount.SubPath)
+		if err != nil {
+			continue
+		}
+		target := filepath.Join(knuuPath, mount.MountPath)
+		files[index] = &File{
+			Dest:       mount.MountPath,
+			Chown:      parseInitCommandArg(initCommand, "chown", target),
+			Permission: parseInitCommandArg(initCommand, "chmod", target),
+		}
+	}
+	// Keep the order of the files, as their index is the key in the configmap
+	for i := 0; i < len(files); i++ {
+		if file, ok := files[i]; ok {
+			config.Files = append(config.Files, file)
+		}
+	}
+	config.Files = append(config.Files, parseRemoteFiles(initCommand)...)
+
+	for n := 0; ; n++ {
+		var mount *v1.VolumeMount
+		for i := range initContainer.VolumeMounts {
+			m := &initContainer.VolumeMounts[i]
+			if m.Name == name+podSecretNameSuffix && m.SubPath == SecretFileKey(n) {
+				mount = m
+				break
+			}
+		}
+		if mount == nil {
+			break
+		}
+		target := filepath.Join(knuuPath, mount.MountPath)
+		config.Files = append(config.Files, &File{
+			Dest:       mount.MountPath,
+			Chown:      parseInitCommandArg(initCommand, "chown", target),
+			Permission: parseInitCommandArg(initCommand, "chmod", target),
+			Secret:     true,
+		})
+	}
+
+	return config, nil
+}
+
+// parseInitCommandArg returns the argument of the given command (e.g. chown, chmod) applied to the target in the init command
+func parseInitCommandArg(initCommand, command, target string) string {
+	re := regexp.MustCompile(command + ` (\S+) ` + regexp.QuoteMeta(target) + `(\s|$)`)
+	match := re.FindStringSubmatch(initCommand)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// parseRemoteFiles returns the files downloaded by the init command
+func parseRemoteFiles(initCommand string) []*File {
+	re := regexp.MustCompile(`curl -fsSL --retry 5 (--aws-sigv4 '[^']+' --user "\$` + FileCredentialsKey + `" )?-o (\S+) '([^']+)'`)
+	var files []*File
+	for _, match := range re.FindAllStringSubmatch(initCommand, -1) {
+		target := match[2]
+		file := &File{
+			Dest:       strings.TrimPrefix(target, knuuPath),
+			URL:        match[3],
+			Signed:     match[1] != "",
+			Chown:      parseInitCommandArg(initCommand, "chown", target),
+			Permission: parseInitCommandArg(initCommand, "chmod", target),
+		}
+		checksum := regexp.MustCompile(`echo '([0-9a-f]+)  ` + regexp.QuoteMeta(target) + `'`).FindStringSubmatch(initCommand)
+		if checksum != nil {
+			file.Checksum = checksum[1]
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// parseVolumeOwner returns the owner of the volume mounted at the given path from the init command
+func parseVolumeOwner(initCommand, path string) int64 {
+	re := regexp.MustCompile(`chown -R (\d+):\d+ ` + regexp.QuoteMeta(knuuPath+path) + `(\s|$)`)
+	match := re.FindStringSubmatch(initCommand)
+	if match == nil {
+		return 0
+	}
+	owner, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return owner
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
@@ -348,6 +349,284 @@ func (s *TestSuite) TestDeletePod() {
 	}
 }
 
+func (s *TestSuite) TestContainerConfigFromPodSpec() {
+	containerConfig := k8s.ContainerConfig{
+		Name:          "test-container",
+		Image:         "test-image",
+		Command:       []string{"sleep"},
+		Args:          []string{"infinity"},
+		Env:           map[string]string{"FOO": "bar"},
+		MemoryRequest: resource.MustParse("64Mi"),
+		MemoryLimit:   resource.MustParse("128Mi"),
+		CPURequest:    resource.MustParse("100m"),
+		TCPPorts:      []int{8080},
+		UDPPorts:      []int{9090},
+		Volumes: []*k8s.Volume{
+			{Path: "/data", Size: resource.MustParse("1Gi"), Owner: 1000},
+		},
+		Files: []*k8s.File{
+			{Source: "config.toml", Dest: "/etc/app/config.toml", Chown: "1000:1000", Permission: "0644"},
+			{Source: "genesis.json", Dest: "/etc/app/genesis.json", Chown: "0:0", Permission: "0600"},
+		},
+	}
+
+	pod, err := s.client.DeployPod(context.Background(), k8s.PodConfig{
+		Namespace:       s.namespace,
+		Name:            "test-pod",
+		Labels:          map[string]string{"app": "test"},
+		ContainerConfig: containerConfig,
+	}, true)
+	s.Require().NoError(err)
+
+	s.Run("instance container", func() {
+		config, err := k8s.ContainerConfigFromPodSpec(pod.Spec, containerConfig.Name)
+		s.Require().NoError(err)
+
+		s.Assert().Equal(containerConfig.Image, config.Image)
+		s.Assert().Equal(containerConfig.Command, config.Command)
+		s.Assert().Equal(containerConfig.Args, config.Args)
+		s.Assert().Equal(containerConfig.Env, config.Env)
+		s.Assert().True(containerConfig.MemoryRequest.Equal(config.MemoryRequest))
+		s.Assert().True(containerConfig.MemoryLimit.Equal(config.MemoryLimit))
+		s.Assert().True(containerConfig.CPURequest.Equal(config.CPURequest))
+		s.Assert().Equal(containerConfig.TCPPorts, config.TCPPorts)
+		s.Assert().Equal(containerConfig.UDPPorts, config.UDPPorts)
+
+		s.Require().Len(config.Volumes, 1)
+		s.Assert().Equal("/data", config.Volumes[0].Path)
+		s.Assert().Equal(int64(1000), config.Volumes[0].Owner)
+
+		s.Require().Len(config.Files, 2)
+		for idx, file := range containerConfig.Files {
+			s.Assert().Equal(file.Dest, config.Files[idx].Dest)
+			s.Assert().Equal(file.Chown, config.Files[idx].Chown)
+			s.Assert().Equal(file.Permission, config.Files[idx].Permission)
+		}
+	})
+
+	s.Run("container not found", func() {
+		_, err := k8s.ContainerConfigFromPodSpec(pod.Spec, "missing-container")
+		s.Require().Error(err)
+		s.Assert().ErrorIs(err, k8s.ErrContainerNotFoundInPodSpec)
+	})
+}
+
+func (s *TestSuite) TestDeployPodWithRemoteFiles() {
+	const checksum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	containerConfig := k8s.ContainerConfig{
+		Name:  "test-container",
+		Image: "test-image",
+		Files: []*k8s.File{
+			{URL: "http://minio-service.test:9000/knuu-files/test/app/data/snapshot.tar", Signed: true, Checksum: checksum,
+				Source: "snapshot.tar", Dest: "/data/snapshot.tar", Chown: "1000:1000", Permission: "600"},
+			{Source: "config.toml", Dest: "/etc/app/config.toml", Chown: "1000:1000", Permission: "644"},
+		},
+	}
+
+	pod, err := s.client.DeployPod(context.Background(), k8s.PodConfig{
+		Namespace:       s.namespace,
+		Name:            "test-pod",
+		Labels:          map[string]string{"app": "test"},
+		ContainerConfig: containerConfig,
+	}, true)
+	s.Require().NoError(err)
+
+	s.Require().Len(pod.Spec.InitContainers, 1)
+	initContainer := pod.Spec.InitContainers[0]
+	initCommand := initContainer.Command[len(initContainer.Command)-1]
+	// the credentials are read from the secret of the container without tracing the command
+	s.Assert().Contains(initCommand, "set +x && curl -fsSL --retry 5 --aws-sigv4 'aws:amz:us-east-1:s3' --user \"$KNUU_FILE_CREDENTIALS\" "+
+		"-o /knuu/data/snapshot.tar '"+containerConfig.Files[0].URL+"' && set -x && ")
+	s.Assert().Equal([]v1.EnvVar{{
+		Name: k8s.FileCredentialsKey,
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: k8s.ContainerSecretName(containerConfig.Name)},
+			Key:                  k8s.FileCredentialsKey,
+		}},
+	}}, initContainer.Env)
+	s.Assert().Contains(initCommand, "echo '"+checksum+"  /knuu/data/snapshot.tar' | sha256sum -c -")
+	s.Assert().Contains(initCommand, "cp /etc/app/config.toml /knuu/etc/app/config.toml")
+
+	// only the file of the configmap is mounted from it, with the first key
+	var configMapMounts []string
+	for _, mount := range initContainer.VolumeMounts {
+		if mount.Name == containerConfig.Name+"-config" {
+			configMapMounts = append(configMapMounts, mount.MountPath+":"+mount.SubPath)
+		}
+	}
+	s.Assert().Equal([]string{"/etc/app/config.toml:0"}, configMapMounts)
+
+	config, err := k8s.ContainerConfigFromPodSpec(pod.Spec, containerConfig.Name)
+	s.Require().NoError(err)
+	s.Require().Len(config.Files, 2)
+	s.Assert().Equal("/etc/app/config.toml", config.Files[0].Dest)
+	s.Assert().False(config.Files[0].IsRemote())
+	s.Assert().Equal("/data/snapshot.tar", config.Files[1].Dest)
+	s.Assert().Equal(containerConfig.Files[0].URL, config.Files[1].URL)
+	s.Assert().True(config.Files[1].Signed)
+	s.Assert().Equal(checksum, config.Files[1].Checksum)
+	s.Assert().Equal("1000:1000", config.Files[1].Chown)
+	s.Assert().Equal("600", config.Files[1].Permission)
+}
+
+func (s *TestSuite) TestDeployPodWithSharedVolumes() {
+	keyring := &k8s.SharedVolume{
+		Name: "shared-keyring",
+		Path: "/home/app/keyring",
+		Source: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: "shared-keyring",
+		}},
+	}
+	containerConfig := k8s.ContainerConfig{
+		Name:          "test-container",
+		Image:         "test-image",
+		SharedVolumes: []*k8s.SharedVolume{keyring},
+	}
+	sidecarConfig := k8s.ContainerConfig{
+		Name:  "test-sidecar",
+		Image: "test-image",
+		SharedVolumes: []*k8s.SharedVolume{
+			{Name: keyring.Name, Path: "/keyring", ReadOnly: true, Source: keyring.Source},
+		},
+	}
+
+	pod, err := s.client.DeployPod(context.Background(), k8s.PodConfig{
+		Namespace:       s.namespace,
+		Name:            "test-pod",
+		Labels:          map[string]string{"app": "test"},
+		ContainerConfig: containerConfig,
+		SidecarConfigs:  []k8s.ContainerConfig{sidecarConfig},
+	}, true)
+	s.Require().NoError(err)
+
+	// the volume mounted by both containers is added once to the pod
+	s.Require().Len(pod.Spec.Volumes, 1)
+	s.Assert().Equal(keyring.Name, pod.Spec.Volumes[0].Name)
+	s.Assert().Equal(keyring.Source, pod.Spec.Volumes[0].VolumeSource)
+
+	s.Require().Len(pod.Spec.Containers, 2)
+	s.Assert().Equal([]v1.VolumeMount{{Name: keyring.Name, MountPath: "/home/app/keyring"}}, pod.Spec.Containers[0].VolumeMounts)
+	s.Assert().Equal([]v1.VolumeMount{{Name: keyring.Name, MountPath: "/keyring", ReadOnly: true}}, pod.Spec.Containers[1].VolumeMounts)
+
+	config, err := k8s.ContainerConfigFromPodSpec(pod.Spec, sidecarConfig.Name)
+	s.Require().NoError(err)
+	s.Assert().Empty(config.Volumes)
+	s.Assert().Equal(sidecarConfig.SharedVolumes, config.SharedVolumes)
+}
+
+func (s *TestSuite) TestDeployPodWithEphemeralVolume() {
+	containerConfig := k8s.ContainerConfig{
+		Name:  "test-container",
+		Image: "test-image",
+		Volumes: []*k8s.Volume{
+			{Path: "/scratch", Size: resource.MustParse("512Mi"), EmptyDir: true, Medium: v1.StorageMediumMemory},
+		},
+	}
+
+	pod, err := s.client.DeployPod(context.Background(), k8s.PodConfig{
+		Namespace:       s.namespace,
+		Name:            "test-pod",
+		Labels:          map[string]string{"app": "test"},
+		ContainerConfig: containerConfig,
+	}, true)
+	s.Require().NoError(err)
+
+	// the volume is a tmpfs limited to its size instead of a PersistentVolumeClaim
+	s.Require().Len(pod.Spec.Volumes, 1)
+	emptyDir := pod.Spec.Volumes[0].EmptyDir
+	s.Require().NotNil(emptyDir)
+	s.Assert().Equal(v1.StorageMediumMemory, emptyDir.Medium)
+	s.Assert().Equal(resource.MustParse("512Mi"), *emptyDir.SizeLimit)
+
+	config, err := k8s.ContainerConfigFromPodSpec(pod.Spec, containerConfig.Name)
+	s.Require().NoError(err)
+	s.Assert().Equal(containerConfig.Volumes, config.Volumes)
+}
+
+func (s *TestSuite) TestDeployPodWithBlockVolume() {
+	containerConfig := k8s.ContainerConfig{
+		Name:  "test-container",
+		Image: "test-image",
+		Volumes: []*k8s.Volume{
+			{Path: "/dev/xvda", Size: resource.MustParse("10Gi"), StorageClass: "local-ssd", VolumeMode: v1.PersistentVolumeBlock},
+		},
+	}
+
+	pod, err := s.client.DeployPod(context.Background(), k8s.PodConfig{
+		Namespace:       s.namespace,
+		Name:            "test-pod",
+		Labels:          map[string]string{"app": "test"},
+		ContainerConfig: containerConfig,
+	}, true)
+	s.Require().NoError(err)
+
+	// the block volume is attached as a device and not prepared by an init container
+	s.Assert().Empty(pod.Spec.InitContainers)
+	s.Require().Len(pod.Spec.Containers, 1)
+	s.Assert().Empty(pod.Spec.Containers[0].VolumeMounts)
+	s.Assert().Equal([]v1.VolumeDevice{{Name: "test-container", DevicePath: "/dev/xvda"}}, pod.Spec.Containers[0].VolumeDevices)
+
+	config, err := k8s.ContainerConfigFromPodSpec(pod.Spec, containerConfig.Name)
+	s.Require().NoError(err)
+	s.Assert().Equal([]*k8s.Volume{{Path: "/dev/xvda", VolumeMode: v1.PersistentVolumeBlock}}, config.Volumes)
+}
+
+func (s *TestSuite) TestDeployPodWithInvalidVolume() {
+	tests := []struct {
+		name        string
+		volumes     []*k8s.Volume
+		files       []*k8s.File
+		expectedErr error
+	}{
+		{
+			name:        "invalid access mode",
+			volumes:     []*k8s.Volume{{Path: "/data", Size: resource.MustParse("1Gi"), AccessMode: "ReadWriteSometimes"}},
+			expectedErr: k8s.ErrInvalidVolumeAccessMode,
+		},
+		{
+			name:        "emptyDir with storage class",
+			volumes:     []*k8s.Volume{{Path: "/data", Size: resource.MustParse("1Gi"), EmptyDir: true, StorageClass: "fast"}},
+			expectedErr: k8s.ErrEmptyDirVolumeWithClaimOptions,
+		},
+		{
+			name:        "medium without emptyDir",
+			volumes:     []*k8s.Volume{{Path: "/data", Size: resource.MustParse("1Gi"), Medium: v1.StorageMediumMemory}},
+			expectedErr: k8s.ErrVolumeMediumWithoutEmptyDir,
+		},
+		{
+			name: "mixed volumes",
+			volumes: []*k8s.Volume{
+				{Path: "/data", Size: resource.MustParse("1Gi")},
+				{Path: "/scratch", Size: resource.MustParse("1Gi"), EmptyDir: true},
+			},
+			expectedErr: k8s.ErrVolumesKindMismatch,
+		},
+		{
+			name:        "block volume with files",
+			volumes:     []*k8s.Volume{{Path: "/dev/xvda", Size: resource.MustParse("1Gi"), VolumeMode: v1.PersistentVolumeBlock}},
+			files:       []*k8s.File{{Source: "config.toml", Dest: "/home/app/config.toml"}},
+			expectedErr: k8s.ErrBlockVolumeWithFiles,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := s.client.DeployPod(context.Background(), k8s.PodConfig{
+				Namespace: s.namespace,
+				Name:      "test-pod",
+				Labels:    map[string]string{"app": "test"},
+				ContainerConfig: k8s.ContainerConfig{
+					Name:    "test-container",
+					Image:   "test-image",
+					Volumes: tt.volumes,
+					Files:   tt.files,
+				},
+			}, true)
+			s.Assert().ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
+
 func (s *TestSuite) TestPortForwardPod() {
 	s.T().Skip("not implemented")
 	// TestPortForwardPod is not implemented.
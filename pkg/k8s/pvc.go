@@ -15,12 +15,37 @@ func (c *Client) PersistentVolumeClaimExists(ctx context.Context, name string) (
 	return err == nil, nil
 }
 
+// PersistentVolumeClaimOption configures a PersistentVolumeClaim before its creation
+type PersistentVolumeClaimOption func(*v1.PersistentVolumeClaim)
+
+// WithAccessModes sets the access modes of the PersistentVolumeClaim, ReadWriteOnce by default
+func WithAccessModes(accessModes ...v1.PersistentVolumeAccessMode) PersistentVolumeClaimOption {
+	return func(pvc *v1.PersistentVolumeClaim) {
+		pvc.Spec.AccessModes = accessModes
+	}
+}
+
+// WithStorageClass sets the StorageClass of the PersistentVolumeClaim, the default class of the cluster is used otherwise
+func WithStorageClass(storageClass string) PersistentVolumeClaimOption {
+	return func(pvc *v1.PersistentVolumeClaim) {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+}
+
+// WithVolumeMode sets the volume mode of the PersistentVolumeClaim, Filesystem by default
+func WithVolumeMode(volumeMode v1.PersistentVolumeMode) PersistentVolumeClaimOption {
+	return func(pvc *v1.PersistentVolumeClaim) {
+		pvc.Spec.VolumeMode = &volumeMode
+	}
+}
+
 // CreatePersistentVolumeClaim deploys a PersistentVolumeClaim if it does not exist.
 func (c *Client) CreatePersistentVolumeClaim(
 	ctx context.Context,
 	name string,
 	labels map[string]string,
 	size resource.Quantity,
+	opts ...PersistentVolumeClaimOption,
 ) error {
 	if c.terminated {
 		return ErrClientTerminated
@@ -52,6 +77,9 @@ func (c *Client) CreatePersistentVolumeClaim(
 			},
 		},
 	}
+	for _, opt := range opts {
+		opt(pvc)
+	}
 
 	if _, err := c.clientset.CoreV1().PersistentVolumeClaims(c.namespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
 		return ErrCreatingPersistentVolumeClaim.WithParams(name).Wrap(err)
@@ -79,6 +107,31 @@ func (c *Client) DeletePersistentVolumeClaim(ctx context.Context, name string) e
 	return nil
 }
 
+// DeletePersistentVolumeClaimsByLabels deletes all the PersistentVolumeClaims matching the given labels.
+// It is used to clean up the claims created from the volumeClaimTemplates of a StatefulSet.
+func (c *Client) DeletePersistentVolumeClaimsByLabels(ctx context.Context, labels map[string]string) error {
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+
+	selector := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: labels})
+	err := c.clientset.CoreV1().PersistentVolumeClaims(c.namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return ErrDeletingPersistentVolumeClaims.WithParams(selector).Wrap(err)
+	}
+
+	c.logger.WithField("selector", selector).Debug("PersistentVolumeClaims deleted")
+	return nil
+}
+
+// GetPersistentVolumeClaim returns the PersistentVolumeClaim with the given name
+func (c *Client) GetPersistentVolumeClaim(ctx context.Context, name string) (*v1.PersistentVolumeClaim, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	return c.getPersistentVolumeClaim(ctx, name)
+}
+
 func (c *Client) getPersistentVolumeClaim(ctx context.Context, name string) (*v1.PersistentVolumeClaim, error) {
 	return c.clientset.CoreV1().PersistentVolumeClaims(c.namespace).Get(ctx, name, metav1.GetOptions{})
 }
@@ -62,6 +62,37 @@ func (s *TestSuite) TestCreatePersistentVolumeClaim() {
 	}
 }
 
+func (s *TestSuite) TestCreatePersistentVolumeClaimWithAccessModes() {
+	err := s.client.CreatePersistentVolumeClaim(context.Background(), "shared-pvc", map[string]string{"app": "test"},
+		resource.MustParse("1Gi"), k8s.WithAccessModes(v1.ReadWriteMany))
+	s.Require().NoError(err)
+
+	pvc, err := s.client.Clientset().CoreV1().PersistentVolumeClaims(s.namespace).Get(context.Background(), "shared-pvc", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Assert().Equal([]v1.PersistentVolumeAccessMode{v1.ReadWriteMany}, pvc.Spec.AccessModes)
+}
+
+func (s *TestSuite) TestCreatePersistentVolumeClaimWithVolumeOptions() {
+	volumes := []*k8s.Volume{{
+		Path:         "/data",
+		Size:         resource.MustParse("1Gi"),
+		StorageClass: "local-ssd",
+		AccessMode:   v1.ReadWriteOncePod,
+		VolumeMode:   v1.PersistentVolumeBlock,
+	}}
+	err := s.client.CreatePersistentVolumeClaim(context.Background(), "ssd-pvc", map[string]string{"app": "test"},
+		resource.MustParse("1Gi"), k8s.VolumeClaimOptions(volumes)...)
+	s.Require().NoError(err)
+
+	pvc, err := s.client.Clientset().CoreV1().PersistentVolumeClaims(s.namespace).Get(context.Background(), "ssd-pvc", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Require().NotNil(pvc.Spec.StorageClassName)
+	s.Assert().Equal("local-ssd", *pvc.Spec.StorageClassName)
+	s.Assert().Equal([]v1.PersistentVolumeAccessMode{v1.ReadWriteOncePod}, pvc.Spec.AccessModes)
+	s.Require().NotNil(pvc.Spec.VolumeMode)
+	s.Assert().Equal(v1.PersistentVolumeBlock, *pvc.Spec.VolumeMode)
+}
+
 func (s *TestSuite) TestDeletePersistentVolumeClaim() {
 	tests := []struct {
 		name        string
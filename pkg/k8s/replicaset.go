@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -120,6 +121,71 @@ func (c *Client) GetFirstPodFromReplicaSet(ctx context.Context, name string) (*v
 	return c.getPod(ctx, pods.Items[0].Name)
 }
 
+// GetPodsFromReplicaSet returns the running (not terminating) pods of the given ReplicaSet.
+// The pods are sorted by creation time and name, so the index of a pod can be used as the replica index.
+// Note: ReplicaSet pods do not have a stable identity, so the index of a replica may change after scaling.
+func (c *Client) GetPodsFromReplicaSet(ctx context.Context, name string) ([]v1.Pod, error) {
+	rs, err := c.getReplicaSet(ctx, name)
+	if err != nil {
+		return nil, ErrGettingReplicaSet.WithParams(name).Wrap(err)
+	}
+	selector := metav1.FormatLabelSelector(rs.Spec.Selector)
+	pods, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, ErrListingPodsForReplicaSet.WithParams(name).Wrap(err)
+	}
+
+	activePods := make([]v1.Pod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		activePods = append(activePods, pod)
+	}
+	sortPods(activePods)
+	return activePods, nil
+}
+
+// ScaleReplicaSet sets the number of desired replicas of the given ReplicaSet.
+func (c *Client) ScaleReplicaSet(ctx context.Context, name string, replicas int32) error {
+	if replicas < 0 {
+		return ErrReplicaSetReplicasNegative.WithParams(replicas)
+	}
+
+	rs, err := c.getReplicaSet(ctx, name)
+	if err != nil {
+		return ErrGettingReplicaSet.WithParams(name).Wrap(err)
+	}
+
+	rs.Spec.Replicas = &replicas
+	if _, err := c.clientset.AppsV1().ReplicaSets(c.namespace).Update(ctx, rs, metav1.UpdateOptions{}); err != nil {
+		return ErrScalingReplicaSet.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":     name,
+		"replicas": replicas,
+	}).Debug("scaled replicaSet")
+	return nil
+}
+
+// ListReplicaSets returns the ReplicaSets matching the given labels.
+func (c *Client) ListReplicaSets(ctx context.Context, labels map[string]string) ([]appv1.ReplicaSet, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateLabels(labels); err != nil {
+		return nil, err
+	}
+
+	selector := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: labels})
+	rsList, err := c.clientset.AppsV1().ReplicaSets(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, ErrListingReplicaSets.WithParams(selector).Wrap(err)
+	}
+	return rsList.Items, nil
+}
+
 func (c *Client) getReplicaSet(ctx context.Context, name string) (*appv1.ReplicaSet, error) {
 	if c.terminated {
 		return nil, ErrClientTerminated
@@ -187,3 +253,14 @@ func (c *Client) prepareReplicaSet(rsConf ReplicaSetConfig, init bool) *appv1.Re
 	}).Debug("prepared replicaSet")
 	return rs
 }
+
+// sortPods sorts the pods by creation time and name to get a deterministic order.
+func sortPods(pods []v1.Pod) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		ti, tj := pods[i].CreationTimestamp, pods[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
@@ -2,8 +2,10 @@ package k8s_test
 
 import (
 	"context"
+	"time"
 
 	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
@@ -477,6 +479,122 @@ func (s *TestSuite) TestDeleteReplicaSet() {
 	}
 }
 
+func (s *TestSuite) TestGetPodsFromReplicaSet() {
+	tests := []struct {
+		name         string
+		rsName       string
+		setupMock    func()
+		expectedPods []string
+		expectedErr  error
+	}{
+		{
+			name:   "pods sorted by creation time",
+			rsName: "test-rs",
+			setupMock: func() {
+				s.Require().NoError(s.createReplicaSetWithSelector("test-rs", map[string]string{"app": "test"}))
+				now := time.Now()
+				s.Require().NoError(s.createReplicaSetPod("test-rs-b", map[string]string{"app": "test"}, now, false))
+				s.Require().NoError(s.createReplicaSetPod("test-rs-a", map[string]string{"app": "test"}, now.Add(time.Minute), false))
+				s.Require().NoError(s.createReplicaSetPod("test-rs-c", map[string]string{"app": "test"}, now, false))
+				s.Require().NoError(s.createReplicaSetPod("test-rs-terminating", map[string]string{"app": "test"}, now, true))
+				s.Require().NoError(s.createReplicaSetPod("other-pod", map[string]string{"app": "other"}, now, false))
+			},
+			expectedPods: []string{"test-rs-b", "test-rs-c", "test-rs-a"},
+		},
+		{
+			name:        "replica set not found",
+			rsName:      "missing-rs",
+			setupMock:   func() {},
+			expectedErr: k8s.ErrGettingReplicaSet,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			pods, err := s.client.GetPodsFromReplicaSet(context.Background(), tt.rsName)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			names := make([]string, 0, len(pods))
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			s.Assert().Equal(tt.expectedPods, names)
+		})
+	}
+}
+
+func (s *TestSuite) TestScaleReplicaSet() {
+	tests := []struct {
+		name        string
+		rsName      string
+		replicas    int32
+		setupMock   func()
+		expectedErr error
+	}{
+		{
+			name:     "successful scaling",
+			rsName:   "test-rs",
+			replicas: 3,
+			setupMock: func() {
+				s.Require().NoError(s.createReplicaSet("test-rs"))
+			},
+		},
+		{
+			name:        "negative replicas",
+			rsName:      "test-rs",
+			replicas:    -1,
+			setupMock:   func() {},
+			expectedErr: k8s.ErrReplicaSetReplicasNegative,
+		},
+		{
+			name:        "replica set not found",
+			rsName:      "missing-rs",
+			replicas:    2,
+			setupMock:   func() {},
+			expectedErr: k8s.ErrGettingReplicaSet,
+		},
+		{
+			name:     "client error on update",
+			rsName:   "error-rs",
+			replicas: 2,
+			setupMock: func() {
+				s.Require().NoError(s.createReplicaSet("error-rs"))
+				s.client.Clientset().(*fake.Clientset).
+					PrependReactor("update", "replicasets",
+						func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+							return true, nil, errInternalServerError
+						})
+			},
+			expectedErr: k8s.ErrScalingReplicaSet.Wrap(errInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			err := s.client.ScaleReplicaSet(context.Background(), tt.rsName, tt.replicas)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			rs, err := s.client.Clientset().AppsV1().ReplicaSets(s.namespace).Get(context.Background(), tt.rsName, metav1.GetOptions{})
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.replicas, *rs.Spec.Replicas)
+		})
+	}
+}
+
 func (s *TestSuite) createReplicaSet(name string) error {
 	_, err := s.client.Clientset().AppsV1().ReplicaSets(s.namespace).Create(context.Background(), &appv1.ReplicaSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -486,3 +604,33 @@ func (s *TestSuite) createReplicaSet(name string) error {
 	}, metav1.CreateOptions{})
 	return err
 }
+
+func (s *TestSuite) createReplicaSetWithSelector(name string, labels map[string]string) error {
+	_, err := s.client.Clientset().AppsV1().ReplicaSets(s.namespace).Create(context.Background(), &appv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.namespace,
+			Labels:    labels,
+		},
+		Spec: appv1.ReplicaSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}, metav1.CreateOptions{})
+	return err
+}
+
+func (s *TestSuite) createReplicaSetPod(name string, labels map[string]string, createdAt time.Time, terminating bool) error {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         s.namespace,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(createdAt),
+		},
+	}
+	if terminating {
+		pod.DeletionTimestamp = ptr.To(metav1.NewTime(createdAt))
+	}
+	_, err := s.client.Clientset().CoreV1().Pods(s.namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+	return err
+}
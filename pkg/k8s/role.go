@@ -40,6 +40,13 @@ func (c *Client) CreateRole(
 	return err
 }
 
+func (c *Client) GetRole(ctx context.Context, name string) (*rbacv1.Role, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	return c.clientset.RbacV1().Roles(c.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
 func (c *Client) DeleteRole(ctx context.Context, name string) error {
 	return c.clientset.RbacV1().Roles(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
 }
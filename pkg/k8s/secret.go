@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (c *Client) GetSecret(ctx context.Context, name string) (*v1.Secret, error) {
+	secret, err := c.clientset.CoreV1().Secrets(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, ErrGettingSecret.WithParams(name).Wrap(err)
+	}
+	return secret, nil
+}
+
+// CreateOrUpdateSecret creates the secret or replaces the data of the existing one
+func (c *Client) CreateOrUpdateSecret(
+	ctx context.Context, name string,
+	labels map[string]string, secretType v1.SecretType, data map[string][]byte,
+) (*v1.Secret, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateSecret(name, labels, data); err != nil {
+		return nil, err
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.namespace,
+			Labels:    labels,
+		},
+		Type: secretType,
+		Data: data,
+	}
+
+	secrets := c.clientset.CoreV1().Secrets(c.namespace)
+	existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return nil, ErrGettingSecret.WithParams(name).Wrap(err)
+		}
+		created, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+		if err != nil {
+			return nil, ErrCreatingSecret.WithParams(name).Wrap(err)
+		}
+		c.logger.WithFields(logrus.Fields{
+			"name":      name,
+			"namespace": c.namespace,
+		}).Debug("secret created")
+		return created, nil
+	}
+
+	secret.ResourceVersion = existing.ResourceVersion
+	updated, err := secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, ErrUpdatingSecret.WithParams(name).Wrap(err)
+	}
+	c.logger.WithFields(logrus.Fields{
+		"name":      name,
+		"namespace": c.namespace,
+	}).Debug("secret updated")
+	return updated, nil
+}
+
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	err := c.clientset.CoreV1().Secrets(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		return ErrDeletingSecret.WithParams(name).Wrap(err)
+	}
+	c.logger.WithFields(logrus.Fields{
+		"name":      name,
+		"namespace": c.namespace,
+	}).Debug("secret deleted")
+	return nil
+}
@@ -0,0 +1,59 @@
+package k8s_test
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+func (s *TestSuite) TestCreateOrUpdateSecret() {
+	ctx := context.Background()
+	labels := map[string]string{"app": "test"}
+
+	secret, err := s.client.CreateOrUpdateSecret(ctx, "test-secret", labels,
+		v1.SecretTypeDockerConfigJson, map[string][]byte{v1.DockerConfigJsonKey: []byte(`{"auths":{}}`)})
+	s.Require().NoError(err)
+	s.Assert().Equal(v1.SecretTypeDockerConfigJson, secret.Type)
+
+	_, err = s.client.CreateOrUpdateSecret(ctx, "test-secret", labels,
+		v1.SecretTypeDockerConfigJson, map[string][]byte{v1.DockerConfigJsonKey: []byte(`{"auths":{"ghcr.io":{}}}`)})
+	s.Require().NoError(err)
+
+	secret, err = s.client.GetSecret(ctx, "test-secret")
+	s.Require().NoError(err)
+	s.Assert().Equal(`{"auths":{"ghcr.io":{}}}`, string(secret.Data[v1.DockerConfigJsonKey]))
+
+	_, err = s.client.CreateOrUpdateSecret(ctx, "invalid_name", labels, v1.SecretTypeOpaque, nil)
+	s.Assert().ErrorIs(err, k8s.ErrInvalidSecretName)
+
+	_, err = s.client.CreateOrUpdateSecret(ctx, "test-secret", labels, v1.SecretTypeOpaque, map[string][]byte{"invalid/key": nil})
+	s.Assert().ErrorIs(err, k8s.ErrInvalidSecretKey)
+
+	s.client.Clientset().(*fake.Clientset).
+		PrependReactor("create", "secrets",
+			func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+				return true, nil, errInternalServerError
+			})
+	_, err = s.client.CreateOrUpdateSecret(ctx, "error-secret", labels, v1.SecretTypeOpaque, nil)
+	s.Assert().ErrorIs(err, k8s.ErrCreatingSecret)
+}
+
+func (s *TestSuite) TestDeleteSecret() {
+	ctx := context.Background()
+
+	_, err := s.client.CreateOrUpdateSecret(ctx, "test-secret", nil, v1.SecretTypeOpaque, nil)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.client.DeleteSecret(ctx, "test-secret"))
+
+	_, err = s.client.GetSecret(ctx, "test-secret")
+	s.Assert().ErrorIs(err, k8s.ErrGettingSecret)
+
+	err = s.client.DeleteSecret(ctx, "test-secret")
+	s.Assert().ErrorIs(err, k8s.ErrDeletingSecret)
+}
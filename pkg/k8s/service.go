@@ -19,6 +19,9 @@ type ServiceOptions struct {
 	TCPPorts    []int
 	UDPPorts    []int
 	NotHeadless bool
+	// PortsOptional allows a headless service without ports, which only provides the DNS records of its pods,
+	// e.g. the governing service of a StatefulSet
+	PortsOptional bool
 }
 
 func (c *Client) GetService(ctx context.Context, name string) (*v1.Service, error) {
@@ -349,7 +352,7 @@ func prepareService(namespace, name string, opts ServiceOptions) (*v1.Service, e
 	}
 
 	servicePorts := buildPorts(opts.TCPPorts, opts.UDPPorts)
-	if len(servicePorts) == 0 {
+	if len(servicePorts) == 0 && (!opts.PortsOptional || opts.NotHeadless) {
 		return nil, ErrNoPortsSpecified.WithParams(name)
 	}
 
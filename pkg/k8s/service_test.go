@@ -136,6 +136,26 @@ func (s *TestSuite) TestCreateService() {
 	}
 }
 
+func (s *TestSuite) TestCreateServiceWithoutPorts() {
+	opts := k8s.ServiceOptions{
+		Labels:      map[string]string{"app": "test"},
+		SelectorMap: map[string]string{"app": "test"},
+	}
+	_, err := s.client.CreateService(context.Background(), "no-ports", opts)
+	s.Require().ErrorIs(err, k8s.ErrPreparingService)
+
+	// a headless service does not need ports to provide the DNS records of its pods
+	opts.PortsOptional = true
+	svc, err := s.client.CreateService(context.Background(), "no-ports", opts)
+	s.Require().NoError(err)
+	s.Assert().Equal(v1.ClusterIPNone, svc.Spec.ClusterIP)
+	s.Assert().Empty(svc.Spec.Ports)
+
+	opts.NotHeadless = true
+	_, err = s.client.CreateService(context.Background(), "no-ports-cluster-ip", opts)
+	s.Require().ErrorIs(err, k8s.ErrPreparingService)
+}
+
 func (s *TestSuite) TestPatchService() {
 	tests := []struct {
 		name        string
@@ -0,0 +1,275 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+type StatefulSetConfig struct {
+	Name        string            // Name of the StatefulSet
+	Namespace   string            // Namespace of the StatefulSet
+	Labels      map[string]string // Labels to apply to the StatefulSet, its pods and its volume claims
+	Replicas    int32             // Replicas is the number of replicas
+	ServiceName string            // ServiceName is the name of the headless service that gives the pods a stable network identity
+	PodConfig   PodConfig         // PodConfig represents the pod configuration
+}
+
+// CreateStatefulSet creates a new StatefulSet in the namespace that k8s is initialized with.
+// The volumes of the containers are created per replica through volumeClaimTemplates,
+// so every replica gets its own PersistentVolumeClaim that survives restarts of the StatefulSet.
+func (c *Client) CreateStatefulSet(ctx context.Context, ssConfig StatefulSetConfig, init bool) (*appv1.StatefulSet, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateStatefulSetConfig(ssConfig); err != nil {
+		return nil, err
+	}
+	ssConfig.Namespace = c.namespace
+	ss := c.prepareStatefulSet(ssConfig, init)
+
+	createdSs, err := c.clientset.AppsV1().StatefulSets(c.namespace).Create(ctx, ss, metav1.CreateOptions{})
+	if err != nil {
+		return nil, ErrCreatingStatefulSet.Wrap(err)
+	}
+
+	return createdSs, nil
+}
+
+func (c *Client) IsStatefulSetRunning(ctx context.Context, name string) (bool, error) {
+	ss, err := c.getStatefulSet(ctx, name)
+	if err != nil {
+		return false, ErrGettingStatefulSet.WithParams(name).Wrap(err)
+	}
+
+	return ss.Spec.Replicas != nil && ss.Status.ReadyReplicas == *ss.Spec.Replicas, nil
+}
+
+func (c *Client) DeleteStatefulSetWithGracePeriod(ctx context.Context, name string, gracePeriodSeconds *int64) error {
+	exists, err := c.StatefulSetExists(ctx, name)
+	if err != nil {
+		return ErrCheckingStatefulSetExists.WithParams(name).Wrap(err)
+	}
+	if !exists {
+		return nil
+	}
+	if gracePeriodSeconds == nil {
+		gracePeriodSeconds = ptr.To[int64](0)
+	}
+
+	delOpts := metav1.DeleteOptions{
+		GracePeriodSeconds: gracePeriodSeconds,
+	}
+	if err := c.clientset.AppsV1().StatefulSets(c.namespace).Delete(ctx, name, delOpts); err != nil {
+		return ErrDeletingStatefulSet.WithParams(name).Wrap(err)
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteStatefulSet(ctx context.Context, name string) error {
+	return c.DeleteStatefulSetWithGracePeriod(ctx, name, nil)
+}
+
+// GetPodsFromStatefulSet returns the running (not terminating) pods of the given StatefulSet sorted by their ordinal.
+func (c *Client) GetPodsFromStatefulSet(ctx context.Context, name string) ([]v1.Pod, error) {
+	ss, err := c.getStatefulSet(ctx, name)
+	if err != nil {
+		return nil, ErrGettingStatefulSet.WithParams(name).Wrap(err)
+	}
+	selector := metav1.FormatLabelSelector(ss.Spec.Selector)
+	pods, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, ErrListingPodsForStatefulSet.WithParams(name).Wrap(err)
+	}
+
+	activePods := make([]v1.Pod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		activePods = append(activePods, pod)
+	}
+	sort.SliceStable(activePods, func(i, j int) bool {
+		return podOrdinal(activePods[i].Name) < podOrdinal(activePods[j].Name)
+	})
+	return activePods, nil
+}
+
+// ScaleStatefulSet sets the number of desired replicas of the given StatefulSet.
+func (c *Client) ScaleStatefulSet(ctx context.Context, name string, replicas int32) error {
+	if replicas < 0 {
+		return ErrStatefulSetReplicasNegative.WithParams(replicas)
+	}
+
+	ss, err := c.getStatefulSet(ctx, name)
+	if err != nil {
+		return ErrGettingStatefulSet.WithParams(name).Wrap(err)
+	}
+
+	ss.Spec.Replicas = &replicas
+	if _, err := c.clientset.AppsV1().StatefulSets(c.namespace).Update(ctx, ss, metav1.UpdateOptions{}); err != nil {
+		return ErrScalingStatefulSet.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":     name,
+		"replicas": replicas,
+	}).Debug("scaled statefulSet")
+	return nil
+}
+
+// StatefulSetExists checks if a StatefulSet exists in the namespace that k8s is initialized with.
+func (c *Client) StatefulSetExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.getStatefulSet(ctx, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, ErrGettingStatefulSet.WithParams(name).Wrap(err)
+	}
+
+	return true, nil
+}
+
+// ListStatefulSets returns the StatefulSets matching the given labels.
+func (c *Client) ListStatefulSets(ctx context.Context, labels map[string]string) ([]appv1.StatefulSet, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	if err := validateLabels(labels); err != nil {
+		return nil, err
+	}
+
+	selector := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: labels})
+	ssList, err := c.clientset.AppsV1().StatefulSets(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, ErrListingStatefulSets.WithParams(selector).Wrap(err)
+	}
+	return ssList.Items, nil
+}
+
+func (c *Client) getStatefulSet(ctx context.Context, name string) (*appv1.StatefulSet, error) {
+	if c.terminated {
+		return nil, ErrClientTerminated
+	}
+	return c.clientset.AppsV1().StatefulSets(c.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// prepareStatefulSet prepares a StatefulSet configuration.
+func (c *Client) prepareStatefulSet(ssConf StatefulSetConfig, init bool) *appv1.StatefulSet {
+	podSpec := c.preparePodSpec(ssConf.PodConfig, init)
+	claimTemplates := prepareVolumeClaimTemplates(ssConf)
+
+	// The volumes backed by a PVC are replaced by the volumeClaimTemplates with the same name
+	podVolumes := make([]v1.Volume, 0, len(podSpec.Volumes))
+	for _, vol := range podSpec.Volumes {
+		if vol.PersistentVolumeClaim != nil && hasClaimTemplate(claimTemplates, vol.Name) {
+			continue
+		}
+		podVolumes = append(podVolumes, vol)
+	}
+	podSpec.Volumes = podVolumes
+
+	ss := &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ssConf.Namespace,
+			Name:      ssConf.Name,
+			Labels:    ssConf.Labels,
+		},
+		Spec: appv1.StatefulSetSpec{
+			Replicas:            &ssConf.Replicas,
+			ServiceName:         ssConf.ServiceName,
+			PodManagementPolicy: appv1.OrderedReadyPodManagement,
+			Selector:            &metav1.LabelSelector{MatchLabels: ssConf.Labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   ssConf.Namespace,
+					Labels:      ssConf.Labels,
+					Annotations: ssConf.PodConfig.Annotations,
+				},
+				Spec: podSpec,
+			},
+			VolumeClaimTemplates: claimTemplates,
+		},
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"name":      ssConf.Name,
+		"namespace": ssConf.Namespace,
+	}).Debug("prepared statefulSet")
+	return ss
+}
+
+// prepareVolumeClaimTemplates creates one volumeClaimTemplate for every container that has persistent volumes.
+// The template is named after the container, as it is the name used for the volume mounts.
+func prepareVolumeClaimTemplates(ssConf StatefulSetConfig) []v1.PersistentVolumeClaim {
+	containers := append([]ContainerConfig{ssConf.PodConfig.ContainerConfig}, ssConf.PodConfig.SidecarConfigs...)
+
+	var templates []v1.PersistentVolumeClaim
+	for _, container := range containers {
+		// the ephemeral volumes are emptyDirs of the pod
+		if len(container.Volumes) == 0 || container.Volumes[0].EmptyDir {
+			continue
+		}
+
+		totalSize := resource.Quantity{}
+		for _, volume := range container.Volumes {
+			totalSize.Add(volume.Size)
+		}
+
+		template := v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   container.Name,
+				Labels: ssConf.Labels,
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{
+					v1.ReadWriteOnce,
+				},
+				Resources: v1.VolumeResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceStorage: totalSize,
+					},
+				},
+			},
+		}
+		for _, opt := range VolumeClaimOptions(container.Volumes) {
+			opt(&template)
+		}
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+func hasClaimTemplate(templates []v1.PersistentVolumeClaim, name string) bool {
+	for _, t := range templates {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// podOrdinal returns the ordinal of a StatefulSet pod from its name (<statefulset-name>-<ordinal>).
+// If the name does not end with an ordinal, -1 is returned.
+func podOrdinal(podName string) int {
+	idx := strings.LastIndex(podName, "-")
+	if idx == -1 {
+		return -1
+	}
+	ordinal, err := strconv.Atoi(podName[idx+1:])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
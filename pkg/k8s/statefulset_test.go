@@ -0,0 +1,405 @@
+package k8s_test
+
+import (
+	"context"
+	"time"
+
+	appv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+func (s *TestSuite) TestCreateStatefulSet() {
+	tests := []struct {
+		name              string
+		ssConfig          k8s.StatefulSetConfig
+		setupMock         func()
+		expectedTemplates []string
+		expectedErr       error
+	}{
+		{
+			name: "successful creation with volumes",
+			ssConfig: k8s.StatefulSetConfig{
+				Name:        "test-ss",
+				Namespace:   s.namespace,
+				Labels:      map[string]string{"app": "test"},
+				Replicas:    2,
+				ServiceName: "test-ss",
+				PodConfig: k8s.PodConfig{
+					Namespace: s.namespace,
+					Name:      "test-pod",
+					Labels:    map[string]string{"app": "test"},
+					ContainerConfig: k8s.ContainerConfig{
+						Name:  "test-container",
+						Image: "test-image",
+						Volumes: []*k8s.Volume{
+							{Path: "/data", Size: resource.MustParse("1Gi")},
+						},
+					},
+				},
+			},
+			setupMock:         func() {},
+			expectedTemplates: []string{"test-container"},
+		},
+		{
+			name: "successful creation with ephemeral volumes",
+			ssConfig: k8s.StatefulSetConfig{
+				Name:        "ephemeral-ss",
+				Namespace:   s.namespace,
+				Labels:      map[string]string{"app": "test"},
+				Replicas:    1,
+				ServiceName: "ephemeral-ss",
+				PodConfig: k8s.PodConfig{
+					Namespace: s.namespace,
+					Name:      "test-pod",
+					Labels:    map[string]string{"app": "test"},
+					ContainerConfig: k8s.ContainerConfig{
+						Name:  "test-container",
+						Image: "test-image",
+						Volumes: []*k8s.Volume{
+							{Path: "/scratch", Size: resource.MustParse("1Gi"), EmptyDir: true},
+						},
+					},
+				},
+			},
+			setupMock:         func() {},
+			expectedTemplates: []string{},
+		},
+		{
+			name: "invalid name",
+			ssConfig: k8s.StatefulSetConfig{
+				Name:      "Invalid_Name",
+				Namespace: s.namespace,
+				Labels:    map[string]string{"app": "test"},
+				Replicas:  1,
+			},
+			setupMock:   func() {},
+			expectedErr: k8s.ErrInvalidStatefulSetName,
+		},
+		{
+			name: "client error",
+			ssConfig: k8s.StatefulSetConfig{
+				Name:      "error-ss",
+				Namespace: s.namespace,
+				Labels:    map[string]string{"app": "error"},
+				Replicas:  1,
+				PodConfig: k8s.PodConfig{
+					Namespace:       s.namespace,
+					Name:            "error-pod",
+					Labels:          map[string]string{"app": "error"},
+					ContainerConfig: testContainerConfig,
+				},
+			},
+			setupMock: func() {
+				s.client.Clientset().(*fake.Clientset).
+					PrependReactor("create", "statefulsets",
+						func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+							return true, nil, errInternalServerError
+						})
+			},
+			expectedErr: k8s.ErrCreatingStatefulSet.Wrap(errInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			ss, err := s.client.CreateStatefulSet(context.Background(), tt.ssConfig, false)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.ssConfig.Name, ss.Name)
+			s.Assert().Equal(tt.ssConfig.ServiceName, ss.Spec.ServiceName)
+
+			templates := make([]string, 0, len(ss.Spec.VolumeClaimTemplates))
+			for _, t := range ss.Spec.VolumeClaimTemplates {
+				templates = append(templates, t.Name)
+			}
+			s.Assert().Equal(tt.expectedTemplates, templates)
+
+			for _, vol := range ss.Spec.Template.Spec.Volumes {
+				s.Assert().Nil(vol.PersistentVolumeClaim, "pod volume %s must be provided by a claim template", vol.Name)
+			}
+		})
+	}
+}
+
+func (s *TestSuite) TestCreateStatefulSetWithStorageClass() {
+	ss, err := s.client.CreateStatefulSet(context.Background(), k8s.StatefulSetConfig{
+		Name:        "ssd-ss",
+		Namespace:   s.namespace,
+		Labels:      map[string]string{"app": "test"},
+		Replicas:    1,
+		ServiceName: "ssd-ss",
+		PodConfig: k8s.PodConfig{
+			Namespace: s.namespace,
+			Name:      "test-pod",
+			Labels:    map[string]string{"app": "test"},
+			ContainerConfig: k8s.ContainerConfig{
+				Name:  "test-container",
+				Image: "test-image",
+				Volumes: []*k8s.Volume{
+					{Path: "/data", Size: resource.MustParse("1Gi"), StorageClass: "local-ssd"},
+				},
+			},
+		},
+	}, false)
+	s.Require().NoError(err)
+
+	s.Require().Len(ss.Spec.VolumeClaimTemplates, 1)
+	s.Assert().Equal(ptr.To("local-ssd"), ss.Spec.VolumeClaimTemplates[0].Spec.StorageClassName)
+}
+
+func (s *TestSuite) TestIsStatefulSetRunning() {
+	tests := []struct {
+		name        string
+		ssName      string
+		setupMock   func()
+		expected    bool
+		expectedErr error
+	}{
+		{
+			name:   "statefulset is running",
+			ssName: "running-ss",
+			setupMock: func() {
+				_, err := s.client.Clientset().AppsV1().StatefulSets(s.namespace).Create(context.Background(), &appv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "running-ss", Namespace: s.namespace},
+					Spec:       appv1.StatefulSetSpec{Replicas: ptr.To[int32](2)},
+					Status:     appv1.StatefulSetStatus{ReadyReplicas: 2},
+				}, metav1.CreateOptions{})
+				s.Require().NoError(err)
+			},
+			expected: true,
+		},
+		{
+			name:   "statefulset is not running",
+			ssName: "pending-ss",
+			setupMock: func() {
+				_, err := s.client.Clientset().AppsV1().StatefulSets(s.namespace).Create(context.Background(), &appv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "pending-ss", Namespace: s.namespace},
+					Spec:       appv1.StatefulSetSpec{Replicas: ptr.To[int32](2)},
+					Status:     appv1.StatefulSetStatus{ReadyReplicas: 1},
+				}, metav1.CreateOptions{})
+				s.Require().NoError(err)
+			},
+			expected: false,
+		},
+		{
+			name:        "statefulset not found",
+			ssName:      "missing-ss",
+			setupMock:   func() {},
+			expectedErr: k8s.ErrGettingStatefulSet,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			running, err := s.client.IsStatefulSetRunning(context.Background(), tt.ssName)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.expected, running)
+		})
+	}
+}
+
+func (s *TestSuite) TestDeleteStatefulSet() {
+	tests := []struct {
+		name        string
+		ssName      string
+		setupMock   func()
+		expectedErr error
+	}{
+		{
+			name:   "successful deletion",
+			ssName: "test-ss",
+			setupMock: func() {
+				s.Require().NoError(s.createStatefulSet("test-ss", map[string]string{"app": "test"}))
+			},
+		},
+		{
+			name:      "statefulset does not exist",
+			ssName:    "missing-ss",
+			setupMock: func() {},
+		},
+		{
+			name:   "client error on delete",
+			ssName: "error-ss",
+			setupMock: func() {
+				s.Require().NoError(s.createStatefulSet("error-ss", map[string]string{"app": "error"}))
+				s.client.Clientset().(*fake.Clientset).
+					PrependReactor("delete", "statefulsets",
+						func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+							return true, nil, errInternalServerError
+						})
+			},
+			expectedErr: k8s.ErrDeletingStatefulSet.WithParams("error-ss").Wrap(errInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			err := s.client.DeleteStatefulSet(context.Background(), tt.ssName)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			exists, err := s.client.StatefulSetExists(context.Background(), tt.ssName)
+			s.Require().NoError(err)
+			s.Assert().False(exists)
+		})
+	}
+}
+
+func (s *TestSuite) TestGetPodsFromStatefulSet() {
+	tests := []struct {
+		name         string
+		ssName       string
+		setupMock    func()
+		expectedPods []string
+		expectedErr  error
+	}{
+		{
+			name:   "pods sorted by ordinal",
+			ssName: "test-ss",
+			setupMock: func() {
+				labels := map[string]string{"app": "test"}
+				s.Require().NoError(s.createStatefulSet("test-ss", labels))
+				now := time.Now()
+				s.Require().NoError(s.createReplicaSetPod("test-ss-10", labels, now, false))
+				s.Require().NoError(s.createReplicaSetPod("test-ss-2", labels, now, false))
+				s.Require().NoError(s.createReplicaSetPod("test-ss-0", labels, now.Add(time.Minute), false))
+				s.Require().NoError(s.createReplicaSetPod("test-ss-1", labels, now, true))
+				s.Require().NoError(s.createReplicaSetPod("other-0", map[string]string{"app": "other"}, now, false))
+			},
+			expectedPods: []string{"test-ss-0", "test-ss-2", "test-ss-10"},
+		},
+		{
+			name:        "statefulset not found",
+			ssName:      "missing-ss",
+			setupMock:   func() {},
+			expectedErr: k8s.ErrGettingStatefulSet,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			pods, err := s.client.GetPodsFromStatefulSet(context.Background(), tt.ssName)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			names := make([]string, 0, len(pods))
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			s.Assert().Equal(tt.expectedPods, names)
+		})
+	}
+}
+
+func (s *TestSuite) TestScaleStatefulSet() {
+	tests := []struct {
+		name        string
+		ssName      string
+		replicas    int32
+		setupMock   func()
+		expectedErr error
+	}{
+		{
+			name:     "successful scaling",
+			ssName:   "test-ss",
+			replicas: 3,
+			setupMock: func() {
+				s.Require().NoError(s.createStatefulSet("test-ss", map[string]string{"app": "test"}))
+			},
+		},
+		{
+			name:        "negative replicas",
+			ssName:      "test-ss",
+			replicas:    -1,
+			setupMock:   func() {},
+			expectedErr: k8s.ErrStatefulSetReplicasNegative,
+		},
+		{
+			name:        "statefulset not found",
+			ssName:      "missing-ss",
+			replicas:    2,
+			setupMock:   func() {},
+			expectedErr: k8s.ErrGettingStatefulSet,
+		},
+		{
+			name:     "client error on update",
+			ssName:   "error-ss",
+			replicas: 2,
+			setupMock: func() {
+				s.Require().NoError(s.createStatefulSet("error-ss", map[string]string{"app": "error"}))
+				s.client.Clientset().(*fake.Clientset).
+					PrependReactor("update", "statefulsets",
+						func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+							return true, nil, errInternalServerError
+						})
+			},
+			expectedErr: k8s.ErrScalingStatefulSet.Wrap(errInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMock()
+
+			err := s.client.ScaleStatefulSet(context.Background(), tt.ssName, tt.replicas)
+			if tt.expectedErr != nil {
+				s.Require().Error(err)
+				s.Assert().ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			s.Require().NoError(err)
+			ss, err := s.client.Clientset().AppsV1().StatefulSets(s.namespace).Get(context.Background(), tt.ssName, metav1.GetOptions{})
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.replicas, *ss.Spec.Replicas)
+		})
+	}
+}
+
+func (s *TestSuite) createStatefulSet(name string, labels map[string]string) error {
+	_, err := s.client.Clientset().AppsV1().StatefulSets(s.namespace).Create(context.Background(), &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.namespace,
+			Labels:    labels,
+		},
+		Spec: appv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}, metav1.CreateOptions{})
+	return err
+}
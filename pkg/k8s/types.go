@@ -5,6 +5,7 @@ import (
 	"io"
 
 	appv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -23,64 +24,102 @@ type KubeManager interface {
 	CreateOrUpdateConfigMap(ctx context.Context, name string, labels, data map[string]string) (*corev1.ConfigMap, error)
 	CreateCustomResource(ctx context.Context, name string, gvr *schema.GroupVersionResource, obj *map[string]interface{}) error
 	CreateDaemonSet(ctx context.Context, name string, labels map[string]string, initContainers []corev1.Container, containers []corev1.Container) (*appv1.DaemonSet, error)
+	CreateJob(ctx context.Context, jobConfig JobConfig, init bool) (*batchv1.Job, error)
 	CreateNamespace(ctx context.Context, name string) error
 	CreateNetworkPolicy(ctx context.Context, name string, selectorMap, ingressSelectorMap, egressSelectorMap map[string]string) error
+	CreateOrUpdatePartitionNetworkPolicy(ctx context.Context, name string, selectorMap map[string]string, key string, blockedValues []string) error
+	CreateNetworkPolicyWithOptions(ctx context.Context, name string, opts NetworkPolicyOptions) (*netv1.NetworkPolicy, error)
+	CreateOrUpdateNetworkPolicy(ctx context.Context, name string, opts NetworkPolicyOptions) (*netv1.NetworkPolicy, error)
+	CreateOrUpdateSecret(ctx context.Context, name string, labels map[string]string, secretType corev1.SecretType, data map[string][]byte) (*corev1.Secret, error)
 	PersistentVolumeClaimExists(ctx context.Context, name string) (bool, error)
-	CreatePersistentVolumeClaim(ctx context.Context, name string, labels map[string]string, size resource.Quantity) error
+	CreatePersistentVolumeClaim(ctx context.Context, name string, labels map[string]string, size resource.Quantity, opts ...PersistentVolumeClaimOption) error
 	CreateReplicaSet(ctx context.Context, rsConfig ReplicaSetConfig, init bool) (*appv1.ReplicaSet, error)
+	CreateStatefulSet(ctx context.Context, ssConfig StatefulSetConfig, init bool) (*appv1.StatefulSet, error)
 	CreateRole(ctx context.Context, name string, labels map[string]string, policyRules []rbacv1.PolicyRule) error
 	CreateRoleBinding(ctx context.Context, name string, labels map[string]string, role, serviceAccount string) error
 	CreateService(ctx context.Context, name string, opts ServiceOptions) (*corev1.Service, error)
 	CreateServiceAccount(ctx context.Context, name string, labels map[string]string) error
+	CreateVolumeSnapshot(ctx context.Context, name string, labels map[string]string, pvcName string) error
 	CustomResourceDefinitionExists(ctx context.Context, gvr *schema.GroupVersionResource) (bool, error)
 	DaemonSetExists(ctx context.Context, name string) (bool, error)
 	DeleteConfigMap(ctx context.Context, name string) error
 	DeleteDaemonSet(ctx context.Context, name string) error
+	DeleteJob(ctx context.Context, name string) error
 	DeleteNamespace(ctx context.Context, name string) error
 	DeleteNetworkPolicy(ctx context.Context, name string) error
 	DeletePersistentVolumeClaim(ctx context.Context, name string) error
+	DeletePersistentVolumeClaimsByLabels(ctx context.Context, labels map[string]string) error
 	DeletePod(ctx context.Context, name string) error
 	DeletePodWithGracePeriod(ctx context.Context, name string, gracePeriodSeconds *int64) error
 	DeleteReplicaSet(ctx context.Context, name string) error
 	DeleteReplicaSetWithGracePeriod(ctx context.Context, name string, gracePeriodSeconds *int64) error
+	DeleteStatefulSet(ctx context.Context, name string) error
+	DeleteStatefulSetWithGracePeriod(ctx context.Context, name string, gracePeriodSeconds *int64) error
 	DeleteRole(ctx context.Context, name string) error
 	DeleteRoleBinding(ctx context.Context, name string) error
+	DeleteSecret(ctx context.Context, name string) error
 	DeleteService(ctx context.Context, name string) error
 	DeleteServiceAccount(ctx context.Context, name string) error
+	DeleteVolumeSnapshot(ctx context.Context, name string) error
 	DeployPod(ctx context.Context, podConfig PodConfig, init bool) (*corev1.Pod, error)
 	DiscoveryClient() discovery.DiscoveryInterface
 	DynamicClient() dynamic.Interface
 	GetConfigMap(ctx context.Context, name string) (*corev1.ConfigMap, error)
 	GetDaemonSet(ctx context.Context, name string) (*appv1.DaemonSet, error)
+	GetRole(ctx context.Context, name string) (*rbacv1.Role, error)
+	GetFirstPodFromJob(ctx context.Context, name string) (*corev1.Pod, error)
 	GetFirstPodFromReplicaSet(ctx context.Context, name string) (*corev1.Pod, error)
+	GetPodsFromJob(ctx context.Context, name string) ([]corev1.Pod, error)
+	GetPodsFromReplicaSet(ctx context.Context, name string) ([]corev1.Pod, error)
+	GetPodsFromStatefulSet(ctx context.Context, name string) ([]corev1.Pod, error)
 	GetLogStream(ctx context.Context, podName string, containerName string) (io.ReadCloser, error)
+	GetPodLogStream(ctx context.Context, podName string, containerName string) (io.ReadCloser, error)
+	FollowPodLogStream(ctx context.Context, podName string, containerName string) (io.ReadCloser, error)
 	GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error)
 	GetNetworkPolicy(ctx context.Context, name string) (*netv1.NetworkPolicy, error)
+	GetPersistentVolumeClaim(ctx context.Context, name string) (*corev1.PersistentVolumeClaim, error)
+	GetSecret(ctx context.Context, name string) (*corev1.Secret, error)
 	GetService(ctx context.Context, name string) (*corev1.Service, error)
 	GetServiceEndpoint(ctx context.Context, name string) (string, error)
 	GetServiceIP(ctx context.Context, name string) (string, error)
+	GetVolumeSnapshot(ctx context.Context, name string) (*VolumeSnapshot, error)
 	ServiceDNS(name string) string
 	ServicePort(ctx context.Context, name string) (int32, error)
+	IsJobStarted(ctx context.Context, name string) (bool, error)
 	IsPodRunning(ctx context.Context, name string) (bool, error)
 	IsReplicaSetRunning(ctx context.Context, name string) (bool, error)
+	IsStatefulSetRunning(ctx context.Context, name string) (bool, error)
+	JobExists(ctx context.Context, name string) (bool, error)
+	ListJobs(ctx context.Context, labels map[string]string) ([]batchv1.Job, error)
+	ListReplicaSets(ctx context.Context, labels map[string]string) ([]appv1.ReplicaSet, error)
+	ListStatefulSets(ctx context.Context, labels map[string]string) ([]appv1.StatefulSet, error)
 	Namespace() string
 	NamespaceExists(ctx context.Context, name string) (bool, error)
 	NetworkPolicyExists(ctx context.Context, name string) bool
+	PatchNetworkPolicy(ctx context.Context, name string, patch []byte) (*netv1.NetworkPolicy, error)
 	NewFile(source, dest, chown, permission string) *File
 	NewVolume(path string, size resource.Quantity, owner int64) *Volume
 	PatchService(ctx context.Context, name string, opts ServiceOptions) (*corev1.Service, error)
 	PortForwardPod(ctx context.Context, podName string, localPort, remotePort int) error
 	ReplicaSetExists(ctx context.Context, name string) (bool, error)
+	ScaleReplicaSet(ctx context.Context, name string, replicas int32) error
+	ScaleStatefulSet(ctx context.Context, name string, replicas int32) error
+	StatefulSetExists(ctx context.Context, name string) (bool, error)
 	ReplacePod(ctx context.Context, podConfig PodConfig) (*corev1.Pod, error)
 	ReplacePodWithGracePeriod(ctx context.Context, podConfig PodConfig, gracePeriod *int64) (*corev1.Pod, error)
 	ReplaceReplicaSet(ctx context.Context, ReplicaSetConfig ReplicaSetConfig) (*appv1.ReplicaSet, error)
 	ReplaceReplicaSetWithGracePeriod(ctx context.Context, ReplicaSetConfig ReplicaSetConfig, gracePeriod *int64) (*appv1.ReplicaSet, error)
 	RunCommandInPod(ctx context.Context, podName, containerName string, cmd []string) (string, error)
+	RunCommandInPodWithStdin(ctx context.Context, podName, containerName string, cmd []string, stdin io.Reader) (string, error)
+	StreamCommandInPod(ctx context.Context, podName, containerName string, cmd []string, stdin io.Reader, stdout io.Writer) error
 	ConfigMapExists(ctx context.Context, name string) (bool, error)
 	UpdateDaemonSet(ctx context.Context, name string, labels map[string]string, initContainers []corev1.Container, containers []corev1.Container) (*appv1.DaemonSet, error)
 	UpdateConfigMap(ctx context.Context, name string, labels, data map[string]string) (*corev1.ConfigMap, error)
+	UpdateNetworkPolicy(ctx context.Context, name string, opts NetworkPolicyOptions) (*netv1.NetworkPolicy, error)
 	WaitForDeployment(ctx context.Context, name string) error
+	WaitForJobCompletion(ctx context.Context, name string) (*batchv1.Job, error)
 	WaitForService(ctx context.Context, name string) error
+	WaitForVolumeSnapshotReady(ctx context.Context, name string) (*VolumeSnapshot, error)
 	Terminate()
 	AllPodsStatuses(ctx context.Context) ([]PodStatus, error)
 	PodStatus(ctx context.Context, name string) (PodStatus, error)
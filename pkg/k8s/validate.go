@@ -1,6 +1,10 @@
 package k8s
 
 import (
+	"net"
+	"regexp"
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -10,6 +14,8 @@ import (
 	"github.com/celestiaorg/knuu/pkg/errors"
 )
 
+var sha256Regex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
 func validateDNS1123Label(name string, err *errors.Error) error {
 	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
 		return err.WithParams(name, errs)
@@ -95,6 +101,60 @@ func validateSelectorMap(selectorMap map[string]string) error {
 	return validateLabels(selectorMap)
 }
 
+func validateNetworkPolicyOptions(opts NetworkPolicyOptions) error {
+	if err := validateLabels(opts.Labels); err != nil {
+		return err
+	}
+	if err := validateSelectorMap(opts.SelectorMap); err != nil {
+		return err
+	}
+	for _, rule := range append(append([]NetworkPolicyRule{}, opts.Ingress...), opts.Egress...) {
+		if err := validateNetworkPolicyRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateNetworkPolicyRule(rule NetworkPolicyRule) error {
+	for _, port := range rule.Ports {
+		switch port.Protocol {
+		case v1.ProtocolTCP, v1.ProtocolUDP, v1.ProtocolSCTP:
+		default:
+			return ErrInvalidNetworkPolicyProtocol.WithParams(port.Protocol)
+		}
+		if port.Port != 0 {
+			if err := validatePort(port.Port); err != nil {
+				return err
+			}
+		}
+		if port.EndPort != 0 && (port.Port == 0 || port.EndPort < port.Port || port.EndPort > 65535) {
+			return ErrInvalidNetworkPolicyPortRange.WithParams(port.Port, port.EndPort)
+		}
+	}
+
+	for _, peer := range rule.Peers {
+		if peer.CIDR == "" {
+			if err := validateSelectorMap(peer.PodSelector); err != nil {
+				return err
+			}
+			if err := validateSelectorMap(peer.NamespaceSelector); err != nil {
+				return err
+			}
+			continue
+		}
+		if peer.PodSelector != nil || peer.NamespaceSelector != nil {
+			return ErrNetworkPolicyPeerCIDRWithSelector.WithParams(peer.CIDR)
+		}
+		for _, cidr := range append([]string{peer.CIDR}, peer.Except...) {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return ErrInvalidCIDR.WithParams(cidr).Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
 func validatePodName(name string) error {
 	return validateDNS1123Subdomain(name, ErrInvalidPodName)
 }
@@ -137,12 +197,25 @@ func validateContainerConfig(config ContainerConfig) error {
 		if err := validateVolume(volume); err != nil {
 			return err
 		}
+		// the volumes of a container share one pod volume
+		if volume.EmptyDir != config.Volumes[0].EmptyDir || volume.VolumeMode != config.Volumes[0].VolumeMode {
+			return ErrVolumesKindMismatch.WithParams(config.Name)
+		}
+		// the files are copied into the mounted volumes
+		if volume.IsBlock() && len(config.Files) != 0 {
+			return ErrBlockVolumeWithFiles.WithParams(volume.Path)
+		}
 	}
 	for _, file := range config.Files {
 		if err := validateFile(file); err != nil {
 			return err
 		}
 	}
+	for _, sv := range config.SharedVolumes {
+		if err := validateSharedVolume(sv); err != nil {
+			return err
+		}
+	}
 	return validateContainerName(config.Name)
 }
 
@@ -154,13 +227,48 @@ func validateVolume(volume *Volume) error {
 	if volume.Size.Value() <= 0 {
 		return ErrVolumeSizeZero.WithParams(volume.Path)
 	}
+
+	switch volume.AccessMode {
+	case "", v1.ReadWriteOnce, v1.ReadOnlyMany, v1.ReadWriteMany, v1.ReadWriteOncePod:
+	default:
+		return ErrInvalidVolumeAccessMode.WithParams(volume.AccessMode, volume.Path)
+	}
+	switch volume.VolumeMode {
+	case "", v1.PersistentVolumeFilesystem, v1.PersistentVolumeBlock:
+	default:
+		return ErrInvalidVolumeMode.WithParams(volume.VolumeMode, volume.Path)
+	}
+	if volume.EmptyDir && (volume.StorageClass != "" || volume.AccessMode != "" || volume.VolumeMode != "") {
+		return ErrEmptyDirVolumeWithClaimOptions.WithParams(volume.Path)
+	}
+	if !volume.EmptyDir && volume.Medium != "" {
+		return ErrVolumeMediumWithoutEmptyDir.WithParams(volume.Path)
+	}
+	return nil
+}
+
+func validateSharedVolume(sv *SharedVolume) error {
+	if err := validateDNS1123Label(sv.Name, ErrInvalidSharedVolumeName); err != nil {
+		return err
+	}
+	if sv.Path == "" {
+		return ErrVolumePathEmpty.WithParams(sv.Path)
+	}
 	return nil
 }
 
 func validateFile(file *File) error {
-	if file.Source == "" || file.Dest == "" {
+	// the content of a secret file is in the secret of the container
+	if (file.Source == "" && !file.Secret) || file.Dest == "" {
 		return ErrFileSourceDestEmpty.WithParams(file.Source, file.Dest)
 	}
+	// the url and the checksum are quoted in the command of the init container
+	if strings.Contains(file.URL, "'") {
+		return ErrInvalidFileURL.WithParams(file.URL)
+	}
+	if file.Checksum != "" && !sha256Regex.MatchString(file.Checksum) {
+		return ErrInvalidFileChecksum.WithParams(file.Checksum)
+	}
 	return nil
 }
 
@@ -197,6 +305,10 @@ func validatePVCName(name string) error {
 	return validateDNS1123Subdomain(name, ErrInvalidPVCName)
 }
 
+func validateVolumeSnapshotName(name string) error {
+	return validateDNS1123Subdomain(name, ErrInvalidVolumeSnapshotName)
+}
+
 func validatePVCSize(size resource.Quantity) error {
 	if size.Value() <= 0 {
 		return ErrPVCSizeZero.WithParams(size)
@@ -227,6 +339,55 @@ func validateReplicaSetConfig(rsConfig ReplicaSetConfig) error {
 	return nil
 }
 
+func validateStatefulSetName(name string) error {
+	return validateDNS1123Subdomain(name, ErrInvalidStatefulSetName)
+}
+
+func validateStatefulSetConfig(ssConfig StatefulSetConfig) error {
+	if err := validateStatefulSetName(ssConfig.Name); err != nil {
+		return err
+	}
+	if err := validateNamespace(ssConfig.Namespace); err != nil {
+		return err
+	}
+	if err := validateLabels(ssConfig.Labels); err != nil {
+		return err
+	}
+	if ssConfig.Replicas < 0 {
+		return ErrStatefulSetReplicasNegative.WithParams(ssConfig.Replicas)
+	}
+	if err := validatePodConfig(ssConfig.PodConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateJobName(name string) error {
+	return validateDNS1123Subdomain(name, ErrInvalidJobName)
+}
+
+func validateJobConfig(jobConfig JobConfig) error {
+	if err := validateJobName(jobConfig.Name); err != nil {
+		return err
+	}
+	if err := validateNamespace(jobConfig.Namespace); err != nil {
+		return err
+	}
+	if err := validateLabels(jobConfig.Labels); err != nil {
+		return err
+	}
+	if jobConfig.Completions < 0 {
+		return ErrJobCompletionsNegative.WithParams(jobConfig.Completions)
+	}
+	if jobConfig.BackoffLimit < 0 {
+		return ErrJobBackoffLimitNegative.WithParams(jobConfig.BackoffLimit)
+	}
+	if err := validatePodConfig(jobConfig.PodConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
 func validateRoleName(name string) error {
 	return validateDNS1123Subdomain(name, ErrInvalidRoleName)
 }
@@ -287,6 +448,22 @@ func validateConfigMap(name string, labels, data map[string]string) error {
 	return validateConfigMapKeys(data)
 }
 
+func validateSecret(name string, labels map[string]string, data map[string][]byte) error {
+	if err := validateDNS1123Subdomain(name, ErrInvalidSecretName); err != nil {
+		return err
+	}
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	for key := range data {
+		// secret keys follow the same rules as config map keys
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return ErrInvalidSecretKey.WithParams(key, errs)
+		}
+	}
+	return nil
+}
+
 func validateServiceOptions(options ServiceOptions) error {
 	if err := validateLabels(options.Labels); err != nil {
 		return err
@@ -0,0 +1,131 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+const volumeSnapshotKind = "VolumeSnapshot"
+
+// volumeSnapshotGVR is the resource of the CSI volume snapshots, the snapshot CRDs and controller must be installed in the cluster
+var volumeSnapshotGVR = schema.GroupVersionResource{
+	Group:    "snapshot.storage.k8s.io",
+	Version:  "v1",
+	Resource: "volumesnapshots",
+}
+
+// VolumeSnapshot is a CSI snapshot of a PersistentVolumeClaim
+type VolumeSnapshot struct {
+	Name        string
+	SourcePVC   string            // Name of the PersistentVolumeClaim the snapshot is taken from
+	ReadyToUse  bool              // True once the snapshot can be restored
+	RestoreSize resource.Quantity // Minimum size of a PersistentVolumeClaim restored from the snapshot, set once it is ready
+}
+
+// CreateVolumeSnapshot takes a snapshot of the given PersistentVolumeClaim with the default VolumeSnapshotClass of the cluster
+func (c *Client) CreateVolumeSnapshot(ctx context.Context, name string, labels map[string]string, pvcName string) error {
+	if c.terminated {
+		return ErrClientTerminated
+	}
+	if err := validateVolumeSnapshotName(name); err != nil {
+		return err
+	}
+	if err := validatePVCName(pvcName); err != nil {
+		return err
+	}
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetAPIVersion(volumeSnapshotGVR.GroupVersion().String())
+	snapshot.SetKind(volumeSnapshotKind)
+	snapshot.SetName(name)
+	snapshot.SetNamespace(c.namespace)
+	snapshot.SetLabels(labels)
+	if err := unstructured.SetNestedField(snapshot.Object, pvcName, "spec", "source", "persistentVolumeClaimName"); err != nil {
+		return ErrCreatingVolumeSnapshot.WithParams(name).Wrap(err)
+	}
+
+	_, err := c.dynamicClient.Resource(volumeSnapshotGVR).Namespace(c.namespace).Create(ctx, snapshot, metav1.CreateOptions{})
+	if err != nil {
+		return ErrCreatingVolumeSnapshot.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithField("name", name).Debug("VolumeSnapshot created")
+	return nil
+}
+
+// GetVolumeSnapshot returns the volume snapshot with the given name
+func (c *Client) GetVolumeSnapshot(ctx context.Context, name string) (*VolumeSnapshot, error) {
+	obj, err := c.dynamicClient.Resource(volumeSnapshotGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, ErrGettingVolumeSnapshot.WithParams(name).Wrap(err)
+	}
+
+	if message, _, _ := unstructured.NestedString(obj.Object, "status", "error", "message"); message != "" {
+		return nil, ErrVolumeSnapshotFailed.WithParams(name, message)
+	}
+
+	snapshot := &VolumeSnapshot{Name: name}
+	snapshot.SourcePVC, _, _ = unstructured.NestedString(obj.Object, "spec", "source", "persistentVolumeClaimName")
+	snapshot.ReadyToUse, _, _ = unstructured.NestedBool(obj.Object, "status", "readyToUse")
+	if size, found, _ := unstructured.NestedString(obj.Object, "status", "restoreSize"); found {
+		snapshot.RestoreSize, err = resource.ParseQuantity(size)
+		if err != nil {
+			return nil, ErrGettingVolumeSnapshot.WithParams(name).Wrap(err)
+		}
+	}
+	return snapshot, nil
+}
+
+// WaitForVolumeSnapshotReady waits until the volume snapshot can be restored
+func (c *Client) WaitForVolumeSnapshotReady(ctx context.Context, name string) (*VolumeSnapshot, error) {
+	for {
+		snapshot, err := c.GetVolumeSnapshot(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.ReadyToUse {
+			return snapshot, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrWaitingForVolumeSnapshot.WithParams(name).Wrap(ctx.Err())
+		case <-time.After(waitRetry):
+			// Retry after some seconds
+		}
+	}
+}
+
+// DeleteVolumeSnapshot deletes the volume snapshot, it does not fail if the snapshot does not exist
+func (c *Client) DeleteVolumeSnapshot(ctx context.Context, name string) error {
+	err := c.dynamicClient.Resource(volumeSnapshotGVR).Namespace(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return ErrDeletingVolumeSnapshot.WithParams(name).Wrap(err)
+	}
+
+	c.logger.WithField("name", name).Debug("VolumeSnapshot deleted")
+	return nil
+}
+
+// WithVolumeSnapshotSource restores the PersistentVolumeClaim from the given volume snapshot
+// The size of the claim must be at least the restore size of the snapshot
+func WithVolumeSnapshotSource(snapshotName string) PersistentVolumeClaimOption {
+	return func(pvc *v1.PersistentVolumeClaim) {
+		pvc.Spec.DataSource = &v1.TypedLocalObjectReference{
+			APIGroup: ptr.To(volumeSnapshotGVR.Group),
+			Kind:     volumeSnapshotKind,
+			Name:     snapshotName,
+		}
+	}
+}
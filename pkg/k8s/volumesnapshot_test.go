@@ -0,0 +1,79 @@
+package k8s_test
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+var volumeSnapshotGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+func (s *TestSuite) TestVolumeSnapshot() {
+	ctx := context.Background()
+	snapshots := s.client.DynamicClient().Resource(volumeSnapshotGVR).Namespace(s.namespace)
+
+	err := s.client.CreateVolumeSnapshot(ctx, "Invalid_Name", map[string]string{"app": "test"}, "test-pvc")
+	s.Assert().ErrorIs(err, k8s.ErrInvalidVolumeSnapshotName)
+
+	s.Require().NoError(s.client.CreateVolumeSnapshot(ctx, "synced", map[string]string{"app": "test"}, "test-pvc"))
+
+	snapshot, err := s.client.GetVolumeSnapshot(ctx, "synced")
+	s.Require().NoError(err)
+	s.Assert().Equal(&k8s.VolumeSnapshot{Name: "synced", SourcePVC: "test-pvc"}, snapshot)
+
+	// the snapshot controller sets the status once the snapshot is taken
+	obj, err := snapshots.Get(ctx, "synced", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Assert().Equal(map[string]string{"app": "test"}, obj.GetLabels())
+	s.Require().NoError(unstructured.SetNestedField(obj.Object, true, "status", "readyToUse"))
+	s.Require().NoError(unstructured.SetNestedField(obj.Object, "2Gi", "status", "restoreSize"))
+	_, err = snapshots.Update(ctx, obj, metav1.UpdateOptions{})
+	s.Require().NoError(err)
+
+	snapshot, err = s.client.WaitForVolumeSnapshotReady(ctx, "synced")
+	s.Require().NoError(err)
+	s.Assert().True(snapshot.ReadyToUse)
+	s.Assert().Equal(resource.MustParse("2Gi"), snapshot.RestoreSize)
+
+	s.Require().NoError(s.client.DeleteVolumeSnapshot(ctx, "synced"))
+	_, err = s.client.GetVolumeSnapshot(ctx, "synced")
+	s.Assert().ErrorIs(err, k8s.ErrGettingVolumeSnapshot)
+	// deleting a snapshot that does not exist is not an error
+	s.Require().NoError(s.client.DeleteVolumeSnapshot(ctx, "synced"))
+}
+
+func (s *TestSuite) TestVolumeSnapshotFailed() {
+	ctx := context.Background()
+	s.Require().NoError(s.client.CreateVolumeSnapshot(ctx, "failed", map[string]string{"app": "test"}, "test-pvc"))
+
+	snapshots := s.client.DynamicClient().Resource(volumeSnapshotGVR).Namespace(s.namespace)
+	obj, err := snapshots.Get(ctx, "failed", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Require().NoError(unstructured.SetNestedField(obj.Object, "no snapshot class", "status", "error", "message"))
+	_, err = snapshots.Update(ctx, obj, metav1.UpdateOptions{})
+	s.Require().NoError(err)
+
+	_, err = s.client.WaitForVolumeSnapshotReady(ctx, "failed")
+	s.Assert().ErrorIs(err, k8s.ErrVolumeSnapshotFailed)
+}
+
+func (s *TestSuite) TestCreatePersistentVolumeClaimFromVolumeSnapshot() {
+	err := s.client.CreatePersistentVolumeClaim(context.Background(), "restored-pvc", map[string]string{"app": "test"},
+		resource.MustParse("2Gi"), k8s.WithVolumeSnapshotSource("synced"))
+	s.Require().NoError(err)
+
+	pvc, err := s.client.Clientset().CoreV1().PersistentVolumeClaims(s.namespace).Get(context.Background(), "restored-pvc", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Require().NotNil(pvc.Spec.DataSource)
+	s.Assert().Equal(v1.TypedLocalObjectReference{
+		APIGroup: &volumeSnapshotGVR.Group,
+		Kind:     "VolumeSnapshot",
+		Name:     "synced",
+	}, *pvc.Spec.DataSource)
+}
@@ -0,0 +1,132 @@
+package knuu
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/celestiaorg/knuu/pkg/imagecache"
+	"github.com/celestiaorg/knuu/pkg/instance"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+// Attach connects to an existing scope, e.g. from a second process or after the test binary crashed,
+// and rebuilds the handles of the instances that are running in it.
+// No timeout handler is deployed, the one of the process that created the scope stays in charge of the cleanup.
+func Attach(ctx context.Context, scope string) (*Knuu, []*instance.Instance, error) {
+	return AttachWithOptions(ctx, Options{Scope: scope})
+}
+
+// AttachWithOptions is like Attach, but allows to pass custom options, e.g. a custom k8s client or logger.
+// The Timeout and ProxyEnabled options are ignored.
+func AttachWithOptions(ctx context.Context, opts Options) (*Knuu, []*instance.Instance, error) {
+	if opts.Scope == "" && opts.K8sClient == nil {
+		return nil, nil, ErrScopeRequiredForAttach
+	}
+	if err := validateOptions(opts); err != nil {
+		return nil, nil, err
+	}
+
+	k := &Knuu{
+		SystemDependencies: &system.SystemDependencies{
+			K8sClient:    opts.K8sClient,
+			MinioClient:  opts.MinioClient,
+			ImageBuilder: opts.ImageBuilder,
+			Registry:     opts.Registry,
+			Logger:       opts.Logger,
+			Scope:        opts.Scope,
+			StartTime:    time.Now().UTC().Format(TimeFormat),
+
+			RegistryCredentials: opts.RegistryCredentials,
+		},
+		clusterDomain: opts.ClusterDomain,
+	}
+
+	if err := setDefaults(ctx, k); err != nil {
+		return nil, nil, err
+	}
+	if opts.ImageCache != nil {
+		k.ImageCache = imagecache.New(opts.ImageCache, k.Registry, k.Logger)
+	}
+	if err := setupRegistryCredentials(ctx, k); err != nil {
+		return nil, nil, err
+	}
+
+	instances, err := k.attachInstances(ctx)
+	if err != nil {
+		return nil, nil, ErrAttachingToScope.WithParams(k.Scope).Wrap(err)
+	}
+	return k, instances, nil
+}
+
+// attachInstances rebuilds the instances of all knuu workloads labelled with the scope
+func (k *Knuu) attachInstances(ctx context.Context) ([]*instance.Instance, error) {
+	labels := map[string]string{
+		instance.LabelScopeKey:     k.Scope,
+		instance.LabelManagedByKey: instance.LabelKnuuValue,
+	}
+
+	type workload struct {
+		workloadType instance.WorkloadType
+		replicas     int32
+		template     v1.PodTemplateSpec
+	}
+	var workloads []workload
+
+	replicaSets, err := k.K8sClient.ListReplicaSets(ctx, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, rs := range replicaSets {
+		workloads = append(workloads, workload{instance.ReplicaSetWorkload, replicasOf(rs.Spec.Replicas), rs.Spec.Template})
+	}
+
+	statefulSets, err := k.K8sClient.ListStatefulSets(ctx, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, ss := range statefulSets {
+		workloads = append(workloads, workload{instance.StatefulSetWorkload, replicasOf(ss.Spec.Replicas), ss.Spec.Template})
+	}
+
+	jobs, err := k.K8sClient.ListJobs(ctx, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		workloads = append(workloads, workload{instance.JobWorkload, replicasOf(job.Spec.Completions), job.Spec.Template})
+	}
+
+	instances := make([]*instance.Instance, 0, len(workloads))
+	for _, w := range workloads {
+		// The timeout handler is managed by the process that created the scope
+		if w.template.Labels[instance.LabelTypeKey] == instance.TimeoutHandlerInstance.String() {
+			continue
+		}
+		// All the instances of a scope share the start time of the process that created them,
+		// it is part of their labels, so it is needed to match their resources
+		if startTime := w.template.Labels[instance.LabelTestStartedKey]; startTime != "" {
+			k.StartTime = startTime
+		}
+
+		inst, err := instance.Attach(ctx, k.SystemDependencies, w.workloadType, w.replicas, w.template)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, inst)
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name() < instances[j].Name()
+	})
+	return instances, nil
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
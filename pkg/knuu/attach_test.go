@@ -0,0 +1,156 @@
+package knuu
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	discfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+	"github.com/celestiaorg/knuu/pkg/instance"
+	"github.com/celestiaorg/knuu/pkg/k8s"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+func TestAttach(t *testing.T) {
+	ctx := context.Background()
+	const scope = "attach-test"
+
+	k8sClient, err := k8s.NewClientCustom(ctx, fake.NewSimpleClientset(), &discfake.FakeDiscovery{Fake: &k8stesting.Fake{}}, nil, scope, logrus.New())
+	require.NoError(t, err)
+
+	// Start an instance from a first "process"
+	sysDeps := &system.SystemDependencies{
+		K8sClient: k8sClient,
+		Logger:    logrus.New(),
+		Scope:     scope,
+		StartTime: time.Now().UTC().Format(TimeFormat),
+	}
+	inst, err := instance.New("app", sysDeps)
+	require.NoError(t, err)
+	require.NoError(t, inst.Build().SetImage(ctx, "alpine:latest"))
+	require.NoError(t, inst.Build().SetStartCommand("sleep", "infinity"))
+	require.NoError(t, inst.Build().Commit(ctx))
+	require.NoError(t, inst.Build().SetEnvironmentVariable("FOO", "bar"))
+	require.NoError(t, inst.Network().AddPortTCP(8080))
+	require.NoError(t, inst.Network().AddPortUDP(9090))
+	require.NoError(t, inst.Storage().AddVolumeWithOwner("/data", resource.MustParse("1Gi"), 1000, instance.WithAccessMode(v1.ReadWriteMany)))
+	require.NoError(t, inst.Resources().SetReplicas(2))
+	require.NoError(t, inst.Execution().StartAsync(ctx))
+
+	// Attach to the scope from a second "process"
+	k, instances, err := AttachWithOptions(ctx, Options{
+		K8sClient: k8sClient,
+		Scope:     scope,
+		Logger:    logrus.New(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, k)
+	assert.Equal(t, sysDeps.StartTime, k.StartTime)
+	require.Len(t, instances, 1)
+
+	attached := instances[0]
+	assert.Equal(t, "app", attached.Name())
+	assert.Equal(t, instance.StateStarted, attached.State())
+	assert.Equal(t, instance.ReplicaSetWorkload, attached.Execution().WorkloadType())
+	assert.Equal(t, int32(2), attached.Resources().Replicas())
+	assert.Equal(t, "alpine:latest", attached.Build().ImageName())
+	assert.Equal(t, inst.Execution().Labels(), attached.Execution().Labels())
+
+	// the access mode of the volume is restored from its claim, so the replicas can still share it
+	require.NoError(t, attached.Execution().Scale(ctx, 3))
+
+	require.NoError(t, attached.Execution().Destroy(ctx))
+	exists, err := k8sClient.ReplicaSetExists(ctx, "app")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestAttachWithNetworkPolicy(t *testing.T) {
+	ctx := context.Background()
+	const scope = "attach-policy-test"
+
+	k8sClient, err := k8s.NewClientCustom(ctx, fake.NewSimpleClientset(), &discfake.FakeDiscovery{Fake: &k8stesting.Fake{}}, nil, scope, logrus.New())
+	require.NoError(t, err)
+	sysDeps := &system.SystemDependencies{
+		K8sClient: k8sClient,
+		Logger:    logrus.New(),
+		Scope:     scope,
+		StartTime: time.Now().UTC().Format(TimeFormat),
+	}
+	inst, err := instance.New("validator", sysDeps)
+	require.NoError(t, err)
+	require.NoError(t, inst.Build().SetImage(ctx, "alpine:latest"))
+	require.NoError(t, inst.Build().Commit(ctx))
+	require.NoError(t, inst.Network().AllowIngress([]k8s.NetworkPolicyPort{instance.PortTCP(26656)}, instance.PeerLabels(map[string]string{"app": "bridge"})))
+	require.NoError(t, inst.Network().DenyAllEgress())
+	require.NoError(t, inst.Network().AllowDNSEgress())
+	require.NoError(t, inst.Network().AllowEgress(nil, instance.PeerCIDR("10.0.0.0/8", "10.1.0.0/16")))
+	require.NoError(t, inst.Execution().StartAsync(ctx))
+	deployed, err := k8sClient.GetNetworkPolicy(ctx, "validator-policy")
+	require.NoError(t, err)
+
+	_, instances, err := AttachWithOptions(ctx, Options{
+		K8sClient: k8sClient,
+		Scope:     scope,
+		Logger:    logrus.New(),
+	})
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	attached := instances[0]
+	expected, actual := inst.Network().NetworkPolicyOptions(), attached.Network().NetworkPolicyOptions()
+	assert.Equal(t, expected.Ingress, actual.Ingress)
+	assert.Equal(t, expected.Egress, actual.Egress)
+
+	// the rules are applied again on restart
+	require.NoError(t, attached.Execution().Stop(ctx))
+	require.NoError(t, attached.Execution().StartAsync(ctx))
+	np, err := k8sClient.GetNetworkPolicy(ctx, "validator-policy")
+	require.NoError(t, err)
+	assert.Equal(t, deployed.Spec, np.Spec)
+
+	require.NoError(t, attached.Execution().Destroy(ctx))
+	assert.False(t, k8sClient.NetworkPolicyExists(ctx, "validator-policy"))
+}
+
+func TestAttachWithoutScope(t *testing.T) {
+	_, _, err := AttachWithOptions(context.Background(), Options{})
+	assert.ErrorIs(t, err, ErrScopeRequiredForAttach)
+}
+
+func TestSetupRegistryCredentials(t *testing.T) {
+	ctx := context.Background()
+	const scope = "credentials-test"
+
+	k8sClient, err := k8s.NewClientCustom(ctx, fake.NewSimpleClientset(), &discfake.FakeDiscovery{Fake: &k8stesting.Fake{}}, nil, scope, logrus.New())
+	require.NoError(t, err)
+
+	k := &Knuu{SystemDependencies: &system.SystemDependencies{
+		K8sClient: k8sClient,
+		Logger:    logrus.New(),
+		Scope:     scope,
+		Registry:  builder.DefaultRegistry(),
+	}}
+	require.NoError(t, setupRegistryCredentials(ctx, k))
+	assert.Empty(t, k.ImagePullSecret, "no secret is needed without credentials")
+
+	k.RegistryCredentials = []*builder.Registry{
+		{Host: "ghcr.io", Auth: &builder.RegistryAuth{Username: "user", Password: "token"}},
+	}
+	require.NoError(t, setupRegistryCredentials(ctx, k))
+	assert.Equal(t, registryCredentialsSecretName, k.ImagePullSecret)
+
+	secret, err := k8sClient.GetSecret(ctx, registryCredentialsSecretName)
+	require.NoError(t, err)
+	assert.Equal(t, v1.SecretTypeDockerConfigJson, secret.Type)
+	assert.Equal(t, scope, secret.Labels[instance.LabelScopeKey])
+	assert.Contains(t, string(secret.Data[v1.DockerConfigJsonKey]), "ghcr.io")
+}
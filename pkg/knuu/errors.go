@@ -210,4 +210,9 @@ var (
 	ErrScopeMismatch                             = errors.New("ScopeMismatch", "scope '%s' set in options does not match scope '%s' set by the k8sClient namespace")
 	ErrHandleTimeout                             = errors.New("HandleTimeout", "error starting handle timeout")
 	ErrDeprecated                                = errors.New("Deprecated", "deprecated")
+	ErrScopeRequiredForAttach                    = errors.New("ScopeRequiredForAttach", "a scope or a k8s client is required to attach to an existing scope")
+	ErrAttachingToScope                          = errors.New("AttachingToScope", "error attaching to scope '%s'")
+	ErrInvalidRegistry                           = errors.New("InvalidRegistry", "invalid registry")
+	ErrRegistryCredentialsWithoutAuth            = errors.New("RegistryCredentialsWithoutAuth", "registry credentials must have a host and an auth")
+	ErrCreatingRegistryCredentials               = errors.New("CreatingRegistryCredentials", "error creating the registry credentials secret")
 )
@@ -2,6 +2,8 @@
 package knuu
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
+
 	"github.com/celestiaorg/knuu/pkg/instance"
 	"github.com/celestiaorg/knuu/pkg/preloader"
 )
@@ -13,3 +15,18 @@ func (k *Knuu) NewInstance(name string) (*instance.Instance, error) {
 func (k *Knuu) NewPreloader(name string) (*preloader.Preloader, error) {
 	return preloader.New(name, k.SystemDependencies)
 }
+
+// NewSharedVolume creates a volume of the scope, backed by a ReadWriteMany PersistentVolumeClaim, that several instances can mount
+func (k *Knuu) NewSharedVolume(name string, size resource.Quantity) (*instance.SharedVolume, error) {
+	return instance.NewSharedVolume(name, size, k.SystemDependencies)
+}
+
+// NewSharedConfigMapVolume creates a volume of the scope, backed by a ConfigMap, that several instances can mount
+func (k *Knuu) NewSharedConfigMapVolume(name string, data map[string]string) (*instance.SharedVolume, error) {
+	return instance.NewSharedConfigMapVolume(name, data, k.SystemDependencies)
+}
+
+// NewSharedSecretVolume creates a volume of the scope, backed by a Secret, that several instances can mount
+func (k *Knuu) NewSharedSecretVolume(name string, data map[string][]byte) (*instance.SharedVolume, error) {
+	return instance.NewSharedSecretVolume(name, data, k.SystemDependencies)
+}
@@ -12,10 +12,12 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	"github.com/celestiaorg/knuu/pkg/builder"
 	"github.com/celestiaorg/knuu/pkg/builder/kaniko"
+	"github.com/celestiaorg/knuu/pkg/imagecache"
 	"github.com/celestiaorg/knuu/pkg/instance"
 	"github.com/celestiaorg/knuu/pkg/k8s"
 	"github.com/celestiaorg/knuu/pkg/log"
@@ -35,6 +37,8 @@ const (
 	ExitCodeSIGINT         = 130
 
 	TimeFormat = "20060102T150405Z"
+
+	registryCredentialsSecretName = "knuu-registry-credentials"
 )
 
 type Knuu struct {
@@ -47,11 +51,17 @@ type Options struct {
 	K8sClient     k8s.KubeManager
 	MinioClient   *minio.Minio
 	ImageBuilder  builder.Builder
+	Registry      *builder.Registry // optional, if not set, ttl.sh with a 24h expiry will be used
+	ImageCache    imagecache.Store  // optional, persists the built images across runs, e.g. imagecache.NewConfigMapStore
 	Scope         string
 	ProxyEnabled  bool
 	Timeout       time.Duration
 	Logger        *logrus.Logger
 	ClusterDomain string // optional, if not set, "cluster.local" will be used
+
+	// RegistryCredentials are the credentials of the private registries the images are pulled from (optional)
+	// The credentials of the Registry, if any, are used to push the built images
+	RegistryCredentials []*builder.Registry
 }
 
 func New(ctx context.Context, opts Options) (*Knuu, error) {
@@ -64,9 +74,12 @@ func New(ctx context.Context, opts Options) (*Knuu, error) {
 			K8sClient:    opts.K8sClient,
 			MinioClient:  opts.MinioClient,
 			ImageBuilder: opts.ImageBuilder,
+			Registry:     opts.Registry,
 			Logger:       opts.Logger,
 			Scope:        opts.Scope,
 			StartTime:    time.Now().UTC().Format(TimeFormat),
+
+			RegistryCredentials: opts.RegistryCredentials,
 		},
 		clusterDomain: opts.ClusterDomain,
 	}
@@ -74,6 +87,12 @@ func New(ctx context.Context, opts Options) (*Knuu, error) {
 	if err := setDefaults(ctx, k); err != nil {
 		return nil, err
 	}
+	if opts.ImageCache != nil {
+		k.ImageCache = imagecache.New(opts.ImageCache, k.Registry, k.Logger)
+	}
+	if err := setupRegistryCredentials(ctx, k); err != nil {
+		return nil, err
+	}
 
 	if opts.Timeout == 0 {
 		opts.Timeout = defaultTimeout
@@ -92,6 +111,13 @@ func New(ctx context.Context, opts Options) (*Knuu, error) {
 }
 
 func (k *Knuu) CleanUp(ctx context.Context) error {
+	// the files may outlive the namespace, e.g. in the persistent volume of minio
+	if k.MinioClient != nil {
+		err := k.MinioClient.DeletePrefix(ctx, instance.UploadedFilesPrefix(k.Scope), instance.FilesBucketName)
+		if err != nil {
+			k.Logger.WithError(err).WithField("scope", k.Scope).Warn("cannot delete the files uploaded to minio")
+		}
+	}
 	return k.K8sClient.DeleteNamespace(ctx, k.Scope)
 }
 
@@ -135,19 +161,26 @@ func (k *Knuu) handleTimeout(ctx context.Context, timeout time.Duration, timeout
 	// Wait for a specific period before executing the next operation.
 	// This is useful to ensure that any previous operation has time to complete.
 	commands = append(commands, fmt.Sprintf("sleep %d", int64(timeout.Seconds())))
+	// Delete the large files of the scope from minio while it is still running, they may outlive the namespace.
+	if k.MinioClient != nil {
+		commands = append(commands, minio.DeletePrefixCommand(k.K8sClient.Namespace(), instance.UploadedFilesPrefix(k.Scope), instance.FilesBucketName))
+	}
 	// Collects all resources (pods, services, etc.) within the specified namespace that match a specific label, excluding certain types,
 	// and then deletes them. This is useful for cleaning up specific test resources before proceeding to delete the namespace.
 	commands = append(commands,
-		fmt.Sprintf("kubectl get all,pvc,netpol,roles,serviceaccounts,rolebindings,configmaps -l knuu.sh/scope=%s -n %s -o json | jq -r '.items[] | select(.metadata.labels.\"knuu.sh/type\" != \"%s\") | \"\\(.kind)/\\(.metadata.name)\"' | xargs -r kubectl delete -n %s",
+		fmt.Sprintf("kubectl get all,pvc,netpol,roles,serviceaccounts,rolebindings,configmaps,secrets -l knuu.sh/scope=%s -n %s -o json | jq -r '.items[] | select(.metadata.labels.\"knuu.sh/type\" != \"%s\") | \"\\(.kind)/\\(.metadata.name)\"' | xargs -r kubectl delete -n %s",
 			k.Scope, k.K8sClient.Namespace(), instance.TimeoutHandlerInstance.String(), k.K8sClient.Namespace()))
 
+	// Delete the volume snapshots of the scope, they are not part of 'all' and their CRDs may not be installed in the cluster.
+	commands = append(commands, fmt.Sprintf("(kubectl delete volumesnapshots -l knuu.sh/scope=%s -n %s || true)", k.Scope, k.K8sClient.Namespace()))
+
 	// Delete the namespace as it was created by knuu.
 	k.Logger.WithField("namespace", k.K8sClient.Namespace()).Debug("the namespace will be deleted")
 	commands = append(commands, fmt.Sprintf("kubectl delete namespace %s", k.K8sClient.Namespace()))
 
 	// Delete all labeled resources within the namespace.
 	// Unlike the previous command that excludes certain types, this command ensures that everything remaining is deleted.
-	commands = append(commands, fmt.Sprintf("kubectl delete all,pvc,netpol,roles,serviceaccounts,rolebindings,configmaps -l knuu.sh/scope=%s -n %s", k.Scope, k.K8sClient.Namespace()))
+	commands = append(commands, fmt.Sprintf("kubectl delete all,pvc,netpol,roles,serviceaccounts,rolebindings,configmaps,secrets -l knuu.sh/scope=%s -n %s", k.Scope, k.K8sClient.Namespace()))
 
 	finalCmd := strings.Join(commands, " && ")
 
@@ -189,6 +222,18 @@ func validateOptions(opts Options) error {
 		k8s.SanitizeName(opts.Scope) != opts.K8sClient.Namespace() {
 		return ErrScopeMismatch.WithParams(opts.Scope, opts.K8sClient.Namespace())
 	}
+
+	if err := opts.Registry.Validate(); err != nil {
+		return ErrInvalidRegistry.Wrap(err)
+	}
+	for _, r := range opts.RegistryCredentials {
+		if r == nil || r.Auth == nil {
+			return ErrRegistryCredentialsWithoutAuth
+		}
+		if err := r.Validate(); err != nil {
+			return ErrInvalidRegistry.Wrap(err)
+		}
+	}
 	return nil
 }
 
@@ -219,6 +264,10 @@ func setDefaults(ctx context.Context, k *Knuu) error {
 		}
 	}
 
+	if k.Registry == nil {
+		k.Registry = builder.DefaultRegistry()
+	}
+
 	if k.ImageBuilder == nil {
 		k.ImageBuilder = &kaniko.Kaniko{
 			SystemDependencies: k.SystemDependencies,
@@ -228,6 +277,34 @@ func setDefaults(ctx context.Context, k *Knuu) error {
 	return nil
 }
 
+// setupRegistryCredentials creates the image pull secret of the scope
+// if the registry or any of the registry credentials needs authentication
+func setupRegistryCredentials(ctx context.Context, k *Knuu) error {
+	registries := append([]*builder.Registry{k.Registry}, k.RegistryCredentials...)
+	if !builder.HasAuth(registries...) {
+		return nil
+	}
+
+	dockerConfig, err := builder.DockerConfigJSON(registries...)
+	if err != nil {
+		return ErrCreatingRegistryCredentials.Wrap(err)
+	}
+
+	labels := map[string]string{
+		instance.LabelScopeKey:     k.Scope,
+		instance.LabelManagedByKey: instance.LabelKnuuValue,
+	}
+	_, err = k.K8sClient.CreateOrUpdateSecret(ctx, registryCredentialsSecretName, labels,
+		v1.SecretTypeDockerConfigJson, map[string][]byte{v1.DockerConfigJsonKey: dockerConfig})
+	if err != nil {
+		return ErrCreatingRegistryCredentials.Wrap(err)
+	}
+	k.ImagePullSecret = registryCredentialsSecretName
+
+	k.Logger.WithField("secret", registryCredentialsSecretName).Debug("created the image pull secret of the scope")
+	return nil
+}
+
 func setupProxy(ctx context.Context, k *Knuu) error {
 	k.Proxy = &traefik.Traefik{
 		K8sClient: k.K8sClient,
@@ -12,6 +12,7 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/celestiaorg/knuu/pkg/builder"
 	"github.com/celestiaorg/knuu/pkg/builder/kaniko"
 	"github.com/celestiaorg/knuu/pkg/k8s"
 	"github.com/celestiaorg/knuu/pkg/minio"
@@ -182,6 +183,13 @@ func TestValidateOptions(t *testing.T) {
 			},
 			expectedErr: ErrScopeMismatch.WithParams("another_scope", "test"),
 		},
+		{
+			name: "Registry credentials without auth",
+			options: Options{
+				RegistryCredentials: []*builder.Registry{{Host: "ghcr.io"}},
+			},
+			expectedErr: ErrRegistryCredentialsWithoutAuth,
+		},
 		{
 			name:        "No options set",
 			options:     Options{},
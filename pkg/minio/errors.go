@@ -41,5 +41,6 @@ var (
 	ErrMinioFailedToCreatePersistentVolume      = errors.New("MinioFailedToCreatePersistentVolume", "failed to create PersistentVolume")
 	ErrMinioFailedToCreatePersistentVolumeClaim = errors.New("MinioFailedToCreatePersistentVolumeClaim", "failed to create PersistentVolumeClaim")
 	ErrMinioClientNotInitialized                = errors.New("MinioClientNotInitialized", "Minio client not initialized")
+	ErrMinioFailedToListFiles                   = errors.New("MinioFailedToListFiles", "failed to list the files with prefix %s")
 	ErrMinioNotInitialized                      = errors.New("MinioNotInitialized", "Minio not initialized")
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"sync"
 	"time"
 
@@ -26,7 +27,6 @@ const (
 	ServiceWebUIPort = 9001 // WebUI port
 	DeploymentName   = "minio"
 	Image            = "minio/minio:RELEASE.2024-03-30T09-41-56Z"
-	StorageClassName = "standard" // standard | gp2 | default
 	VolumeClaimName  = "minio-data"
 	VolumeMountPath  = "/data"
 
@@ -46,15 +46,32 @@ const (
 	envMinioRootPassword = "MINIO_ROOT_PASSWORD"
 )
 
+// StorageClassName was meant as the StorageClass of the data volume, it has never been used
+//
+// Deprecated: the data volume uses the default StorageClass of the cluster, set another one with WithStorageClass
+const StorageClassName = "standard"
+
 var (
 	PVCStorageSize = resource.MustParse("1Gi")
 )
 
 type Minio struct {
-	client    *miniogo.Client
-	k8sClient k8s.KubeManager
-	Logger    *logrus.Logger
-	muMap     map[string]*sync.Mutex
+	client       *miniogo.Client
+	k8sClient    k8s.KubeManager
+	Logger       *logrus.Logger
+	muMap        map[string]*sync.Mutex
+	storageClass string
+}
+
+// Option configures the minio deployment
+type Option func(*Minio)
+
+// WithStorageClass sets the StorageClass of the minio data volume
+// Without it, a hostPath PersistentVolume is created for the volume if the cluster does not provision one
+func WithStorageClass(storageClass string) Option {
+	return func(m *Minio) {
+		m.storageClass = storageClass
+	}
 }
 
 type Config struct {
@@ -63,11 +80,14 @@ type Config struct {
 	SecretAccessKey string
 }
 
-func New(ctx context.Context, k8sClient k8s.KubeManager, logger *logrus.Logger) (*Minio, error) {
+func New(ctx context.Context, k8sClient k8s.KubeManager, logger *logrus.Logger, opts ...Option) (*Minio, error) {
 	m := &Minio{
 		k8sClient: k8sClient,
 		Logger:    logger,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
 
 	if err := m.deployMinio(ctx); err != nil {
 		return nil, err
@@ -153,6 +173,89 @@ func (m *Minio) GetURL(ctx context.Context, minioFilePath, bucketName string) (s
 	return presignedURL.String(), nil
 }
 
+// GetInternalURL returns the URL of a Minio file through the minio service, for the pods of the namespace
+// Unlike the presigned URL of GetURL, it does not expire, so it can be set in the spec of the pods;
+// the requests must be signed with the credentials of GetConfigs (AWS signature v4 for the region us-east-1)
+func (m *Minio) GetInternalURL(minioFilePath, bucketName string) (string, error) {
+	if m == nil {
+		return "", ErrMinioNotInitialized
+	}
+
+	u := url.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s.%s:%d", ServiceName, m.k8sClient.Namespace(), ServiceAPIPort),
+		Path:   "/" + bucketName + "/" + minioFilePath,
+	}
+	return u.String(), nil
+}
+
+// DeletePrefix deletes all the files of the bucket whose path starts with the prefix
+// It does nothing if the bucket does not exist
+func (m *Minio) DeletePrefix(ctx context.Context, prefix, bucketName string) error {
+	if m == nil {
+		return ErrMinioNotInitialized
+	}
+
+	exists, err := m.client.BucketExists(ctx, bucketName)
+	if err != nil {
+		return ErrMinioFailedToCheckBucket.Wrap(err)
+	}
+	if !exists {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		listErr error
+		objects = make(chan miniogo.ObjectInfo)
+	)
+	go func() {
+		defer close(objects)
+		for object := range m.client.ListObjects(ctx, bucketName, miniogo.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+			if object.Err != nil {
+				listErr = object.Err
+				return
+			}
+			select {
+			case objects <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// the errors channel is drained so that the removal goes through all the files
+	var removeErr error
+	for rErr := range m.client.RemoveObjects(ctx, bucketName, objects, miniogo.RemoveObjectsOptions{}) {
+		if removeErr == nil {
+			removeErr = rErr.Err
+		}
+	}
+	if listErr != nil {
+		return ErrMinioFailedToListFiles.WithParams(prefix).Wrap(listErr)
+	}
+	if removeErr != nil {
+		return ErrMinioFailedToDeleteFile.Wrap(removeErr)
+	}
+
+	m.Logger.WithFields(logrus.Fields{
+		"prefix": prefix,
+		"bucket": bucketName,
+	}).Debug("Files deleted successfully")
+	return nil
+}
+
+// DeletePrefixCommand returns a shell command deleting the files of the bucket whose path starts with the prefix,
+// for the places where the minio client cannot be used, e.g. the timeout handler which only has kubectl
+// The files are deleted with the mc client of the minio deployment in the namespace, the command does not fail
+// if minio is not deployed
+func DeletePrefixCommand(namespace, prefix, bucketName string) string {
+	return fmt.Sprintf(`(kubectl exec -n %s deploy/%s -- sh -c 'mc alias set knuu http://localhost:%d "$%s" "$%s" >/dev/null && mc rm --recursive --force knuu/%s/%s' || true)`,
+		namespace, DeploymentName, ServiceAPIPort, envMinioRootUser, envMinioRootPassword, bucketName, prefix)
+}
+
 func (m *Minio) GetConfigs(ctx context.Context) (*Config, error) {
 	if m == nil {
 		return nil, ErrMinioNotInitialized
@@ -424,7 +527,42 @@ func (m *Minio) createPVC(ctx context.Context, pvcName string, storageSize resou
 		return nil
 	}
 
-	// Create a simple PersistentVolume if no suitable one is found
+	if m.storageClass == "" {
+		if err := m.createPV(ctx, storageSize, createOptions); err != nil {
+			return err
+		}
+	}
+
+	// Create PVC with the existing or newly created PV
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvcName,
+			Namespace: m.k8sClient.Namespace(),
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			Resources: v1.VolumeResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: storageSize,
+				},
+			},
+		},
+	}
+	if m.storageClass != "" {
+		pvc.Spec.StorageClassName = &m.storageClass
+	}
+
+	_, err = pvcClient.Create(ctx, pvc, createOptions)
+	if err != nil {
+		return ErrMinioFailedToCreatePersistentVolumeClaim.Wrap(err)
+	}
+
+	m.Logger.WithField("pvc", pvcName).Debug("PersistentVolumeClaim created successfully.")
+	return nil
+}
+
+// createPV creates a simple hostPath PersistentVolume for the minio data volume if no suitable one is found
+func (m *Minio) createPV(ctx context.Context, storageSize resource.Quantity, createOptions metav1.CreateOptions) error {
 	pvList, err := m.k8sClient.Clientset().CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return ErrMinioFailedToListPersistentVolumes.Wrap(err)
@@ -462,28 +600,5 @@ func (m *Minio) createPVC(ctx context.Context, pvcName string, storageSize resou
 		}
 	}
 	m.Logger.WithField("pv", existingPV.Name).Debug("PersistentVolume created successfully.")
-
-	// Create PVC with the existing or newly created PV
-	pvc := &v1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pvcName,
-			Namespace: m.k8sClient.Namespace(),
-		},
-		Spec: v1.PersistentVolumeClaimSpec{
-			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
-			Resources: v1.VolumeResourceRequirements{
-				Requests: v1.ResourceList{
-					v1.ResourceStorage: storageSize,
-				},
-			},
-		},
-	}
-
-	_, err = pvcClient.Create(ctx, pvc, createOptions)
-	if err != nil {
-		return ErrMinioFailedToCreatePersistentVolumeClaim.Wrap(err)
-	}
-
-	m.Logger.WithField("pvc", pvcName).Debug("PersistentVolumeClaim created successfully.")
 	return nil
 }
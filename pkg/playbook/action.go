@@ -0,0 +1,161 @@
+package playbook
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+	"github.com/celestiaorg/knuu/pkg/sidecars/netshaper"
+)
+
+// Action is applied to each instance selected by a step
+type Action interface {
+	Apply(ctx context.Context, inst *instance.Instance) error
+	String() string
+}
+
+type actionFunc struct {
+	name string
+	fn   func(ctx context.Context, inst *instance.Instance) error
+}
+
+// ActionFunc wraps a custom function as an action, the name is used in the audit trail
+func ActionFunc(name string, fn func(ctx context.Context, inst *instance.Instance) error) Action {
+	return &actionFunc{name: name, fn: fn}
+}
+
+func (a *actionFunc) Apply(ctx context.Context, inst *instance.Instance) error {
+	return a.fn(ctx, inst)
+}
+
+func (a *actionFunc) String() string {
+	return a.name
+}
+
+// SetBandwidthLimit limits the bandwidth of the instance in bps using its netshaper sidecar
+func SetBandwidthLimit(limit int64) Action {
+	return ActionFunc(fmt.Sprintf("set bandwidth limit to %d bps", limit),
+		func(ctx context.Context, inst *instance.Instance) error {
+			ns, err := findNetShaper(inst)
+			if err != nil {
+				return err
+			}
+			if err := ns.SetBandwidthLimit(limit); err != nil {
+				return ErrSettingBandwidthLimit.WithParams(inst.Name()).Wrap(err)
+			}
+			return nil
+		})
+}
+
+// SetLatencyAndJitter sets the latency and jitter of the instance in ms using its netshaper sidecar
+func SetLatencyAndJitter(latency, jitter int64) Action {
+	return ActionFunc(fmt.Sprintf("set latency to %dms and jitter to %dms", latency, jitter),
+		func(ctx context.Context, inst *instance.Instance) error {
+			ns, err := findNetShaper(inst)
+			if err != nil {
+				return err
+			}
+			if err := ns.SetLatencyAndJitter(latency, jitter); err != nil {
+				return ErrSettingLatencyAndJitter.WithParams(inst.Name()).Wrap(err)
+			}
+			return nil
+		})
+}
+
+// SetPacketLoss sets the packet loss of the instance in percent using its netshaper sidecar
+func SetPacketLoss(packetLoss int32) Action {
+	return ActionFunc(fmt.Sprintf("set packet loss to %d%%", packetLoss),
+		func(ctx context.Context, inst *instance.Instance) error {
+			ns, err := findNetShaper(inst)
+			if err != nil {
+				return err
+			}
+			if err := ns.SetPacketLoss(packetLoss); err != nil {
+				return ErrSettingPacketLoss.WithParams(inst.Name()).Wrap(err)
+			}
+			return nil
+		})
+}
+
+// DisableNetwork cuts the network of the instance, see instance.Network().Disable()
+func DisableNetwork() Action {
+	return ActionFunc("disable network", func(ctx context.Context, inst *instance.Instance) error {
+		if err := inst.Network().Disable(ctx); err != nil {
+			return ErrDisablingNetwork.WithParams(inst.Name()).Wrap(err)
+		}
+		return nil
+	})
+}
+
+// EnableNetwork restores the network of the instance, see instance.Network().Enable()
+func EnableNetwork() Action {
+	return ActionFunc("enable network", func(ctx context.Context, inst *instance.Instance) error {
+		if err := inst.Network().Enable(ctx); err != nil {
+			return ErrEnablingNetwork.WithParams(inst.Name()).Wrap(err)
+		}
+		return nil
+	})
+}
+
+// Stop stops the instance
+func Stop() Action {
+	return ActionFunc("stop", func(ctx context.Context, inst *instance.Instance) error {
+		if err := inst.Execution().Stop(ctx); err != nil {
+			return ErrStoppingInstance.WithParams(inst.Name()).Wrap(err)
+		}
+		return nil
+	})
+}
+
+// Start starts a stopped instance and waits until it is running
+func Start() Action {
+	return ActionFunc("start", func(ctx context.Context, inst *instance.Instance) error {
+		if err := inst.Execution().Start(ctx); err != nil {
+			return ErrStartingInstance.WithParams(inst.Name()).Wrap(err)
+		}
+		return nil
+	})
+}
+
+// ExecuteCommand executes the command in the instance
+func ExecuteCommand(command ...string) Action {
+	return ActionFunc(fmt.Sprintf("execute %q", strings.Join(command, " ")),
+		func(ctx context.Context, inst *instance.Instance) error {
+			if _, err := inst.Execution().ExecuteCommand(ctx, command...); err != nil {
+				return ErrExecutingCommand.WithParams(inst.Name()).Wrap(err)
+			}
+			return nil
+		})
+}
+
+// WriteFile writes the content to the file in the running instance, the file is created or overwritten
+// The content is passed as an argument of the command, so it is meant for small files such as configs
+func WriteFile(dest string, content []byte) Action {
+	return ActionFunc(fmt.Sprintf("write file '%s'", dest),
+		func(ctx context.Context, inst *instance.Instance) error {
+			script := fmt.Sprintf("mkdir -p %s && echo %s | base64 -d > %s",
+				shellQuote(filepath.Dir(dest)), base64.StdEncoding.EncodeToString(content), shellQuote(dest))
+			// the command is already run by a shell in the instance
+			if _, err := inst.Execution().ExecuteCommand(ctx, script); err != nil {
+				return ErrWritingFile.WithParams(dest, inst.Name()).Wrap(err)
+			}
+			return nil
+		})
+}
+
+// findNetShaper returns the netshaper sidecar of the instance
+func findNetShaper(inst *instance.Instance) (*netshaper.NetShaper, error) {
+	for _, sc := range inst.Sidecars().List() {
+		if ns, ok := sc.(*netshaper.NetShaper); ok {
+			return ns, nil
+		}
+	}
+	return nil, ErrNetShaperNotFound.WithParams(inst.Name())
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,36 @@
+package playbook
+
+import (
+	"github.com/celestiaorg/knuu/pkg/errors"
+)
+
+type Error = errors.Error
+
+var (
+	ErrStepNameRequired          = errors.New("StepNameRequired", "name is required for step at index %d")
+	ErrDuplicateStepName         = errors.New("DuplicateStepName", "step '%s' is defined more than once")
+	ErrStepTriggerRequired       = errors.New("StepTriggerRequired", "trigger is required for step '%s'")
+	ErrStepTargetsRequired       = errors.New("StepTargetsRequired", "targets are required for step '%s'")
+	ErrStepActionRequired        = errors.New("StepActionRequired", "action is required for step '%s'")
+	ErrWaitingForTrigger         = errors.New("WaitingForTrigger", "error waiting for the trigger of step '%s'")
+	ErrSelectingTargets          = errors.New("SelectingTargets", "error selecting the targets of step '%s'")
+	ErrApplyingAction            = errors.New("ApplyingAction", "error applying action '%s' of step '%s' to instance '%s'")
+	ErrInvalidPercentage         = errors.New("InvalidPercentage", "percentage must be between 0 and 100, got %v")
+	ErrNoInstancesToSelect       = errors.New("NoInstancesToSelect", "no instances to select from")
+	ErrInvalidPollInterval       = errors.New("InvalidPollInterval", "poll interval must be positive, got '%s'")
+	ErrTriggerTimeout            = errors.New("TriggerTimeout", "trigger '%s' was not met")
+	ErrExecutingProbeCommand     = errors.New("ExecutingProbeCommand", "error executing probe command in instance '%s'")
+	ErrCreatingHTTPProbeRequest  = errors.New("CreatingHTTPProbeRequest", "error creating http probe request for '%s'")
+	ErrNetShaperNotFound         = errors.New("NetShaperNotFound", "instance '%s' has no netshaper sidecar")
+	ErrSettingBandwidthLimit     = errors.New("SettingBandwidthLimit", "error setting bandwidth limit of instance '%s'")
+	ErrSettingLatencyAndJitter   = errors.New("SettingLatencyAndJitter", "error setting latency and jitter of instance '%s'")
+	ErrSettingPacketLoss         = errors.New("SettingPacketLoss", "error setting packet loss of instance '%s'")
+	ErrDisablingNetwork          = errors.New("DisablingNetwork", "error disabling network of instance '%s'")
+	ErrEnablingNetwork           = errors.New("EnablingNetwork", "error enabling network of instance '%s'")
+	ErrStoppingInstance          = errors.New("StoppingInstance", "error stopping instance '%s'")
+	ErrStartingInstance          = errors.New("StartingInstance", "error starting instance '%s'")
+	ErrExecutingCommand          = errors.New("ExecutingCommand", "error executing command in instance '%s'")
+	ErrWritingFile               = errors.New("WritingFile", "error writing file '%s' in instance '%s'")
+	ErrPlaybookAlreadyRunning    = errors.New("PlaybookAlreadyRunning", "playbook is already running")
+	ErrWaitingForInstanceRunning = errors.New("WaitingForInstanceRunning", "error waiting for instance '%s' to be running")
+)
@@ -0,0 +1,210 @@
+// Package playbook schedules actions against running instances, as described in ADR-001.
+//
+// A playbook is a list of steps. Each step waits for its trigger, selects its targets and applies its action to each of them,
+// e.g. "when the height of validator-0 reaches 10, limit the bandwidth of 15% of the validators to 10Mbps":
+//
+//	pb := playbook.New(logger)
+//	err := pb.AddStep(playbook.Step{
+//		Name:    "throttle-validators",
+//		Trigger: playbook.WhenCommandOutput(validators[0], playbook.OutputAtLeast(10), "sh", "-c", "get-height"),
+//		Targets: playbook.Percentage(15, validators...),
+//		Action:  playbook.SetBandwidthLimit(10_000_000),
+//	})
+//	...
+//	err = pb.Run(ctx)
+//	for _, record := range pb.Records() { ... }
+package playbook
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const defaultPollInterval = 5 * time.Second
+
+// Step is a single scheduled action of a playbook
+type Step struct {
+	Name    string
+	Trigger Trigger
+	Targets Selector
+	Action  Action
+}
+
+// Record is an entry of the audit trail of a playbook
+type Record struct {
+	Time     time.Time
+	Step     string
+	Trigger  string
+	Instance string
+	Action   string
+	// Err is nil if the action was applied successfully
+	Err error
+}
+
+// Playbook runs steps against instances and records every applied action
+type Playbook struct {
+	logger       *logrus.Logger
+	steps        []Step
+	pollInterval time.Duration
+	rand         *rand.Rand
+
+	mu      sync.Mutex
+	records []Record
+	running bool
+}
+
+// New creates an empty playbook
+func New(logger *logrus.Logger) *Playbook {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Playbook{
+		logger:       logger,
+		pollInterval: defaultPollInterval,
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetPollInterval sets the interval between two checks of the probe triggers
+func (p *Playbook) SetPollInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return ErrInvalidPollInterval.WithParams(interval.String())
+	}
+	p.pollInterval = interval
+	return nil
+}
+
+// SetSeed makes the percentage based selection of the targets reproducible
+func (p *Playbook) SetSeed(seed int64) {
+	p.rand = rand.New(rand.NewSource(seed))
+}
+
+// AddStep adds a step to the playbook
+func (p *Playbook) AddStep(step Step) error {
+	switch {
+	case step.Name == "":
+		return ErrStepNameRequired.WithParams(len(p.steps))
+	case step.Trigger == nil:
+		return ErrStepTriggerRequired.WithParams(step.Name)
+	case step.Targets == nil:
+		return ErrStepTargetsRequired.WithParams(step.Name)
+	case step.Action == nil:
+		return ErrStepActionRequired.WithParams(step.Name)
+	}
+	for _, s := range p.steps {
+		if s.Name == step.Name {
+			return ErrDuplicateStepName.WithParams(step.Name)
+		}
+	}
+	p.steps = append(p.steps, step)
+	return nil
+}
+
+// Run waits for the triggers of all the steps concurrently and applies their actions when they fire
+// The delays of the triggers are relative to the start of Run
+// If an action fails, the remaining steps are cancelled and the first error is returned
+// The failed action is part of the audit trail as well
+func (p *Playbook) Run(ctx context.Context) error {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return ErrPlaybookAlreadyRunning
+	}
+	p.running = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.running = false
+		p.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, step := range p.steps {
+		wg.Add(1)
+		go func(step Step) {
+			defer wg.Done()
+			if err := p.runStep(ctx, step); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(step)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// Records returns the audit trail of the applied actions in the order they were applied
+func (p *Playbook) Records() []Record {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	records := make([]Record, len(p.records))
+	copy(records, p.records)
+	return records
+}
+
+func (p *Playbook) runStep(ctx context.Context, step Step) error {
+	if err := step.Trigger.Wait(ctx, p.pollInterval); err != nil {
+		return ErrWaitingForTrigger.WithParams(step.Name).Wrap(err)
+	}
+
+	p.mu.Lock()
+	targets, err := step.Targets.Select(p.rand)
+	p.mu.Unlock()
+	if err != nil {
+		return ErrSelectingTargets.WithParams(step.Name).Wrap(err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"step":    step.Name,
+		"trigger": step.Trigger.String(),
+		"targets": step.Targets.String(),
+	}).Debug("playbook step triggered")
+
+	for _, inst := range targets {
+		err := step.Action.Apply(ctx, inst)
+		p.record(Record{
+			Time:     time.Now().UTC(),
+			Step:     step.Name,
+			Trigger:  step.Trigger.String(),
+			Instance: inst.Name(),
+			Action:   step.Action.String(),
+			Err:      err,
+		})
+		if err != nil {
+			return ErrApplyingAction.WithParams(step.Action.String(), step.Name, inst.Name()).Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (p *Playbook) record(r Record) {
+	p.mu.Lock()
+	p.records = append(p.records, r)
+	p.mu.Unlock()
+
+	entry := p.logger.WithFields(logrus.Fields{
+		"step":     r.Step,
+		"instance": r.Instance,
+		"action":   r.Action,
+		"time":     r.Time.Format(time.RFC3339Nano),
+	})
+	if r.Err != nil {
+		entry.WithError(r.Err).Error("playbook action failed")
+		return
+	}
+	entry.Info("playbook action applied")
+}
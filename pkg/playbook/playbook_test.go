@@ -0,0 +1,240 @@
+package playbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	discfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+	"github.com/celestiaorg/knuu/pkg/k8s"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+func TestRun(t *testing.T) {
+	validators := newTestInstances(t, "validator", 20)
+	var probeCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if probeCalls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"height": 10}`))
+	}))
+	defer server.Close()
+
+	pb := New(logrus.New())
+	require.NoError(t, pb.SetPollInterval(10*time.Millisecond))
+	pb.SetSeed(1)
+
+	require.NoError(t, pb.AddStep(Step{
+		Name:    "throttle",
+		Trigger: WhenHTTP(server.URL, BodyContains(`"height": 10`)),
+		Targets: Percentage(15, validators...),
+		Action:  ActionFunc("noop", func(ctx context.Context, inst *instance.Instance) error { return nil }),
+	}))
+	require.NoError(t, pb.AddStep(Step{
+		Name:    "later",
+		Trigger: After(20 * time.Millisecond),
+		Targets: All(validators[0]),
+		Action:  ActionFunc("noop", func(ctx context.Context, inst *instance.Instance) error { return nil }),
+	}))
+
+	start := time.Now()
+	require.NoError(t, pb.Run(context.Background()))
+
+	records := pb.Records()
+	require.Len(t, records, 4)
+	counts := map[string]int{}
+	for _, r := range records {
+		counts[r.Step]++
+		assert.NoError(t, r.Err)
+		assert.False(t, r.Time.Before(start))
+	}
+	assert.Equal(t, map[string]int{"throttle": 3, "later": 1}, counts)
+	assert.GreaterOrEqual(t, probeCalls.Load(), int32(3))
+}
+
+func TestRunFailingAction(t *testing.T) {
+	instances := newTestInstances(t, "node", 2)
+	errAction := errors.New("action failed")
+
+	pb := New(logrus.New())
+	require.NoError(t, pb.AddStep(Step{
+		Name:    "fail",
+		Trigger: After(0),
+		Targets: All(instances...),
+		Action:  ActionFunc("fail", func(ctx context.Context, inst *instance.Instance) error { return errAction }),
+	}))
+	require.NoError(t, pb.AddStep(Step{
+		Name:    "never",
+		Trigger: After(time.Hour),
+		Targets: All(instances...),
+		Action:  ActionFunc("noop", func(ctx context.Context, inst *instance.Instance) error { return nil }),
+	}))
+
+	err := pb.Run(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrApplyingAction)
+
+	records := pb.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, "fail", records[0].Step)
+	assert.Equal(t, "node-0", records[0].Instance)
+	assert.ErrorIs(t, records[0].Err, errAction)
+}
+
+func TestAddStep(t *testing.T) {
+	inst := newTestInstances(t, "node", 1)
+	noop := ActionFunc("noop", func(ctx context.Context, inst *instance.Instance) error { return nil })
+
+	tests := []struct {
+		name        string
+		step        Step
+		expectedErr error
+	}{
+		{
+			name: "valid step",
+			step: Step{Name: "a", Trigger: After(0), Targets: All(inst...), Action: noop},
+		},
+		{
+			name:        "missing name",
+			step:        Step{Trigger: After(0), Targets: All(inst...), Action: noop},
+			expectedErr: ErrStepNameRequired,
+		},
+		{
+			name:        "missing trigger",
+			step:        Step{Name: "b", Targets: All(inst...), Action: noop},
+			expectedErr: ErrStepTriggerRequired,
+		},
+		{
+			name:        "missing action",
+			step:        Step{Name: "b", Trigger: After(0), Targets: All(inst...)},
+			expectedErr: ErrStepActionRequired,
+		},
+		{
+			name:        "duplicate name",
+			step:        Step{Name: "a", Trigger: After(0), Targets: All(inst...), Action: noop},
+			expectedErr: ErrDuplicateStepName,
+		},
+	}
+
+	pb := New(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pb.AddStep(tt.step)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	instances := newTestInstances(t, "light", 7)
+	r := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		percentage  float64
+		expected    int
+		expectedErr error
+	}{
+		{percentage: 0, expected: 0},
+		{percentage: 10, expected: 1},
+		{percentage: 30, expected: 3},
+		{percentage: 100, expected: 7},
+		{percentage: 120, expectedErr: ErrInvalidPercentage},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v%%", tt.percentage), func(t *testing.T) {
+			selected, err := Percentage(tt.percentage, instances...).Select(r)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, selected, tt.expected)
+
+			unique := map[*instance.Instance]bool{}
+			for _, inst := range selected {
+				unique[inst] = true
+			}
+			assert.Len(t, unique, tt.expected)
+		})
+	}
+}
+
+func TestOutputAtLeast(t *testing.T) {
+	match := OutputAtLeast(10)
+	assert.True(t, match("10\n"))
+	assert.True(t, match("42"))
+	assert.False(t, match("9"))
+	assert.False(t, match("not a number"))
+}
+
+// execRecorder records the commands run in the pods instead of executing them
+type execRecorder struct {
+	k8s.KubeManager
+	commands [][]string
+}
+
+func (r *execRecorder) GetFirstPodFromReplicaSet(_ context.Context, name string) (*v1.Pod, error) {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name + "-0"}}, nil
+}
+
+func (r *execRecorder) RunCommandInPod(_ context.Context, _, _ string, cmd []string) (string, error) {
+	r.commands = append(r.commands, cmd)
+	return "", nil
+}
+
+func TestCommandActions(t *testing.T) {
+	ctx := context.Background()
+	inst := newTestInstances(t, "node", 1)[0]
+	recorder := &execRecorder{KubeManager: inst.K8sClient}
+	inst.K8sClient = recorder
+	require.NoError(t, inst.Build().SetImage(ctx, "alpine:latest"))
+	require.NoError(t, inst.Build().Commit(ctx))
+	require.NoError(t, inst.Execution().StartAsync(ctx))
+
+	require.NoError(t, ExecuteCommand("celestia-appd", "status").Apply(ctx, inst))
+	require.NoError(t, WriteFile("/home/celestia/it's.toml", []byte("key = 1\n")).Apply(ctx, inst))
+
+	assert.Equal(t, [][]string{
+		{"/bin/sh", "-c", "celestia-appd status"},
+		{"/bin/sh", "-c", `mkdir -p '/home/celestia' && echo a2V5ID0gMQo= | base64 -d > '/home/celestia/it'\''s.toml'`},
+	}, recorder.commands)
+}
+
+func newTestInstances(t *testing.T, prefix string, count int) []*instance.Instance {
+	k8sClient, err := k8s.NewClientCustom(context.Background(), fake.NewSimpleClientset(), &discfake.FakeDiscovery{Fake: &k8stesting.Fake{}}, nil, "test", logrus.New())
+	require.NoError(t, err)
+	sysDeps := &system.SystemDependencies{
+		K8sClient: k8sClient,
+		Logger:    logrus.New(),
+		Scope:     "test",
+	}
+
+	instances := make([]*instance.Instance, count)
+	for idx := range instances {
+		instances[idx], err = instance.New(fmt.Sprintf("%s-%d", prefix, idx), sysDeps)
+		require.NoError(t, err)
+	}
+	return instances
+}
@@ -0,0 +1,78 @@
+package playbook
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+)
+
+// Selector picks the instances an action is applied to
+// It is evaluated when the trigger of the step fires
+type Selector interface {
+	Select(r *rand.Rand) ([]*instance.Instance, error)
+	String() string
+}
+
+type allSelector struct {
+	instances []*instance.Instance
+}
+
+// All selects all the given instances
+func All(instances ...*instance.Instance) Selector {
+	return &allSelector{instances: instances}
+}
+
+// Group selects all the instances of the group
+func Group(g *instance.Group) Selector {
+	return &allSelector{instances: g.Instances()}
+}
+
+func (s *allSelector) Select(_ *rand.Rand) ([]*instance.Instance, error) {
+	if len(s.instances) == 0 {
+		return nil, ErrNoInstancesToSelect
+	}
+	return s.instances, nil
+}
+
+func (s *allSelector) String() string {
+	return fmt.Sprintf("all of %d instances", len(s.instances))
+}
+
+type percentageSelector struct {
+	percentage float64
+	instances  []*instance.Instance
+}
+
+// Percentage selects the given percentage of the instances at random
+// The number of selected instances is rounded up, so any percentage above 0 selects at least one instance
+func Percentage(percentage float64, instances ...*instance.Instance) Selector {
+	return &percentageSelector{percentage: percentage, instances: instances}
+}
+
+// GroupPercentage selects the given percentage of the instances of the group at random
+func GroupPercentage(percentage float64, g *instance.Group) Selector {
+	return Percentage(percentage, g.Instances()...)
+}
+
+func (s *percentageSelector) Select(r *rand.Rand) ([]*instance.Instance, error) {
+	if s.percentage < 0 || s.percentage > 100 {
+		return nil, ErrInvalidPercentage.WithParams(s.percentage)
+	}
+	if len(s.instances) == 0 {
+		return nil, ErrNoInstancesToSelect
+	}
+
+	// the epsilon avoids rounding up float imprecisions, e.g. 3.0000000000000004 instances
+	count := int(math.Ceil(float64(len(s.instances))*s.percentage/100 - 1e-9))
+	selected := make([]*instance.Instance, 0, count)
+	for _, idx := range r.Perm(len(s.instances))[:count] {
+		selected = append(selected, s.instances[idx])
+	}
+	return selected, nil
+}
+
+func (s *percentageSelector) String() string {
+	return fmt.Sprintf("%v%% of %d instances", s.percentage, len(s.instances))
+}
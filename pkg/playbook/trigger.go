@@ -0,0 +1,164 @@
+package playbook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+)
+
+// Trigger decides when the action of a step is applied
+type Trigger interface {
+	// Wait blocks until the trigger fires or the context is done
+	// Triggers that need to poll a condition use the given interval between two checks
+	Wait(ctx context.Context, pollInterval time.Duration) error
+	String() string
+}
+
+type delayTrigger struct {
+	delay time.Duration
+}
+
+// After fires once the given delay has elapsed since the playbook started
+func After(delay time.Duration) Trigger {
+	return &delayTrigger{delay: delay}
+}
+
+func (t *delayTrigger) Wait(ctx context.Context, _ time.Duration) error {
+	timer := time.NewTimer(t.delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *delayTrigger) String() string {
+	return fmt.Sprintf("after %s", t.delay)
+}
+
+type timeTrigger struct {
+	at time.Time
+}
+
+// At fires at the given wall-clock time, or immediately if it is in the past
+func At(at time.Time) Trigger {
+	return &timeTrigger{at: at}
+}
+
+func (t *timeTrigger) Wait(ctx context.Context, pollInterval time.Duration) error {
+	return (&delayTrigger{delay: time.Until(t.at)}).Wait(ctx, pollInterval)
+}
+
+func (t *timeTrigger) String() string {
+	return fmt.Sprintf("at %s", t.at.Format(time.RFC3339))
+}
+
+// conditionTrigger fires once the check returns true
+// Errors of the check are considered as the condition not being met yet, e.g. while the probed instance starts
+type conditionTrigger struct {
+	description string
+	check       func(ctx context.Context) (bool, error)
+}
+
+func (t *conditionTrigger) Wait(ctx context.Context, pollInterval time.Duration) error {
+	for {
+		met, err := t.check(ctx)
+		if err == nil && met {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ErrTriggerTimeout.WithParams(t.description).Wrap(ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (t *conditionTrigger) String() string {
+	return t.description
+}
+
+// OutputMatcher checks the output of a probe command
+type OutputMatcher func(output string) bool
+
+// OutputContains matches when the output contains the given string
+func OutputContains(s string) OutputMatcher {
+	return func(output string) bool {
+		return strings.Contains(output, s)
+	}
+}
+
+// OutputAtLeast matches when the output is a number greater than or equal to the given value,
+// e.g. the block height returned by a command
+func OutputAtLeast(value float64) OutputMatcher {
+	return func(output string) bool {
+		n, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+		return err == nil && n >= value
+	}
+}
+
+// WhenCommandOutput fires once the output of the command executed in the instance matches
+func WhenCommandOutput(inst *instance.Instance, match OutputMatcher, command ...string) Trigger {
+	return &conditionTrigger{
+		description: fmt.Sprintf("when output of %q in '%s' matches", strings.Join(command, " "), inst.Name()),
+		check: func(ctx context.Context) (bool, error) {
+			output, err := inst.Execution().ExecuteCommand(ctx, command...)
+			if err != nil {
+				return false, ErrExecutingProbeCommand.WithParams(inst.Name()).Wrap(err)
+			}
+			return match(output), nil
+		},
+	}
+}
+
+// ResponseMatcher checks the response of an http probe
+type ResponseMatcher func(statusCode int, body []byte) bool
+
+// StatusCode matches when the response has the given status code
+func StatusCode(code int) ResponseMatcher {
+	return func(statusCode int, _ []byte) bool {
+		return statusCode == code
+	}
+}
+
+// BodyContains matches when the response is successful and its body contains the given string
+func BodyContains(s string) ResponseMatcher {
+	return func(statusCode int, body []byte) bool {
+		return statusCode >= 200 && statusCode < 300 && strings.Contains(string(body), s)
+	}
+}
+
+// WhenHTTP fires once the response of a GET request to the url matches
+// The url must be reachable from where the playbook runs, e.g. the one returned by instance.Network().AddHost()
+func WhenHTTP(url string, match ResponseMatcher) Trigger {
+	return &conditionTrigger{
+		description: fmt.Sprintf("when response of '%s' matches", url),
+		check: func(ctx context.Context) (bool, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return false, ErrCreatingHTTPProbeRequest.WithParams(url).Wrap(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return false, err
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return false, err
+			}
+			return match(resp.StatusCode, body), nil
+		},
+	}
+}
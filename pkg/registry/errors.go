@@ -0,0 +1,17 @@
+package registry
+
+import (
+	"github.com/celestiaorg/knuu/pkg/errors"
+)
+
+type Error = errors.Error
+
+var (
+	ErrCreatingDeployment        = errors.New("CreatingRegistryDeployment", "failed to create the registry deployment")
+	ErrGettingDeployment         = errors.New("GettingRegistryDeployment", "failed to get the registry deployment")
+	ErrCreatingService           = errors.New("CreatingRegistryService", "failed to create the registry service")
+	ErrGettingServiceIP          = errors.New("GettingRegistryServiceIP", "failed to get the IP of the registry service")
+	ErrTimeoutWaitingForRegistry = errors.New("TimeoutWaitingForRegistry", "timeout waiting for the registry to be ready")
+	ErrDeletingDeployment        = errors.New("DeletingRegistryDeployment", "failed to delete the registry deployment")
+	ErrDeletingService           = errors.New("DeletingRegistryService", "failed to delete the registry service")
+)
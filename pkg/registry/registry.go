@@ -0,0 +1,160 @@
+// Package registry deploys an image registry inside the test namespace,
+// so images can be built and pulled without pushing them to an external registry.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"github.com/celestiaorg/knuu/pkg/builder"
+	"github.com/celestiaorg/knuu/pkg/k8s"
+)
+
+const (
+	Name  = "knuu-registry"
+	Image = "registry:2"
+	Port  = 5000
+
+	appLabel   = "app"
+	scopeLabel = "knuu.sh/scope"
+	dataPath   = "/var/lib/registry"
+	dataVolume = "data"
+	waitRetry  = 2 * time.Second
+)
+
+// Deploy deploys a registry in the namespace of the k8s client and returns the registry to set in knuu.Options
+// The images are stored in an emptyDir, so they are lost when the registry pod restarts
+// The registry is served over plain HTTP on its ClusterIP, which is reachable from the pods (e.g. kaniko)
+// and from the nodes; the container runtime of the nodes must allow pulling from it as an insecure registry
+// (e.g. a containerd mirror config on kind)
+// Deploying the registry again reuses the existing one
+func Deploy(ctx context.Context, k8sClient k8s.KubeManager, logger *logrus.Logger) (*builder.Registry, error) {
+	labels := map[string]string{
+		appLabel:   Name,
+		scopeLabel: k8sClient.Namespace(),
+	}
+
+	if err := createDeployment(ctx, k8sClient, labels); err != nil {
+		return nil, err
+	}
+	if err := waitForDeployment(ctx, k8sClient); err != nil {
+		return nil, err
+	}
+
+	if _, err := k8sClient.GetService(ctx, Name); err != nil {
+		_, err := k8sClient.CreateService(ctx, Name, k8s.ServiceOptions{
+			Labels:      labels,
+			SelectorMap: map[string]string{appLabel: Name},
+			TCPPorts:    []int{Port},
+			NotHeadless: true,
+		})
+		if err != nil {
+			return nil, ErrCreatingService.Wrap(err)
+		}
+	}
+
+	ip, err := k8sClient.GetServiceIP(ctx, Name)
+	if err != nil {
+		return nil, ErrGettingServiceIP.Wrap(err)
+	}
+
+	reg := &builder.Registry{
+		Host:     fmt.Sprintf("%s:%d", ip, Port),
+		Insecure: true,
+	}
+	logger.WithFields(logrus.Fields{
+		"host":      reg.Host,
+		"namespace": k8sClient.Namespace(),
+	}).Debug("in-cluster registry deployed")
+	return reg, nil
+}
+
+// Delete deletes the registry deployed by Deploy and all its images
+func Delete(ctx context.Context, k8sClient k8s.KubeManager) error {
+	err := k8sClient.Clientset().AppsV1().Deployments(k8sClient.Namespace()).Delete(ctx, Name, metav1.DeleteOptions{})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return ErrDeletingDeployment.Wrap(err)
+	}
+	if err := k8sClient.DeleteService(ctx, Name); err != nil && !apierrs.IsNotFound(err) {
+		return ErrDeletingService.Wrap(err)
+	}
+	return nil
+}
+
+func createDeployment(ctx context.Context, k8sClient k8s.KubeManager, labels map[string]string) error {
+	deployments := k8sClient.Clientset().AppsV1().Deployments(k8sClient.Namespace())
+
+	_, err := deployments.Get(ctx, Name, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrs.IsNotFound(err) {
+		return ErrGettingDeployment.Wrap(err)
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name,
+			Namespace: k8sClient.Namespace(),
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{appLabel: Name},
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:  Name,
+						Image: Image,
+						Ports: []v1.ContainerPort{{ContainerPort: Port}},
+						VolumeMounts: []v1.VolumeMount{{
+							Name:      dataVolume,
+							MountPath: dataPath,
+						}},
+						ReadinessProbe: &v1.Probe{
+							ProbeHandler: v1.ProbeHandler{
+								TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt32(Port)},
+							},
+						},
+					}},
+					Volumes: []v1.Volume{{
+						Name:         dataVolume,
+						VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+					}},
+				},
+			},
+		},
+	}
+
+	if _, err := deployments.Create(ctx, deployment, metav1.CreateOptions{}); err != nil {
+		return ErrCreatingDeployment.Wrap(err)
+	}
+	return nil
+}
+
+func waitForDeployment(ctx context.Context, k8sClient k8s.KubeManager) error {
+	for {
+		deployment, err := k8sClient.Clientset().AppsV1().Deployments(k8sClient.Namespace()).Get(ctx, Name, metav1.GetOptions{})
+		if err == nil && deployment.Status.ReadyReplicas > 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ErrTimeoutWaitingForRegistry.Wrap(ctx.Err())
+		case <-time.After(waitRetry):
+		}
+	}
+}
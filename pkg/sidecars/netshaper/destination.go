@@ -0,0 +1,238 @@
+package netshaper
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/celestiaorg/bittwister/api/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+)
+
+const (
+	// unshapedRate is the rate of the tc classes of the destinations, the bandwidth limit itself is applied by netem
+	unshapedRate = "10gbit"
+	// classQuantum is the quantum of the tc classes of the destinations, one full ethernet frame
+	// Without it tc derives the quantum from the rate and warns on stderr that it is too big, which fails the command
+	classQuantum = 1514
+)
+
+// SetLatencyTo sets the latency and jitter of the traffic sent by the instance to the given peer instance
+// latency in ms (e.g. 1000 for 1s)
+// jitter in ms (e.g. 1000 for 1s)
+// The peer is resolved to its ephemeral IP, so this must be called again if the peer is restarted
+// The latency only applies to the traffic leaving the instance, the peer needs its own netshaper for the way back
+// This function can only be called once the instance and the peer are started
+func (bt *NetShaper) SetLatencyTo(ctx context.Context, peer *instance.Instance, latency, jitter int64) error {
+	destination, err := peerDestination(ctx, peer)
+	if err != nil {
+		return err
+	}
+	return bt.updateDestinations(ctx, func(destinations map[string]Profile) error {
+		profile := destinations[destination]
+		profile.Latency, profile.Jitter = latency, jitter
+		return setDestination(destinations, destination, profile)
+	})
+}
+
+// SetBandwidthLimitTo sets the bandwidth limit of the traffic sent by the instance to the given peer instance
+// bandwidth limit in bps (e.g. 1000 for 1Kbps)
+// The peer is resolved to its ephemeral IP, so this must be called again if the peer is restarted
+// This function can only be called once the instance and the peer are started
+func (bt *NetShaper) SetBandwidthLimitTo(ctx context.Context, peer *instance.Instance, limit int64) error {
+	destination, err := peerDestination(ctx, peer)
+	if err != nil {
+		return err
+	}
+	return bt.updateDestinations(ctx, func(destinations map[string]Profile) error {
+		profile := destinations[destination]
+		profile.Bandwidth = limit
+		return setDestination(destinations, destination, profile)
+	})
+}
+
+// SetProfileTo sets the impairments of the traffic sent by the instance to the given IP or CIDR (e.g. 10.0.0.0/16)
+// The zero Profile removes the impairments of the destination
+// When destinations overlap, the most specific one applies
+// This function can only be called once the instance is started
+func (bt *NetShaper) SetProfileTo(ctx context.Context, destination string, profile Profile) error {
+	cidr, err := destinationCIDR(destination)
+	if err != nil {
+		return err
+	}
+	return bt.updateDestinations(ctx, func(destinations map[string]Profile) error {
+		return setDestination(destinations, cidr, profile)
+	})
+}
+
+// DestinationProfiles returns the impairments of the traffic sent by the instance, keyed by destination CIDR
+func (bt *NetShaper) DestinationProfiles() map[string]Profile {
+	destinations := make(map[string]Profile, len(bt.destinations))
+	for cidr, profile := range bt.destinations {
+		destinations[cidr] = profile
+	}
+	return destinations
+}
+
+// ClearDestinations removes the impairments of all the destinations
+// This function can only be called once the instance is started
+func (bt *NetShaper) ClearDestinations(ctx context.Context) error {
+	return bt.updateDestinations(ctx, func(destinations map[string]Profile) error {
+		for cidr := range destinations {
+			delete(destinations, cidr)
+		}
+		return nil
+	})
+}
+
+// updateDestinations applies the destinations changed by update to the instance
+// If applying them fails, the previous destinations are applied again
+func (bt *NetShaper) updateDestinations(ctx context.Context, update func(destinations map[string]Profile) error) error {
+	if bt.instance == nil || bt.client == nil {
+		return ErrBitTwisterNotInitialized
+	}
+
+	destinations := bt.DestinationProfiles()
+	if err := update(destinations); err != nil {
+		return err
+	}
+
+	if err := bt.applyDestinations(ctx, destinations); err != nil {
+		if rbErr := bt.applyDestinations(ctx, bt.destinations); rbErr != nil {
+			return ErrRollingBackDestinations.WithParams(bt.instance.Name()).Wrap(err).Wrap(rbErr)
+		}
+		return err
+	}
+	bt.destinations = destinations
+
+	bt.instance.Logger.WithFields(logrus.Fields{
+		"instance":     bt.instance.Name(),
+		"destinations": len(destinations),
+	}).Debug("applied net-shaper destinations")
+	return nil
+}
+
+// applyDestinations replaces the tc configuration of the network interface with the given destinations
+// BitTwister applies the latency of the whole interface with tc as well, so both cannot be used at the same time
+func (bt *NetShaper) applyDestinations(ctx context.Context, destinations map[string]Profile) error {
+	status, err := bt.client.LatencyStatus()
+	if err != nil {
+		return ErrGettingServiceStatus.WithParams(bt.instance.Name()).Wrap(err)
+	}
+	if status.Slug == api.SlugServiceReady {
+		return ErrDestinationShapingWithLatency.WithParams(bt.instance.Name())
+	}
+
+	_, err = bt.executeCommand(ctx, destinationScript(bt.networkInterface, destinations))
+	if err != nil {
+		return ErrApplyingDestinations.WithParams(bt.instance.Name()).Wrap(err)
+	}
+	return nil
+}
+
+// destinationScript returns the tc commands shaping the traffic to the given destinations
+// Every destination gets an HTB class with a netem qdisc, selected by a u32 filter on its CIDR,
+// the traffic matching no filter is not classified and leaves unshaped
+func destinationScript(netIf string, destinations map[string]Profile) string {
+	cidrs := make([]string, 0, len(destinations))
+	for cidr := range destinations {
+		cidrs = append(cidrs, cidr)
+	}
+	// the most specific destinations are matched first
+	sort.Slice(cidrs, func(a, b int) bool {
+		if ones(cidrs[a]) != ones(cidrs[b]) {
+			return ones(cidrs[a]) > ones(cidrs[b])
+		}
+		return cidrs[a] < cidrs[b]
+	})
+
+	reset := fmt.Sprintf("tc qdisc del dev %s root 2>/dev/null; ", netIf)
+	if len(cidrs) == 0 {
+		return reset + "true"
+	}
+
+	commands := []string{fmt.Sprintf("tc qdisc add dev %s root handle 1: htb", netIf)}
+	for idx, cidr := range cidrs {
+		classID := idx + 1
+		protocol, match := "ip", "ip"
+		if strings.Contains(cidr, ":") {
+			protocol, match = "ipv6", "ip6"
+		}
+		commands = append(commands,
+			fmt.Sprintf("tc class add dev %s parent 1: classid 1:%x htb rate %s quantum %d", netIf, classID, unshapedRate, classQuantum),
+			fmt.Sprintf("tc qdisc add dev %s parent 1:%x handle %x: netem%s", netIf, classID, classID+1, netemArgs(destinations[cidr])),
+			fmt.Sprintf("tc filter add dev %s parent 1: protocol %s prio %d u32 match %s dst %s flowid 1:%x",
+				netIf, protocol, idx+1, match, cidr, classID),
+		)
+	}
+	return reset + strings.Join(commands, " && ")
+}
+
+// netemArgs returns the arguments of the netem qdisc applying the profile
+func netemArgs(profile Profile) string {
+	var args string
+	if profile.Latency != 0 || profile.Jitter != 0 {
+		args += fmt.Sprintf(" delay %dms %dms", profile.Latency, profile.Jitter)
+	}
+	if profile.PacketLoss != 0 {
+		args += fmt.Sprintf(" loss %d%%", profile.PacketLoss)
+	}
+	if profile.Bandwidth != 0 {
+		args += fmt.Sprintf(" rate %dbit", profile.Bandwidth)
+	}
+	return args
+}
+
+// setDestination sets the profile of the destination, the zero Profile removes it
+func setDestination(destinations map[string]Profile, cidr string, profile Profile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	if profile == (Profile{}) {
+		delete(destinations, cidr)
+		return nil
+	}
+	destinations[cidr] = profile
+	return nil
+}
+
+// peerDestination returns the CIDR matching the ephemeral IP of the peer instance
+func peerDestination(ctx context.Context, peer *instance.Instance) (string, error) {
+	if peer == nil {
+		return "", ErrPeerInstanceIsNil
+	}
+	ip, err := peer.Network().GetEphemeralIP(ctx)
+	if err != nil {
+		return "", ErrResolvingPeerIP.WithParams(peer.Name()).Wrap(err)
+	}
+	return destinationCIDR(ip)
+}
+
+// destinationCIDR returns the canonical CIDR of the given IP or CIDR, an IP is a CIDR of a single address
+func destinationCIDR(destination string) (string, error) {
+	if _, ipNet, err := net.ParseCIDR(destination); err == nil {
+		return ipNet.String(), nil
+	}
+	ip := net.ParseIP(destination)
+	if ip == nil {
+		return "", ErrInvalidDestination.WithParams(destination)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}).String(), nil
+	}
+	return (&net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}).String(), nil
+}
+
+// ones returns the prefix length of the CIDR
+func ones(cidr string) int {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0
+	}
+	n, _ := ipNet.Mask.Size()
+	return n
+}
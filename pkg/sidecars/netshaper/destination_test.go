@@ -0,0 +1,133 @@
+package netshaper
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/celestiaorg/bittwister/sdk"
+)
+
+// fakeExecutor records the scripts run in the BitTwister container instead of running them
+// The scripts containing failOn fail, if it is set
+type fakeExecutor struct {
+	scripts []string
+	failOn  string
+}
+
+func (f *fakeExecutor) execute(_ context.Context, command ...string) (string, error) {
+	script := strings.Join(command, " ")
+	if f.failOn != "" && strings.Contains(script, f.failOn) {
+		return "", errors.New("command terminated with exit code 2")
+	}
+	f.scripts = append(f.scripts, script)
+	return "", nil
+}
+
+// newDestinationNetShaper returns an initialized NetShaper running its commands with a fake executor
+func (s *TestSuite) newDestinationNetShaper(name string) (*NetShaper, *fakeExecutor, func()) {
+	bt := New()
+	s.Require().NoError(bt.Initialize(s.ctx, name, s.sysDeps))
+	server := httptest.NewServer(newFakeBitTwister())
+	bt.client = sdk.NewClient(server.URL)
+	executor := &fakeExecutor{}
+	bt.executeCommand = executor.execute
+	return bt, executor, server.Close
+}
+
+func (s *TestSuite) TestDestinationCIDR() {
+	tests := []struct {
+		name        string
+		destination string
+		expected    string
+		err         error
+	}{
+		{"IPv4 address", "10.0.0.5", "10.0.0.5/32", nil},
+		{"IPv4 CIDR", "10.0.3.7/16", "10.0.0.0/16", nil},
+		{"IPv6 address", "fd00::5", "fd00::5/128", nil},
+		{"IPv6 CIDR", "fd00::/64", "fd00::/64", nil},
+		{"Invalid destination", "validator-0", "", ErrInvalidDestination},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		s.Run(tt.name, func() {
+			cidr, err := destinationCIDR(tt.destination)
+			if tt.err != nil {
+				s.Assert().ErrorIs(err, tt.err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Assert().Equal(tt.expected, cidr)
+		})
+	}
+}
+
+func (s *TestSuite) TestDestinationScript() {
+	script := destinationScript("eth0", map[string]Profile{
+		"10.0.0.0/16": {Bandwidth: 1000},
+		"10.0.0.5/32": {Latency: 120, Jitter: 10, PacketLoss: 5},
+	})
+	s.Assert().Equal("tc qdisc del dev eth0 root 2>/dev/null; "+
+		"tc qdisc add dev eth0 root handle 1: htb && "+
+		// the most specific destination comes first
+		"tc class add dev eth0 parent 1: classid 1:1 htb rate 10gbit quantum 1514 && "+
+		"tc qdisc add dev eth0 parent 1:1 handle 2: netem delay 120ms 10ms loss 5% && "+
+		"tc filter add dev eth0 parent 1: protocol ip prio 1 u32 match ip dst 10.0.0.5/32 flowid 1:1 && "+
+		"tc class add dev eth0 parent 1: classid 1:2 htb rate 10gbit quantum 1514 && "+
+		"tc qdisc add dev eth0 parent 1:2 handle 3: netem rate 1000bit && "+
+		"tc filter add dev eth0 parent 1: protocol ip prio 2 u32 match ip dst 10.0.0.0/16 flowid 1:2",
+		script)
+
+	s.Assert().Contains(destinationScript("eth0", map[string]Profile{"fd00::5/128": {Latency: 50}}),
+		"protocol ipv6 prio 1 u32 match ip6 dst fd00::5/128")
+
+	// without destinations the tc configuration is only reset
+	s.Assert().Equal("tc qdisc del dev eth0 root 2>/dev/null; true", destinationScript("eth0", nil))
+}
+
+func (s *TestSuite) TestSetProfileTo() {
+	s.Assert().ErrorIs(s.bt.SetProfileTo(s.ctx, "10.0.0.5", Profile{Latency: 100}), ErrBitTwisterNotInitialized)
+	s.Assert().ErrorIs(s.bt.SetProfileTo(s.ctx, "not-an-ip", Profile{Latency: 100}), ErrInvalidDestination)
+	s.Assert().ErrorIs(s.bt.SetLatencyTo(s.ctx, nil, 100, 0), ErrPeerInstanceIsNil)
+	s.Assert().ErrorIs(s.bt.SetBandwidthLimitTo(s.ctx, nil, 1000), ErrPeerInstanceIsNil)
+
+	s.Require().NoError(s.bt.Initialize(s.ctx, "test-destination", s.sysDeps))
+	s.bt.client = sdk.NewClient(s.mockServer.URL)
+	s.Assert().ErrorIs(s.bt.SetProfileTo(s.ctx, "10.0.0.5", Profile{PacketLoss: 101}), ErrInvalidProfile)
+	s.Assert().Empty(s.bt.DestinationProfiles())
+
+	// the latency of the whole interface cannot be combined with the per-destination shaping
+	s.bt.destinations = map[string]Profile{"10.0.0.5/32": {Latency: 100}}
+	s.Assert().ErrorIs(s.bt.SetLatencyAndJitter(100, 0), ErrDestinationShapingWithLatency)
+	_, err := s.bt.SetProfile(Profile{Latency: 100})
+	s.Assert().ErrorIs(err, ErrDestinationShapingWithLatency)
+}
+
+func (s *TestSuite) TestUpdateDestinations() {
+	bt, executor, closeServer := s.newDestinationNetShaper("test-update")
+	defer closeServer()
+
+	s.Require().NoError(bt.SetProfileTo(s.ctx, "10.0.0.5", Profile{Latency: 100}))
+	previous := map[string]Profile{"10.0.0.5/32": {Latency: 100}}
+	s.Assert().Equal(previous, bt.DestinationProfiles())
+	s.Require().Len(executor.scripts, 1)
+	s.Assert().Equal(destinationScript(DefaultNetworkInterface, previous), executor.scripts[0])
+
+	// the previous destinations are applied again when the new ones fail
+	executor.failOn = "10.0.0.6/32"
+	s.Assert().ErrorIs(bt.SetProfileTo(s.ctx, "10.0.0.6", Profile{Latency: 50}), ErrApplyingDestinations)
+	s.Assert().Equal(previous, bt.DestinationProfiles())
+	s.Require().Len(executor.scripts, 2)
+	s.Assert().Equal(destinationScript(DefaultNetworkInterface, previous), executor.scripts[1])
+
+	// the rollback fails as well
+	executor.failOn = "tc"
+	s.Assert().ErrorIs(bt.SetProfileTo(s.ctx, "10.0.0.6", Profile{Latency: 50}), ErrRollingBackDestinations)
+	s.Assert().Equal(previous, bt.DestinationProfiles())
+
+	executor.failOn = ""
+	s.Require().NoError(bt.ClearDestinations(s.ctx))
+	s.Assert().Empty(bt.DestinationProfiles())
+}
@@ -27,4 +27,18 @@ var (
 	ErrStoppingPacketLoss                        = errors.New("StoppingPacketLoss", "error stopping packet loss for bit-twister instance '%s'")
 	ErrGettingServiceStatus                      = errors.New("GettingServiceStatus", "error getting service status for net-shaper (bit-twister) instance '%s'")
 	ErrStoppingService                           = errors.New("StoppingService", "error stopping service for net-shaper (bit-twister) instance '%s'")
+	ErrInvalidProfile                            = errors.New("InvalidProfile", "invalid net-shaper profile: %s")
+	ErrApplyingProfile                           = errors.New("ApplyingProfile", "error applying %s of the net-shaper profile")
+	ErrRollingBackProfile                        = errors.New("RollingBackProfile", "error rolling back the net-shaper profile, the impairments may be partially applied")
+	ErrGettingEffectiveProfile                   = errors.New("GettingEffectiveProfile", "error getting the effective net-shaper profile")
+	ErrInvalidDestination                        = errors.New("InvalidDestination", "invalid destination '%s', it must be an IP or a CIDR")
+	ErrPeerInstanceIsNil                         = errors.New("PeerInstanceIsNil", "peer instance is nil")
+	ErrResolvingPeerIP                           = errors.New("ResolvingPeerIP", "error resolving the IP of peer instance '%s'")
+	ErrDestinationShapingWithLatency             = errors.New("DestinationShapingWithLatency", "per-destination shaping and the latency of the whole network interface cannot be combined on instance '%s'")
+	ErrApplyingDestinations                      = errors.New("ApplyingDestinations", "error applying the per-destination shaping of instance '%s'")
+	ErrRollingBackDestinations                   = errors.New("RollingBackDestinations", "error rolling back the per-destination shaping of instance '%s'")
+	ErrRegionNotSet                              = errors.New("RegionNotSet", "region of instance '%s' is not set")
+	ErrNetShaperNotFound                         = errors.New("NetShaperNotFound", "instance '%s' has no net-shaper sidecar")
+	ErrApplyingLatencyMatrix                     = errors.New("ApplyingLatencyMatrix", "error applying the latency matrix to instance '%s'")
+	ErrRollingBackLatencyMatrix                  = errors.New("RollingBackLatencyMatrix", "error rolling back the latency matrix, it may be partially applied")
 )
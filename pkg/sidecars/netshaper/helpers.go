@@ -27,8 +27,7 @@ func (bt *NetShaper) SetNetworkInterface(netIf string) {
 
 // SetBandwidthLimit sets the bandwidth limit of the instance
 // bandwidth limit in bps (e.g. 1000 for 1Kbps)
-// Currently, only one of bandwidth, jitter, latency or packet loss can be set
-// This function can only be called in the state 'Commited'
+// The other impairments are left untouched, use SetProfile to set several of them at once
 func (bt *NetShaper) SetBandwidthLimit(limit int64) error {
 	if bt.client == nil {
 		return ErrBitTwisterNotInitialized
@@ -48,10 +47,15 @@ func (bt *NetShaper) SetBandwidthLimit(limit int64) error {
 // SetLatency sets the latency of the instance
 // latency in ms (e.g. 1000 for 1s)
 // jitter in ms (e.g. 1000 for 1s)
+// The other impairments are left untouched, use SetProfile to set several of them at once
+// It cannot be combined with the per-destination shaping, e.g. SetLatencyTo
 func (bt *NetShaper) SetLatencyAndJitter(latency, jitter int64) error {
 	if bt.client == nil {
 		return ErrBitTwisterNotInitialized
 	}
+	if len(bt.destinations) != 0 {
+		return ErrDestinationShapingWithLatency.WithParams(bt.instance.Name())
+	}
 
 	err := bt.stopIfRunning(bt.client.LatencyStatus, bt.client.LatencyStop)
 	if err != nil {
@@ -67,7 +71,7 @@ func (bt *NetShaper) SetLatencyAndJitter(latency, jitter int64) error {
 
 // SetPacketLoss sets the packet loss of the instance
 // packet loss in percent (e.g. 10 for 10%)
-// Currently, only one of bandwidth, jitter, latency or packet loss can be set
+// The other impairments are left untouched, use SetProfile to set several of them at once
 func (bt *NetShaper) SetPacketLoss(packetLoss int32) error {
 	if bt.client == nil {
 		return ErrBitTwisterNotInitialized
@@ -139,5 +143,6 @@ func (bt *NetShaper) stopIfRunning(
 		!sdk.IsErrorServiceNotStarted(err) {
 		return ErrStoppingService.WithParams(bt.instance.Name()).Wrap(err)
 	}
-	return err
+	// the service stopped in the meantime, there is nothing left to stop
+	return nil
 }
@@ -0,0 +1,137 @@
+package netshaper
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+)
+
+// LatencyMatrix is the one-way latency in ms between regions, indexed by the source region and then the destination region
+// A missing pair falls back to the reverse direction, so half of the table is enough for symmetric latencies
+// The latency within a region is matrix[region][region], a pair missing in both directions has no latency
+type LatencyMatrix map[string]map[string]int64
+
+// Latency returns the latency in ms of the traffic sent from the region from to the region to
+func (m LatencyMatrix) Latency(from, to string) int64 {
+	if latency, ok := m[from][to]; ok {
+		return latency
+	}
+	return m[to][from]
+}
+
+// FromInstance returns the NetShaper sidecar of the instance, or nil if it has none
+func FromInstance(i *instance.Instance) *NetShaper {
+	for _, sc := range i.Sidecars().List() {
+		if bt, ok := sc.(*NetShaper); ok {
+			return bt
+		}
+	}
+	return nil
+}
+
+// matrixNode is an instance of the group the latency matrix is applied to
+type matrixNode struct {
+	name        string
+	region      string
+	destination string
+	shaper      *NetShaper
+}
+
+// ApplyLatencyMatrix sets the latency between every pair of instances of the group according to their regions
+// regions maps every instance of the group to its region and every instance needs a NetShaper sidecar
+// The latency of a pair is applied to the traffic leaving each instance, so the round trip time between
+// two instances is the sum of both directions
+// The other impairments of the destinations are left untouched
+// If applying the matrix to one of the instances fails, the instances already updated get their previous destinations back
+// This function can only be called when all the instances of the group are started
+func ApplyLatencyMatrix(ctx context.Context, group *instance.Group, regions map[*instance.Instance]string, matrix LatencyMatrix) error {
+	instances := group.Instances()
+	nodes := make([]matrixNode, len(instances))
+	for idx, i := range instances {
+		region, ok := regions[i]
+		if !ok {
+			return ErrRegionNotSet.WithParams(i.Name())
+		}
+		nodes[idx] = matrixNode{name: i.Name(), region: region}
+	}
+	for idx, i := range instances {
+		nodes[idx].shaper = FromInstance(i)
+		if nodes[idx].shaper == nil {
+			return ErrNetShaperNotFound.WithParams(i.Name())
+		}
+	}
+
+	for idx, i := range instances {
+		destination, err := peerDestination(ctx, i)
+		if err != nil {
+			return err
+		}
+		nodes[idx].destination = destination
+	}
+
+	if err := applyLatencyMatrix(ctx, nodes, matrix); err != nil {
+		return err
+	}
+
+	if len(instances) != 0 {
+		instances[0].Logger.WithFields(logrus.Fields{
+			"instances": len(instances),
+			"regions":   len(matrix),
+		}).Debug("applied latency matrix")
+	}
+	return nil
+}
+
+// applyLatencyMatrix sets the latency from every node to all the other ones
+// If it fails for one of the nodes, the previous destinations of the nodes already updated are applied again
+func applyLatencyMatrix(ctx context.Context, nodes []matrixNode, matrix LatencyMatrix) error {
+	previous := make([]map[string]Profile, 0, len(nodes))
+	for from, node := range nodes {
+		destinations := node.shaper.DestinationProfiles()
+		err := node.shaper.updateDestinations(ctx, func(profiles map[string]Profile) error {
+			for to, peer := range nodes {
+				if to == from {
+					continue
+				}
+				profile := profiles[peer.destination]
+				profile.Latency = matrix.Latency(node.region, peer.region)
+				if err := setDestination(profiles, peer.destination, profile); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			applyErr := ErrApplyingLatencyMatrix.WithParams(node.name).Wrap(err)
+			if rbErr := rollbackLatencyMatrix(ctx, nodes[:from], previous); rbErr != nil {
+				return ErrRollingBackLatencyMatrix.Wrap(applyErr).Wrap(rbErr)
+			}
+			return applyErr
+		}
+		previous = append(previous, destinations)
+	}
+	return nil
+}
+
+// rollbackLatencyMatrix applies the previous destinations of the given nodes again
+// It goes through all of them even if one fails, so that as many nodes as possible are restored
+func rollbackLatencyMatrix(ctx context.Context, nodes []matrixNode, previous []map[string]Profile) error {
+	var rbErr error
+	for idx, node := range nodes {
+		err := node.shaper.updateDestinations(ctx, func(destinations map[string]Profile) error {
+			for cidr := range destinations {
+				delete(destinations, cidr)
+			}
+			for cidr, profile := range previous[idx] {
+				destinations[cidr] = profile
+			}
+			return nil
+		})
+		if err != nil {
+			rbErr = ErrRollingBackDestinations.WithParams(node.name).Wrap(err)
+		}
+	}
+	return rbErr
+}
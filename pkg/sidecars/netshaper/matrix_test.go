@@ -0,0 +1,119 @@
+package netshaper
+
+import (
+	"fmt"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+)
+
+func (s *TestSuite) TestLatencyMatrix() {
+	matrix := LatencyMatrix{
+		"eu": {"eu": 5, "us": 40},
+		"us": {"us": 5, "eu": 45},
+		"ap": {"eu": 120},
+	}
+	s.Assert().Equal(int64(5), matrix.Latency("eu", "eu"))
+	s.Assert().Equal(int64(40), matrix.Latency("eu", "us"))
+	s.Assert().Equal(int64(45), matrix.Latency("us", "eu"))
+	// the missing direction falls back to the reverse one
+	s.Assert().Equal(int64(120), matrix.Latency("eu", "ap"))
+	s.Assert().Equal(int64(0), matrix.Latency("us", "ap"))
+}
+
+func (s *TestSuite) TestApplyLatencyMatrix() {
+	validator, err := instance.New("validator", s.sysDeps)
+	s.Require().NoError(err)
+	bridge, err := instance.New("bridge", s.sysDeps)
+	s.Require().NoError(err)
+	group := instance.NewGroup(validator, bridge)
+	matrix := LatencyMatrix{"eu": {"us": 40}}
+
+	err = ApplyLatencyMatrix(s.ctx, group, map[*instance.Instance]string{validator: "eu"}, matrix)
+	s.Assert().ErrorIs(err, ErrRegionNotSet)
+
+	regions := map[*instance.Instance]string{validator: "eu", bridge: "us"}
+	err = ApplyLatencyMatrix(s.ctx, group, regions, matrix)
+	s.Assert().ErrorIs(err, ErrNetShaperNotFound)
+
+	s.Assert().Nil(FromInstance(validator))
+	s.Require().NoError(validator.Build().SetImage(s.ctx, "alpine:latest"))
+	bt := New()
+	s.Require().NoError(validator.Sidecars().Add(s.ctx, bt))
+	s.Assert().Equal(bt, FromInstance(validator))
+}
+
+func (s *TestSuite) newMatrixNodes() ([]matrixNode, []*fakeExecutor, func()) {
+	var (
+		nodes     []matrixNode
+		executors []*fakeExecutor
+		closers   []func()
+	)
+	for idx, region := range []string{"eu", "us", "ap"} {
+		name := fmt.Sprintf("node-%d", idx)
+		bt, executor, closeServer := s.newDestinationNetShaper(name)
+		nodes = append(nodes, matrixNode{name: name, region: region, destination: fmt.Sprintf("10.0.0.%d/32", idx+1), shaper: bt})
+		executors = append(executors, executor)
+		closers = append(closers, closeServer)
+	}
+	return nodes, executors, func() {
+		for _, closeServer := range closers {
+			closeServer()
+		}
+	}
+}
+
+func (s *TestSuite) TestApplyLatencyMatrixNodes() {
+	nodes, executors, closeServers := s.newMatrixNodes()
+	defer closeServers()
+	matrix := LatencyMatrix{"eu": {"us": 40, "ap": 120}, "us": {"ap": 90}}
+
+	// the other impairments of the destinations are kept
+	s.Require().NoError(nodes[0].shaper.SetProfileTo(s.ctx, "10.0.0.2", Profile{Bandwidth: 1000}))
+	s.Require().NoError(applyLatencyMatrix(s.ctx, nodes, matrix))
+	s.Assert().Equal(map[string]Profile{
+		"10.0.0.2/32": {Bandwidth: 1000, Latency: 40},
+		"10.0.0.3/32": {Latency: 120},
+	}, nodes[0].shaper.DestinationProfiles())
+	s.Assert().Equal(map[string]Profile{
+		"10.0.0.1/32": {Latency: 40},
+		"10.0.0.3/32": {Latency: 90},
+	}, nodes[1].shaper.DestinationProfiles())
+	s.Assert().Equal(map[string]Profile{
+		"10.0.0.1/32": {Latency: 120},
+		"10.0.0.2/32": {Latency: 90},
+	}, nodes[2].shaper.DestinationProfiles())
+	for idx, node := range nodes {
+		s.Assert().Equal(destinationScript(DefaultNetworkInterface, node.shaper.DestinationProfiles()),
+			executors[idx].scripts[len(executors[idx].scripts)-1])
+	}
+}
+
+func (s *TestSuite) TestApplyLatencyMatrixRollback() {
+	nodes, executors, closeServers := s.newMatrixNodes()
+	defer closeServers()
+	matrix := LatencyMatrix{"eu": {"us": 40, "ap": 120}, "us": {"ap": 90}}
+
+	previous := map[string]Profile{"10.0.0.2/32": {Bandwidth: 1000}}
+	s.Require().NoError(nodes[0].shaper.SetProfileTo(s.ctx, "10.0.0.2", Profile{Bandwidth: 1000}))
+
+	// the last node fails, so the nodes already updated get their previous destinations back
+	executors[2].failOn = "tc"
+	err := applyLatencyMatrix(s.ctx, nodes, matrix)
+	s.Require().ErrorIs(err, ErrApplyingLatencyMatrix)
+	s.Assert().Equal(previous, nodes[0].shaper.DestinationProfiles())
+	s.Assert().Empty(nodes[1].shaper.DestinationProfiles())
+	s.Assert().Equal(destinationScript(DefaultNetworkInterface, previous), executors[0].scripts[len(executors[0].scripts)-1])
+	s.Assert().Equal(destinationScript(DefaultNetworkInterface, nil), executors[1].scripts[len(executors[1].scripts)-1])
+
+	// the second node cannot be restored either
+	executors[1].failOn = "; true"
+	err = applyLatencyMatrix(s.ctx, nodes, matrix)
+	s.Require().ErrorIs(err, ErrRollingBackLatencyMatrix)
+	s.Assert().Contains(err.Error(), "node-1")
+	s.Assert().Equal(previous, nodes[0].shaper.DestinationProfiles())
+	// the second node keeps the latencies of the matrix, as its tc configuration could not be reset
+	s.Assert().Equal(map[string]Profile{
+		"10.0.0.1/32": {Latency: 40},
+		"10.0.0.3/32": {Latency: 90},
+	}, nodes[1].shaper.DestinationProfiles())
+}
@@ -28,6 +28,9 @@ type NetShaper struct {
 	image            string
 	networkInterface string
 	client           *sdk.Client
+	destinations     map[string]Profile
+	// executeCommand runs a command in the BitTwister container, it is replaced in the tests
+	executeCommand func(ctx context.Context, command ...string) (string, error)
 }
 
 var _ instance.SidecarManager = (*NetShaper)(nil)
@@ -49,6 +52,7 @@ func (bt *NetShaper) Initialize(ctx context.Context, namePrefix string, sysDeps
 		return ErrCreatingBitTwisterInstance.Wrap(err)
 	}
 	bt.instance.Sidecars().SetIsSidecar(true)
+	bt.executeCommand = bt.instance.Execution().ExecuteCommand
 
 	if err := bt.instance.Build().SetImage(ctx, bt.image); err != nil {
 		return ErrSettingBitTwisterImage.Wrap(err)
@@ -109,5 +113,6 @@ func (bt *NetShaper) Clone(namePrefix string) (instance.SidecarManager, error) {
 		port:             bt.port,
 		image:            bt.image,
 		networkInterface: bt.networkInterface,
+		executeCommand:   clone.Execution().ExecuteCommand,
 	}, nil
 }
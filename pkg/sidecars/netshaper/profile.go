@@ -0,0 +1,228 @@
+package netshaper
+
+import (
+	"fmt"
+
+	"github.com/celestiaorg/bittwister/api/v1"
+	"github.com/celestiaorg/bittwister/sdk"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	serviceBandwidth  = "bandwidth"
+	serviceLatency    = "latency"
+	servicePacketLoss = "packetloss"
+
+	paramLimit          = "limit"
+	paramLatency        = "latency_ms"
+	paramJitter         = "jitter_ms"
+	paramPacketLossRate = "packet_loss_rate"
+)
+
+// Profile is a set of network impairments applied together to the instance
+// A zero value disables the impairment, so the zero Profile removes all of them
+type Profile struct {
+	// Bandwidth is the bandwidth limit in bps (e.g. 1000 for 1Kbps)
+	Bandwidth int64
+	// Latency is the added latency in ms (e.g. 1000 for 1s)
+	Latency int64
+	// Jitter is the jitter of the latency in ms (e.g. 1000 for 1s)
+	Jitter int64
+	// PacketLoss is the packet loss in percent (e.g. 10 for 10%)
+	PacketLoss int32
+}
+
+// Validate returns an error if a value of the profile is out of range
+func (p Profile) Validate() error {
+	switch {
+	case p.Bandwidth < 0:
+		return ErrInvalidProfile.WithParams(fmt.Sprintf("bandwidth must not be negative, got %d", p.Bandwidth))
+	case p.Latency < 0:
+		return ErrInvalidProfile.WithParams(fmt.Sprintf("latency must not be negative, got %d", p.Latency))
+	case p.Jitter < 0:
+		return ErrInvalidProfile.WithParams(fmt.Sprintf("jitter must not be negative, got %d", p.Jitter))
+	case p.PacketLoss < 0 || p.PacketLoss > 100:
+		return ErrInvalidProfile.WithParams(fmt.Sprintf("packet loss must be between 0 and 100, got %d", p.PacketLoss))
+	}
+	return nil
+}
+
+// impairment is one of the services of BitTwister, which is configured by a part of the profile
+type impairment struct {
+	name    string
+	status  func() (*api.MetaMessage, error)
+	stop    func() error
+	start   func(p Profile) error
+	enabled func(p Profile) bool
+	equal   func(a, b Profile) bool
+}
+
+func (bt *NetShaper) impairments() []impairment {
+	return []impairment{
+		{
+			name:   serviceBandwidth,
+			status: bt.client.BandwidthStatus,
+			stop:   bt.client.BandwidthStop,
+			start: func(p Profile) error {
+				return bt.client.BandwidthStart(sdk.BandwidthStartRequest{
+					NetworkInterfaceName: bt.networkInterface,
+					Limit:                p.Bandwidth,
+				})
+			},
+			enabled: func(p Profile) bool { return p.Bandwidth != 0 },
+			equal:   func(a, b Profile) bool { return a.Bandwidth == b.Bandwidth },
+		},
+		{
+			name:   serviceLatency,
+			status: bt.client.LatencyStatus,
+			stop:   bt.client.LatencyStop,
+			start: func(p Profile) error {
+				return bt.client.LatencyStart(sdk.LatencyStartRequest{
+					NetworkInterfaceName: bt.networkInterface,
+					Latency:              p.Latency,
+					Jitter:               p.Jitter,
+				})
+			},
+			enabled: func(p Profile) bool { return p.Latency != 0 || p.Jitter != 0 },
+			equal:   func(a, b Profile) bool { return a.Latency == b.Latency && a.Jitter == b.Jitter },
+		},
+		{
+			name:   servicePacketLoss,
+			status: bt.client.PacketlossStatus,
+			stop:   bt.client.PacketlossStop,
+			start: func(p Profile) error {
+				return bt.client.PacketlossStart(sdk.PacketLossStartRequest{
+					NetworkInterfaceName: bt.networkInterface,
+					PacketLossRate:       p.PacketLoss,
+				})
+			},
+			enabled: func(p Profile) bool { return p.PacketLoss != 0 },
+			equal:   func(a, b Profile) bool { return a.PacketLoss == b.PacketLoss },
+		},
+	}
+}
+
+// SetProfile applies all the impairments of the profile to the instance at once and returns the effective profile
+// The impairments that are zero in the profile are removed, the ones that did not change are left untouched
+// If applying one of them fails, the impairments already changed are rolled back to the profile
+// that was effective before the call, which is then returned along with the error
+// If the rollback fails as well, the partially applied profile that is effective is returned with the error
+// The latency cannot be combined with the per-destination shaping, e.g. SetLatencyTo
+// This function can only be called once the instance is started
+func (bt *NetShaper) SetProfile(profile Profile) (Profile, error) {
+	if bt.client == nil {
+		return Profile{}, ErrBitTwisterNotInitialized
+	}
+	if err := profile.Validate(); err != nil {
+		return Profile{}, err
+	}
+	if (profile.Latency != 0 || profile.Jitter != 0) && len(bt.destinations) != 0 {
+		return Profile{}, ErrDestinationShapingWithLatency.WithParams(bt.instance.Name())
+	}
+
+	previous, err := bt.EffectiveProfile()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	var changed []impairment
+	for _, imp := range bt.impairments() {
+		if imp.equal(previous, profile) {
+			continue
+		}
+		changed = append(changed, imp)
+		if err := bt.applyImpairment(imp, profile); err != nil {
+			applyErr := ErrApplyingProfile.WithParams(imp.name).Wrap(err)
+			if rbErr := bt.rollbackImpairments(changed, previous); rbErr != nil {
+				// the impairments are partially applied, so the profile that is effective now is returned
+				effective, err := bt.EffectiveProfile()
+				return effective, ErrRollingBackProfile.Wrap(applyErr).Wrap(rbErr).Wrap(err)
+			}
+			return previous, applyErr
+		}
+	}
+
+	bt.logProfile(profile, "applied net-shaper profile")
+	return bt.EffectiveProfile()
+}
+
+// EffectiveProfile returns the impairments that are currently active in the BitTwister sidecar,
+// whether they were set by SetProfile or by SetBandwidthLimit, SetLatencyAndJitter and SetPacketLoss
+func (bt *NetShaper) EffectiveProfile() (Profile, error) {
+	statuses, err := bt.AllServicesStatus()
+	if err != nil {
+		return Profile{}, ErrGettingEffectiveProfile.Wrap(err)
+	}
+
+	var profile Profile
+	for _, status := range statuses {
+		if !status.Ready {
+			continue
+		}
+		switch status.Name {
+		case serviceBandwidth:
+			profile.Bandwidth = paramInt64(status.Params, paramLimit)
+		case serviceLatency:
+			profile.Latency = paramInt64(status.Params, paramLatency)
+			profile.Jitter = paramInt64(status.Params, paramJitter)
+		case servicePacketLoss:
+			profile.PacketLoss = int32(paramInt64(status.Params, paramPacketLossRate))
+		}
+	}
+	return profile, nil
+}
+
+// applyImpairment stops the service of the impairment and starts it again with the values of the profile
+func (bt *NetShaper) applyImpairment(imp impairment, profile Profile) error {
+	if err := bt.stopIfRunning(imp.status, imp.stop); err != nil {
+		return err
+	}
+	if !imp.enabled(profile) {
+		return nil
+	}
+	return imp.start(profile)
+}
+
+// rollbackImpairments restores the given impairments to the values of the previous profile
+// It goes through all of them even if one fails, so that as much as possible of the previous profile is restored
+func (bt *NetShaper) rollbackImpairments(impairments []impairment, previous Profile) error {
+	var rbErr error
+	for _, imp := range impairments {
+		if err := bt.applyImpairment(imp, previous); err != nil {
+			rbErr = ErrApplyingProfile.WithParams(imp.name).Wrap(err)
+		}
+	}
+	if rbErr != nil {
+		return rbErr
+	}
+	bt.logProfile(previous, "rolled back net-shaper profile")
+	return nil
+}
+
+func (bt *NetShaper) logProfile(profile Profile, msg string) {
+	if bt.instance == nil {
+		return
+	}
+	bt.instance.Logger.WithFields(logrus.Fields{
+		"instance":    bt.instance.Name(),
+		"bandwidth":   profile.Bandwidth,
+		"latency":     profile.Latency,
+		"jitter":      profile.Jitter,
+		"packet_loss": profile.PacketLoss,
+	}).Debug(msg)
+}
+
+// paramInt64 returns the numeric value of the given parameter of a service status, which is decoded from JSON
+func paramInt64(params map[string]interface{}, key string) int64 {
+	switch v := params[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	}
+	return 0
+}
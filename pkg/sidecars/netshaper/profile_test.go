@@ -0,0 +1,166 @@
+package netshaper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/celestiaorg/bittwister/api/v1"
+	"github.com/celestiaorg/bittwister/sdk"
+)
+
+// fakeBitTwister keeps the state of the services like the BitTwister API does
+// The start of the services listed in failStart fails
+type fakeBitTwister struct {
+	mu        sync.Mutex
+	params    map[string]map[string]interface{}
+	failStart map[string]bool
+}
+
+func newFakeBitTwister() *fakeBitTwister {
+	return &fakeBitTwister{
+		params:    make(map[string]map[string]interface{}),
+		failStart: make(map[string]bool),
+	}
+}
+
+func (f *fakeBitTwister) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == api.ServicesPath.Status() {
+		out := []sdk.ServiceStatus{}
+		for _, name := range []string{servicePacketLoss, serviceBandwidth, serviceLatency} {
+			params, ready := f.params[name]
+			out = append(out, sdk.ServiceStatus{Name: name, Ready: ready, Params: params})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	name, action, _ := strings.Cut(path, "/")
+	_, running := f.params[name]
+	switch action {
+	case "status":
+		slug := api.SlugServiceNotReady
+		if running {
+			slug = api.SlugServiceReady
+		}
+		_ = json.NewEncoder(w).Encode(api.MetaMessage{Slug: slug})
+	case "stop":
+		if !running {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(api.MetaMessage{Slug: api.SlugServiceNotStarted})
+			return
+		}
+		delete(f.params, name)
+	case "start":
+		if running {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(api.MetaMessage{Slug: api.SlugServiceAlreadyStarted})
+			return
+		}
+		if f.failStart[name] {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(api.MetaMessage{Slug: api.SlugServiceStartFailed})
+			return
+		}
+		params := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		delete(params, "network_interface")
+		f.params[name] = params
+	}
+}
+
+func (s *TestSuite) newProfileNetShaper() (*fakeBitTwister, func()) {
+	s.Require().NoError(s.bt.Initialize(s.ctx, "test-profile", s.sysDeps))
+	fake := newFakeBitTwister()
+	server := httptest.NewServer(fake)
+	s.bt.client = sdk.NewClient(server.URL)
+	return fake, server.Close
+}
+
+func (s *TestSuite) TestSetProfile() {
+	_, closeServer := s.newProfileNetShaper()
+	defer closeServer()
+
+	profile := Profile{Bandwidth: 1000, Latency: 100, Jitter: 10, PacketLoss: 5}
+	effective, err := s.bt.SetProfile(profile)
+	s.Require().NoError(err)
+	s.Assert().Equal(profile, effective)
+
+	// the zero values remove the impairments
+	effective, err = s.bt.SetProfile(Profile{Latency: 200})
+	s.Require().NoError(err)
+	s.Assert().Equal(Profile{Latency: 200}, effective)
+
+	// the impairments set one by one are part of the effective profile
+	s.Require().NoError(s.bt.SetPacketLoss(20))
+	effective, err = s.bt.EffectiveProfile()
+	s.Require().NoError(err)
+	s.Assert().Equal(Profile{Latency: 200, PacketLoss: 20}, effective)
+}
+
+func (s *TestSuite) TestSetProfileRollback() {
+	fake, closeServer := s.newProfileNetShaper()
+	defer closeServer()
+
+	previous := Profile{Bandwidth: 1000, PacketLoss: 5}
+	_, err := s.bt.SetProfile(previous)
+	s.Require().NoError(err)
+
+	// bandwidth is rolled back once latency fails
+	fake.failStart[serviceLatency] = true
+	effective, err := s.bt.SetProfile(Profile{Bandwidth: 2000, Latency: 100, PacketLoss: 10})
+	s.Require().ErrorIs(err, ErrApplyingProfile)
+	s.Assert().Equal(previous, effective)
+	effective, err = s.bt.EffectiveProfile()
+	s.Require().NoError(err)
+	s.Assert().Equal(previous, effective)
+
+	// the previous packet loss cannot be restored either, so the rollback fails
+	fake.failStart[serviceLatency] = false
+	fake.failStart[servicePacketLoss] = true
+	effective, err = s.bt.SetProfile(Profile{Bandwidth: 2000, Latency: 100, PacketLoss: 10})
+	s.Require().ErrorIs(err, ErrRollingBackProfile)
+	s.Assert().Contains(err.Error(), "error applying packetloss")
+	s.Assert().Equal(Profile{Bandwidth: 1000}, effective)
+	effective, err = s.bt.EffectiveProfile()
+	s.Require().NoError(err)
+	s.Assert().Equal(Profile{Bandwidth: 1000}, effective)
+
+	// the next profile starts from the effective state
+	fake.failStart[servicePacketLoss] = false
+	effective, err = s.bt.SetProfile(previous)
+	s.Require().NoError(err)
+	s.Assert().Equal(previous, effective)
+}
+
+func (s *TestSuite) TestSetProfileInvalid() {
+	tests := []struct {
+		name    string
+		profile Profile
+		err     error
+	}{
+		{"Negative bandwidth", Profile{Bandwidth: -1}, ErrInvalidProfile},
+		{"Negative latency", Profile{Latency: -1}, ErrInvalidProfile},
+		{"Negative jitter", Profile{Jitter: -1}, ErrInvalidProfile},
+		{"Packet loss over 100", Profile{PacketLoss: 101}, ErrInvalidProfile},
+	}
+
+	s.bt.client = sdk.NewClient(s.mockServer.URL)
+	for _, tt := range tests {
+		tt := tt
+		s.Run(tt.name, func() {
+			_, err := s.bt.SetProfile(tt.profile)
+			s.Assert().ErrorIs(err, tt.err)
+		})
+	}
+
+	s.bt.client = nil
+	_, err := s.bt.SetProfile(Profile{})
+	s.Assert().ErrorIs(err, ErrBitTwisterNotInitialized)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/celestiaorg/knuu/pkg/builder"
+	"github.com/celestiaorg/knuu/pkg/imagecache"
 	"github.com/celestiaorg/knuu/pkg/k8s"
 	"github.com/celestiaorg/knuu/pkg/minio"
 	"github.com/celestiaorg/knuu/pkg/traefik"
@@ -17,9 +18,16 @@ type SystemDependencies struct {
 	MinioClient  *minio.Minio
 	Logger       *logrus.Logger
 	Proxy        *traefik.Traefik
-	Scope        string
-	StartTime    string
-	instancesMap sync.Map
+	Registry     *builder.Registry
+	ImageCache   *imagecache.Cache
+	// RegistryCredentials are the credentials of the private registries the images are pulled from
+	RegistryCredentials []*builder.Registry
+	// ImagePullSecret is the name of the dockerconfigjson secret holding the credentials of the registries,
+	// it is empty if no registry needs credentials
+	ImagePullSecret string
+	Scope           string
+	StartTime       string
+	instancesMap    sync.Map
 }
 
 func (s *SystemDependencies) AddInstanceName(name string) {
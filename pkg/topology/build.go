@@ -0,0 +1,383 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+	"github.com/celestiaorg/knuu/pkg/sidecars/netshaper"
+	"github.com/celestiaorg/knuu/pkg/sidecars/observability"
+	"github.com/celestiaorg/knuu/pkg/sidecars/tshark"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+// Deployment holds the instances built from a topology
+type Deployment struct {
+	topology  *Topology
+	instances map[string][]*instance.Instance
+	logger    *logrus.Logger
+}
+
+// Build creates and commits the instances described by the topology, without starting them
+// Instances with clones are built once and then cloned, so the image is only built once per spec
+func (t *Topology) Build(ctx context.Context, sysDeps *system.SystemDependencies) (*Deployment, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	d := &Deployment{
+		topology:  t,
+		instances: make(map[string][]*instance.Instance, len(t.Instances)),
+		logger:    sysDeps.Logger,
+	}
+	for _, spec := range t.Instances {
+		instances, err := t.buildInstances(ctx, sysDeps, spec)
+		if err != nil {
+			return nil, err
+		}
+		d.instances[spec.Name] = instances
+	}
+	return d, nil
+}
+
+// Instances returns the instances built from the spec with the given name
+func (d *Deployment) Instances(name string) []*instance.Instance {
+	return d.instances[name]
+}
+
+// All returns all the instances of the deployment in the order of the topology file
+func (d *Deployment) All() []*instance.Instance {
+	var all []*instance.Instance
+	for _, spec := range d.topology.Instances {
+		all = append(all, d.instances[spec.Name]...)
+	}
+	return all
+}
+
+// Start starts the instances in dependency order and waits until all of them are running
+// An instance is only started once all the instances it depends on are running,
+// the instances that do not depend on each other are started concurrently
+func (d *Deployment) Start(ctx context.Context) error {
+	g := instance.NewGroup()
+	for _, spec := range d.topology.Instances {
+		for _, inst := range d.instances[spec.Name] {
+			m := g.Add(inst)
+			for _, dep := range spec.DependsOn {
+				for _, depInst := range d.instances[dep] {
+					m.DependsOn(depInst, instance.ReadyWhenRunning)
+				}
+			}
+		}
+	}
+	if err := g.Start(ctx); err != nil {
+		return ErrStartingTopology.Wrap(err)
+	}
+
+	for _, inst := range d.All() {
+		if err := inst.Execution().WaitInstanceIsRunning(ctx); err != nil {
+			return ErrWaitingForInstance.WithParams(inst.Name()).Wrap(err)
+		}
+	}
+	d.logger.WithField("instances", len(d.All())).Debug("started topology")
+	return nil
+}
+
+// Destroy destroys all the instances of the deployment in the reverse start order
+func (d *Deployment) Destroy(ctx context.Context) error {
+	all := d.All()
+	for idx := len(all) - 1; idx >= 0; idx-- {
+		if err := all[idx].Execution().Destroy(ctx); err != nil {
+			return ErrDestroyingInstance.WithParams(all[idx].Name()).Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (t *Topology) buildInstances(ctx context.Context, sysDeps *system.SystemDependencies, spec InstanceSpec) ([]*instance.Instance, error) {
+	names := spec.instanceNames()
+
+	inst, err := instance.New(names[0], sysDeps)
+	if err != nil {
+		return nil, ErrCreatingInstance.WithParams(names[0]).Wrap(err)
+	}
+	if err := t.configureInstance(ctx, inst, spec); err != nil {
+		return nil, err
+	}
+
+	instances := []*instance.Instance{inst}
+	for _, name := range names[1:] {
+		clone, err := inst.CloneWithName(name)
+		if err != nil {
+			return nil, ErrCloningInstance.WithParams(inst.Name()).Wrap(err)
+		}
+		instances = append(instances, clone)
+	}
+
+	sysDeps.Logger.WithFields(logrus.Fields{
+		"spec":      spec.Name,
+		"instances": len(instances),
+	}).Debug("built instances from topology")
+	return instances, nil
+}
+
+func (t *Topology) configureInstance(ctx context.Context, inst *instance.Instance, spec InstanceSpec) error {
+	name := inst.Name()
+
+	if err := inst.Build().SetImage(ctx, spec.Image); err != nil {
+		return ErrSettingImage.WithParams(name).Wrap(err)
+	}
+	if len(spec.Command) > 0 {
+		if err := inst.Build().SetStartCommand(spec.Command...); err != nil {
+			return ErrSettingStartCommand.WithParams(name).Wrap(err)
+		}
+	}
+	if len(spec.Args) > 0 {
+		if err := inst.Build().SetArgs(spec.Args...); err != nil {
+			return ErrSettingArgs.WithParams(name).Wrap(err)
+		}
+	}
+	if err := inst.Build().Commit(ctx); err != nil {
+		return ErrCommittingInstance.WithParams(name).Wrap(err)
+	}
+
+	for key, value := range spec.Env {
+		if err := inst.Build().SetEnvironmentVariable(key, value); err != nil {
+			return ErrSettingEnvironmentVariable.WithParams(key, name).Wrap(err)
+		}
+	}
+	for _, f := range spec.Files {
+		if err := inst.Storage().AddFile(t.sourcePath(f.Source), f.Dest, f.Chown); err != nil {
+			return ErrAddingFile.WithParams(f.Source, name).Wrap(err)
+		}
+	}
+	for _, f := range spec.Folders {
+		if err := inst.Storage().AddFolder(t.sourcePath(f.Source), f.Dest, f.Chown); err != nil {
+			return ErrAddingFolder.WithParams(f.Source, name).Wrap(err)
+		}
+	}
+	for _, v := range spec.Volumes {
+		size, err := parseQuantity(v.Size, "volume size", name)
+		if err != nil {
+			return err
+		}
+		if err := inst.Storage().AddVolumeWithOwner(v.Path, size, v.Owner, v.options()...); err != nil {
+			return ErrAddingVolume.WithParams(v.Path, name).Wrap(err)
+		}
+	}
+	for _, port := range spec.Ports.TCP {
+		if err := inst.Network().AddPortTCP(port); err != nil {
+			return ErrAddingPort.WithParams(port, name).Wrap(err)
+		}
+	}
+	for _, port := range spec.Ports.UDP {
+		if err := inst.Network().AddPortUDP(port); err != nil {
+			return ErrAddingPort.WithParams(port, name).Wrap(err)
+		}
+	}
+
+	if err := configureResources(inst, spec); err != nil {
+		return ErrSettingResources.WithParams(name).Wrap(err)
+	}
+
+	workloadType, err := spec.workloadType()
+	if err != nil {
+		return err
+	}
+	if err := inst.Execution().SetWorkloadType(workloadType); err != nil {
+		return ErrSettingWorkload.WithParams(name).Wrap(err)
+	}
+
+	if spec.Security.Privileged {
+		if err := inst.Security().SetPrivileged(true); err != nil {
+			return ErrSettingSecurity.WithParams(name).Wrap(err)
+		}
+	}
+	if len(spec.Security.Capabilities) > 0 {
+		if err := inst.Security().AddKubernetesCapabilities(spec.Security.Capabilities); err != nil {
+			return ErrSettingSecurity.WithParams(name).Wrap(err)
+		}
+	}
+
+	return addSidecars(ctx, inst, spec)
+}
+
+func configureResources(inst *instance.Instance, spec InstanceSpec) error {
+	res := spec.Resources
+	if res.MemoryRequest != "" || res.MemoryLimit != "" {
+		memoryRequest, memoryLimit := res.MemoryRequest, res.MemoryLimit
+		if memoryLimit == "" {
+			memoryLimit = memoryRequest
+		}
+		if memoryRequest == "" {
+			memoryRequest = memoryLimit
+		}
+		request, err := parseQuantity(memoryRequest, "memory request", spec.Name)
+		if err != nil {
+			return err
+		}
+		limit, err := parseQuantity(memoryLimit, "memory limit", spec.Name)
+		if err != nil {
+			return err
+		}
+		if err := inst.Resources().SetMemory(request, limit); err != nil {
+			return err
+		}
+	}
+	if res.CPURequest != "" {
+		cpu, err := parseQuantity(res.CPURequest, "cpu request", spec.Name)
+		if err != nil {
+			return err
+		}
+		if err := inst.Resources().SetCPU(cpu); err != nil {
+			return err
+		}
+	}
+	if spec.Replicas > 0 {
+		return inst.Resources().SetReplicas(spec.Replicas)
+	}
+	return nil
+}
+
+func addSidecars(ctx context.Context, inst *instance.Instance, spec InstanceSpec) error {
+	if ns := spec.Sidecars.NetShaper; ns != nil {
+		sc := netshaper.New()
+		if ns.Image != "" {
+			sc.SetImage(ns.Image)
+		}
+		if ns.Port != 0 {
+			sc.SetPort(ns.Port)
+		}
+		if ns.NetworkInterface != "" {
+			sc.SetNetworkInterface(ns.NetworkInterface)
+		}
+		if err := inst.Sidecars().Add(ctx, sc); err != nil {
+			return ErrAddingSidecar.WithParams("netshaper", inst.Name()).Wrap(err)
+		}
+	}
+
+	if obsy := spec.Sidecars.Obsy; obsy != nil {
+		sc, err := newObsy(obsy)
+		if err != nil {
+			return ErrConfiguringSidecar.WithParams("obsy", inst.Name()).Wrap(err)
+		}
+		if err := inst.Sidecars().Add(ctx, sc); err != nil {
+			return ErrAddingSidecar.WithParams("obsy", inst.Name()).Wrap(err)
+		}
+	}
+
+	if ts := spec.Sidecars.Tshark; ts != nil {
+		volumeSize, err := parseQuantity(ts.VolumeSize, "tshark volume size", spec.Name)
+		if err != nil {
+			return err
+		}
+		uploadInterval, err := ts.uploadInterval(spec.Name)
+		if err != nil {
+			return err
+		}
+		sc := &tshark.Tshark{
+			Image:          ts.Image,
+			VolumeSize:     volumeSize,
+			S3AccessKey:    ts.S3AccessKey,
+			S3SecretKey:    ts.S3SecretKey,
+			S3Region:       ts.S3Region,
+			S3Bucket:       ts.S3Bucket,
+			CreateBucket:   ts.CreateBucket,
+			S3KeyPrefix:    ts.S3KeyPrefix,
+			S3Endpoint:     ts.S3Endpoint,
+			UploadInterval: uploadInterval,
+		}
+		if err := inst.Sidecars().Add(ctx, sc); err != nil {
+			return ErrAddingSidecar.WithParams("tshark", inst.Name()).Wrap(err)
+		}
+	}
+	return nil
+}
+
+func newObsy(spec *ObsySpec) (*observability.Obsy, error) {
+	o := observability.New()
+	if spec.OtelCollectorVersion != "" {
+		if err := o.SetOtelCollectorVersion(spec.OtelCollectorVersion); err != nil {
+			return nil, err
+		}
+		o.SetImage(fmt.Sprintf(observability.DefaultImage, spec.OtelCollectorVersion))
+	}
+	if spec.Image != "" {
+		o.SetImage(spec.Image)
+	}
+	if spec.OtlpPort != 0 {
+		if err := o.SetOtelEndpoint(spec.OtlpPort); err != nil {
+			return nil, err
+		}
+	}
+	if p := spec.Prometheus; p != nil {
+		if err := o.SetPrometheusEndpoint(p.Port, p.JobName, p.ScrapeInterval); err != nil {
+			return nil, err
+		}
+	}
+	if j := spec.Jaeger; j != nil {
+		if err := o.SetJaegerEndpoint(j.GrpcPort, j.ThriftCompactPort, j.ThriftHttpPort); err != nil {
+			return nil, err
+		}
+	}
+
+	exp := spec.Exporters
+	if exp.Otlp != nil {
+		if err := o.SetOtlpExporter(exp.Otlp.Endpoint, exp.Otlp.Username, exp.Otlp.Password); err != nil {
+			return nil, err
+		}
+	}
+	if exp.Jaeger != "" {
+		if err := o.SetJaegerExporter(exp.Jaeger); err != nil {
+			return nil, err
+		}
+	}
+	if exp.Prometheus != "" {
+		if err := o.SetPrometheusExporter(exp.Prometheus); err != nil {
+			return nil, err
+		}
+	}
+	if exp.PrometheusRemoteWrite != "" {
+		if err := o.SetPrometheusRemoteWriteExporter(exp.PrometheusRemoteWrite); err != nil {
+			return nil, err
+		}
+	}
+	if exp.LoggingLevel != "" {
+		if err := o.SetLoggingExporter(exp.LoggingLevel); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// sourcePath resolves the source of a file relative to the directory of the topology file
+func (t *Topology) sourcePath(src string) string {
+	if filepath.IsAbs(src) || t.baseDir == "" {
+		return src
+	}
+	return filepath.Join(t.baseDir, src)
+}
+
+// options returns the options of the volume
+func (v VolumeSpec) options() []instance.VolumeOption {
+	var opts []instance.VolumeOption
+	if v.StorageClass != "" {
+		opts = append(opts, instance.WithStorageClass(v.StorageClass))
+	}
+	if v.AccessMode != "" {
+		opts = append(opts, instance.WithAccessMode(v1.PersistentVolumeAccessMode(v.AccessMode)))
+	}
+	if v.VolumeMode != "" {
+		opts = append(opts, instance.WithVolumeMode(v1.PersistentVolumeMode(v.VolumeMode)))
+	}
+	switch {
+	case v.Tmpfs:
+		opts = append(opts, instance.WithTmpfs())
+	case v.EmptyDir:
+		opts = append(opts, instance.WithEmptyDir())
+	}
+	return opts
+}
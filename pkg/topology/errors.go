@@ -0,0 +1,43 @@
+package topology
+
+import (
+	"github.com/celestiaorg/knuu/pkg/errors"
+)
+
+type Error = errors.Error
+
+var (
+	ErrReadingTopologyFile        = errors.New("ReadingTopologyFile", "error reading topology file '%s'")
+	ErrParsingTopology            = errors.New("ParsingTopology", "error parsing topology")
+	ErrTopologyHasNoInstances     = errors.New("TopologyHasNoInstances", "topology has no instances")
+	ErrInstanceNameRequired       = errors.New("InstanceNameRequired", "name is required for instance at index %d")
+	ErrDuplicateInstanceName      = errors.New("DuplicateInstanceName", "instance '%s' is defined more than once")
+	ErrInstanceImageRequired      = errors.New("InstanceImageRequired", "image is required for instance '%s'")
+	ErrClonesNegative             = errors.New("ClonesNegative", "clones of instance '%s' must not be negative")
+	ErrReplicasNegative           = errors.New("ReplicasNegative", "replicas of instance '%s' must not be negative")
+	ErrInvalidWorkload            = errors.New("InvalidWorkload", "invalid workload '%s' for instance '%s'")
+	ErrInvalidQuantity            = errors.New("InvalidQuantity", "invalid %s '%s' for instance '%s'")
+	ErrInvalidDuration            = errors.New("InvalidDuration", "invalid %s '%s' for instance '%s'")
+	ErrInstanceNameCollision      = errors.New("InstanceNameCollision", "instance name '%s' is used by the instances of both '%s' and '%s'")
+	ErrUnknownDependency          = errors.New("UnknownDependency", "instance '%s' depends on unknown instance '%s'")
+	ErrDependencyCycle            = errors.New("DependencyCycle", "dependency cycle detected between instances %v")
+	ErrCreatingInstance           = errors.New("CreatingInstance", "error creating instance '%s'")
+	ErrSettingImage               = errors.New("SettingImage", "error setting image for instance '%s'")
+	ErrSettingStartCommand        = errors.New("SettingStartCommand", "error setting start command for instance '%s'")
+	ErrSettingArgs                = errors.New("SettingArgs", "error setting args for instance '%s'")
+	ErrCommittingInstance         = errors.New("CommittingInstance", "error committing instance '%s'")
+	ErrSettingEnvironmentVariable = errors.New("SettingEnvironmentVariable", "error setting environment variable '%s' for instance '%s'")
+	ErrAddingFile                 = errors.New("AddingFile", "error adding file '%s' to instance '%s'")
+	ErrAddingFolder               = errors.New("AddingFolder", "error adding folder '%s' to instance '%s'")
+	ErrAddingVolume               = errors.New("AddingVolume", "error adding volume '%s' to instance '%s'")
+	ErrAddingPort                 = errors.New("AddingPort", "error adding port '%d' to instance '%s'")
+	ErrSettingResources           = errors.New("SettingResources", "error setting resources for instance '%s'")
+	ErrSettingWorkload            = errors.New("SettingWorkload", "error setting workload for instance '%s'")
+	ErrSettingSecurity            = errors.New("SettingSecurity", "error setting security options for instance '%s'")
+	ErrAddingSidecar              = errors.New("AddingSidecar", "error adding sidecar '%s' to instance '%s'")
+	ErrConfiguringSidecar         = errors.New("ConfiguringSidecar", "error configuring sidecar '%s' of instance '%s'")
+	ErrCloningInstance            = errors.New("CloningInstance", "error cloning instance '%s'")
+	ErrStartingTopology           = errors.New("StartingTopology", "error starting topology")
+	ErrWaitingForInstance         = errors.New("WaitingForInstance", "error waiting for instance '%s' to be running")
+	ErrDestroyingInstance         = errors.New("DestroyingInstance", "error destroying instance '%s'")
+)
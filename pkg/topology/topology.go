@@ -0,0 +1,385 @@
+// Package topology loads declarative descriptions of test networks and turns them into knuu instances.
+//
+// A topology file is written in YAML (or JSON, which is a subset of YAML).
+// Scenarios written in CUE can be loaded by exporting them first, e.g. `cue export --out yaml scenario.cue`.
+//
+//	instances:
+//	  - name: validator
+//	    image: ghcr.io/celestiaorg/celestia-app:v1.0.0
+//	    args: ["start", "--home", "/home/celestia"]
+//	    env:
+//	      CHAIN_ID: test
+//	    files:
+//	      - source: config/genesis.json
+//	        dest: /home/celestia/config/genesis.json
+//	        chown: "10001:10001"
+//	    volumes:
+//	      - path: /home/celestia
+//	        size: 1Gi
+//	        owner: 10001
+//	        storageClass: local-ssd
+//	    ports:
+//	      tcp: [26656, 26657]
+//	    resources:
+//	      memoryRequest: 1Gi
+//	      cpuRequest: 500m
+//	    clones: 4
+//	    sidecars:
+//	      netshaper: {}
+//	  - name: bridge
+//	    image: ghcr.io/celestiaorg/celestia-node:v0.13.0
+//	    dependsOn: [validator]
+package topology
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+)
+
+// Topology describes a set of instances and how they depend on each other
+type Topology struct {
+	Instances []InstanceSpec `yaml:"instances"`
+
+	// baseDir is used to resolve relative file sources, it is the directory of the topology file
+	baseDir string
+}
+
+// InstanceSpec describes an instance and the sidecars that run next to it
+type InstanceSpec struct {
+	Name      string            `yaml:"name"`
+	Image     string            `yaml:"image"`
+	Command   []string          `yaml:"command,omitempty"`
+	Args      []string          `yaml:"args,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty"`
+	Files     []FileSpec        `yaml:"files,omitempty"`
+	Folders   []FileSpec        `yaml:"folders,omitempty"`
+	Volumes   []VolumeSpec      `yaml:"volumes,omitempty"`
+	Ports     PortsSpec         `yaml:"ports,omitempty"`
+	Resources ResourcesSpec     `yaml:"resources,omitempty"`
+	Security  SecuritySpec      `yaml:"security,omitempty"`
+	Sidecars  SidecarsSpec      `yaml:"sidecars,omitempty"`
+	// Workload is the kind of kubernetes workload: ReplicaSet (default), StatefulSet or Job
+	Workload string `yaml:"workload,omitempty"`
+	// Replicas is the number of pods of each instance
+	Replicas int32 `yaml:"replicas,omitempty"`
+	// Clones is the number of instances created from this spec, they are named <name>-<index>
+	// and these names must not be used by the instances of the other specs
+	// If it is 0 or 1, a single instance named <name> is created
+	Clones int `yaml:"clones,omitempty"`
+	// DependsOn lists the instances that must be running before this one is started
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+}
+
+type FileSpec struct {
+	Source string `yaml:"source"`
+	Dest   string `yaml:"dest"`
+	Chown  string `yaml:"chown,omitempty"`
+}
+
+type VolumeSpec struct {
+	Path  string `yaml:"path"`
+	Size  string `yaml:"size"`
+	Owner int64  `yaml:"owner,omitempty"`
+	// StorageClass is the StorageClass of the volume, the default class of the cluster if empty
+	StorageClass string `yaml:"storageClass,omitempty"`
+	// AccessMode is the access mode of the volume: ReadWriteOnce (default), ReadOnlyMany, ReadWriteMany or ReadWriteOncePod
+	AccessMode string `yaml:"accessMode,omitempty"`
+	// VolumeMode is the volume mode of the volume: Filesystem (default) or Block
+	VolumeMode string `yaml:"volumeMode,omitempty"`
+	// EmptyDir backs the volume by an emptyDir limited to its size, for scratch data
+	EmptyDir bool `yaml:"emptyDir,omitempty"`
+	// Tmpfs backs the volume by a memory-backed emptyDir limited to its size
+	Tmpfs bool `yaml:"tmpfs,omitempty"`
+}
+
+type PortsSpec struct {
+	TCP []int `yaml:"tcp,omitempty"`
+	UDP []int `yaml:"udp,omitempty"`
+}
+
+type ResourcesSpec struct {
+	MemoryRequest string `yaml:"memoryRequest,omitempty"`
+	// MemoryLimit defaults to MemoryRequest and the other way around
+	MemoryLimit string `yaml:"memoryLimit,omitempty"`
+	CPURequest  string `yaml:"cpuRequest,omitempty"`
+}
+
+type SecuritySpec struct {
+	Privileged   bool     `yaml:"privileged,omitempty"`
+	Capabilities []string `yaml:"capabilities,omitempty"`
+}
+
+// SidecarsSpec enables the sidecars shipped with knuu, a nil entry means the sidecar is not added
+type SidecarsSpec struct {
+	NetShaper *NetShaperSpec `yaml:"netshaper,omitempty"`
+	Obsy      *ObsySpec      `yaml:"obsy,omitempty"`
+	Tshark    *TsharkSpec    `yaml:"tshark,omitempty"`
+}
+
+type NetShaperSpec struct {
+	Image            string `yaml:"image,omitempty"`
+	Port             int    `yaml:"port,omitempty"`
+	NetworkInterface string `yaml:"networkInterface,omitempty"`
+}
+
+type ObsySpec struct {
+	Image                string `yaml:"image,omitempty"`
+	OtelCollectorVersion string `yaml:"otelCollectorVersion,omitempty"`
+	OtlpPort             int    `yaml:"otlpPort,omitempty"`
+	Prometheus           *struct {
+		Port           int    `yaml:"port"`
+		JobName        string `yaml:"jobName"`
+		ScrapeInterval string `yaml:"scrapeInterval"`
+	} `yaml:"prometheus,omitempty"`
+	Jaeger *struct {
+		GrpcPort          int `yaml:"grpcPort"`
+		ThriftCompactPort int `yaml:"thriftCompactPort"`
+		ThriftHttpPort    int `yaml:"thriftHttpPort"`
+	} `yaml:"jaeger,omitempty"`
+	Exporters struct {
+		Otlp *struct {
+			Endpoint string `yaml:"endpoint"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"otlp,omitempty"`
+		Jaeger                string `yaml:"jaeger,omitempty"`
+		Prometheus            string `yaml:"prometheus,omitempty"`
+		PrometheusRemoteWrite string `yaml:"prometheusRemoteWrite,omitempty"`
+		LoggingLevel          string `yaml:"loggingLevel,omitempty"`
+	} `yaml:"exporters,omitempty"`
+}
+
+type TsharkSpec struct {
+	Image          string `yaml:"image,omitempty"`
+	VolumeSize     string `yaml:"volumeSize"`
+	S3AccessKey    string `yaml:"s3AccessKey"`
+	S3SecretKey    string `yaml:"s3SecretKey"`
+	S3Region       string `yaml:"s3Region"`
+	S3Bucket       string `yaml:"s3Bucket"`
+	CreateBucket   bool   `yaml:"createBucket,omitempty"`
+	S3KeyPrefix    string `yaml:"s3KeyPrefix,omitempty"`
+	S3Endpoint     string `yaml:"s3Endpoint,omitempty"`
+	UploadInterval string `yaml:"uploadInterval,omitempty"`
+}
+
+// Load reads and validates the topology file at the given path
+// Relative file sources are resolved against the directory of the topology file
+func Load(path string) (*Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ErrReadingTopologyFile.WithParams(path).Wrap(err)
+	}
+
+	t, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	t.baseDir = filepath.Dir(path)
+	return t, nil
+}
+
+// Parse parses and validates a topology
+func Parse(data []byte) (*Topology, error) {
+	t := &Topology{}
+	if err := yaml.UnmarshalStrict(data, t); err != nil {
+		return nil, ErrParsingTopology.Wrap(err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Validate checks that the topology is consistent without creating any instance
+func (t *Topology) Validate() error {
+	if len(t.Instances) == 0 {
+		return ErrTopologyHasNoInstances
+	}
+
+	names := make(map[string]bool, len(t.Instances))
+	for idx, spec := range t.Instances {
+		if spec.Name == "" {
+			return ErrInstanceNameRequired.WithParams(idx)
+		}
+		if names[spec.Name] {
+			return ErrDuplicateInstanceName.WithParams(spec.Name)
+		}
+		names[spec.Name] = true
+
+		if err := spec.validate(); err != nil {
+			return err
+		}
+	}
+
+	// the clones are named <name>-<index>, which must not clash with the instances of the other specs
+	instanceNames := make(map[string]string)
+	for _, spec := range t.Instances {
+		for _, name := range spec.instanceNames() {
+			if other, ok := instanceNames[name]; ok {
+				return ErrInstanceNameCollision.WithParams(name, other, spec.Name)
+			}
+			instanceNames[name] = spec.Name
+		}
+	}
+
+	for _, spec := range t.Instances {
+		for _, dep := range spec.DependsOn {
+			if !names[dep] {
+				return ErrUnknownDependency.WithParams(spec.Name, dep)
+			}
+		}
+	}
+
+	_, err := t.StartOrder()
+	return err
+}
+
+// StartOrder groups the names of the instance specs in the order they must be started
+// All the specs of a group only depend on specs of the previous groups, so they can be started together
+func (t *Topology) StartOrder() ([][]string, error) {
+	pending := make(map[string][]string, len(t.Instances))
+	for _, spec := range t.Instances {
+		pending[spec.Name] = spec.DependsOn
+	}
+
+	var (
+		order   [][]string
+		started = make(map[string]bool, len(t.Instances))
+	)
+	for len(pending) > 0 {
+		var group []string
+		// iterate over the specs to keep the order of the file within a group
+		for _, spec := range t.Instances {
+			deps, ok := pending[spec.Name]
+			if !ok {
+				continue
+			}
+			if allStarted(deps, started) {
+				group = append(group, spec.Name)
+			}
+		}
+
+		if len(group) == 0 {
+			cycle := make([]string, 0, len(pending))
+			for name := range pending {
+				cycle = append(cycle, name)
+			}
+			sort.Strings(cycle)
+			return nil, ErrDependencyCycle.WithParams(cycle)
+		}
+
+		for _, name := range group {
+			started[name] = true
+			delete(pending, name)
+		}
+		order = append(order, group)
+	}
+	return order, nil
+}
+
+func (s *InstanceSpec) validate() error {
+	if s.Image == "" {
+		return ErrInstanceImageRequired.WithParams(s.Name)
+	}
+	if s.Clones < 0 {
+		return ErrClonesNegative.WithParams(s.Name)
+	}
+	if s.Replicas < 0 {
+		return ErrReplicasNegative.WithParams(s.Name)
+	}
+	if _, err := s.workloadType(); err != nil {
+		return err
+	}
+
+	for _, v := range s.Volumes {
+		if _, err := parseQuantity(v.Size, "volume size", s.Name); err != nil {
+			return err
+		}
+	}
+	quantities := map[string]string{
+		"memory request": s.Resources.MemoryRequest,
+		"memory limit":   s.Resources.MemoryLimit,
+		"cpu request":    s.Resources.CPURequest,
+	}
+	for kind, value := range quantities {
+		if value == "" {
+			continue
+		}
+		if _, err := parseQuantity(value, kind, s.Name); err != nil {
+			return err
+		}
+	}
+	if ts := s.Sidecars.Tshark; ts != nil {
+		if _, err := parseQuantity(ts.VolumeSize, "tshark volume size", s.Name); err != nil {
+			return err
+		}
+		if _, err := ts.uploadInterval(s.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *InstanceSpec) workloadType() (instance.WorkloadType, error) {
+	if s.Workload == "" {
+		return instance.ReplicaSetWorkload, nil
+	}
+	for _, wt := range []instance.WorkloadType{
+		instance.ReplicaSetWorkload,
+		instance.StatefulSetWorkload,
+		instance.JobWorkload,
+	} {
+		if wt.String() == s.Workload {
+			return wt, nil
+		}
+	}
+	return 0, ErrInvalidWorkload.WithParams(s.Workload, s.Name)
+}
+
+// instanceNames returns the names of the instances created from the spec
+func (s *InstanceSpec) instanceNames() []string {
+	if s.Clones <= 1 {
+		return []string{s.Name}
+	}
+	names := make([]string, s.Clones)
+	for idx := range names {
+		names[idx] = s.Name + "-" + strconv.Itoa(idx)
+	}
+	return names
+}
+
+func parseQuantity(value, kind, instanceName string) (resource.Quantity, error) {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return resource.Quantity{}, ErrInvalidQuantity.WithParams(kind, value, instanceName).Wrap(err)
+	}
+	return q, nil
+}
+
+func allStarted(deps []string, started map[string]bool) bool {
+	for _, dep := range deps {
+		if !started[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+func (ts *TsharkSpec) uploadInterval(instanceName string) (time.Duration, error) {
+	if ts.UploadInterval == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(ts.UploadInterval)
+	if err != nil {
+		return 0, ErrInvalidDuration.WithParams("tshark upload interval", ts.UploadInterval, instanceName).Wrap(err)
+	}
+	return d, nil
+}
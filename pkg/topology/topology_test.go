@@ -0,0 +1,179 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	discfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/celestiaorg/knuu/pkg/instance"
+	"github.com/celestiaorg/knuu/pkg/k8s"
+	"github.com/celestiaorg/knuu/pkg/system"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr error
+	}{
+		{
+			name: "valid topology",
+			data: `
+instances:
+  - name: validator
+    image: alpine:latest
+    clones: 2
+  - name: bridge
+    image: alpine:latest
+    dependsOn: [validator]
+`,
+		},
+		{
+			name:        "no instances",
+			data:        `instances: []`,
+			expectedErr: ErrTopologyHasNoInstances,
+		},
+		{
+			name:        "unknown field",
+			data:        "instances:\n  - name: a\n    image: alpine\n    imagee: alpine\n",
+			expectedErr: ErrParsingTopology,
+		},
+		{
+			name:        "missing name",
+			data:        "instances:\n  - image: alpine\n",
+			expectedErr: ErrInstanceNameRequired,
+		},
+		{
+			name:        "missing image",
+			data:        "instances:\n  - name: a\n",
+			expectedErr: ErrInstanceImageRequired,
+		},
+		{
+			name:        "duplicate name",
+			data:        "instances:\n  - name: a\n    image: alpine\n  - name: a\n    image: alpine\n",
+			expectedErr: ErrDuplicateInstanceName,
+		},
+		{
+			name:        "clone name collision",
+			data:        "instances:\n  - name: val\n    image: alpine\n    clones: 2\n  - name: val-1\n    image: alpine\n",
+			expectedErr: ErrInstanceNameCollision,
+		},
+		{
+			name:        "invalid workload",
+			data:        "instances:\n  - name: a\n    image: alpine\n    workload: Deployment\n",
+			expectedErr: ErrInvalidWorkload,
+		},
+		{
+			name:        "invalid volume size",
+			data:        "instances:\n  - name: a\n    image: alpine\n    volumes:\n      - path: /data\n        size: lots\n",
+			expectedErr: ErrInvalidQuantity,
+		},
+		{
+			name:        "unknown dependency",
+			data:        "instances:\n  - name: a\n    image: alpine\n    dependsOn: [b]\n",
+			expectedErr: ErrUnknownDependency,
+		},
+		{
+			name:        "dependency cycle",
+			data:        "instances:\n  - name: a\n    image: alpine\n    dependsOn: [b]\n  - name: b\n    image: alpine\n    dependsOn: [a]\n",
+			expectedErr: ErrDependencyCycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStartOrder(t *testing.T) {
+	topology := &Topology{
+		Instances: []InstanceSpec{
+			{Name: "bridge", Image: "alpine", DependsOn: []string{"validator"}},
+			{Name: "validator", Image: "alpine"},
+			{Name: "light", Image: "alpine", DependsOn: []string{"bridge", "validator"}},
+			{Name: "seed", Image: "alpine"},
+		},
+	}
+
+	order, err := topology.StartOrder()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"validator", "seed"}, {"bridge"}, {"light"}}, order)
+}
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "genesis.json"), []byte("{}"), 0644))
+	topologyFile := filepath.Join(dir, "topology.yaml")
+	require.NoError(t, os.WriteFile(topologyFile, []byte(`
+instances:
+  - name: validator
+    image: alpine:latest
+    command: ["sleep", "infinity"]
+    env:
+      CHAIN_ID: test
+    files:
+      - source: genesis.json
+        dest: /home/celestia/genesis.json
+        chown: "0:0"
+    volumes:
+      - path: /home/celestia
+        size: 1Gi
+    ports:
+      tcp: [26656]
+    resources:
+      memoryRequest: 64Mi
+      cpuRequest: 100m
+    workload: StatefulSet
+    clones: 3
+  - name: bridge
+    image: alpine:latest
+    dependsOn: [validator]
+`), 0644))
+
+	topology, err := Load(topologyFile)
+	require.NoError(t, err)
+
+	k8sClient, err := k8s.NewClientCustom(ctx, fake.NewSimpleClientset(), &discfake.FakeDiscovery{Fake: &k8stesting.Fake{}}, nil, "test", logrus.New())
+	require.NoError(t, err)
+	sysDeps := &system.SystemDependencies{
+		K8sClient: k8sClient,
+		Logger:    logrus.New(),
+		Scope:     "test",
+		StartTime: time.Now().UTC().Format("20060102T150405Z"),
+	}
+
+	deployment, err := topology.Build(ctx, sysDeps)
+	require.NoError(t, err)
+
+	validators := deployment.Instances("validator")
+	require.Len(t, validators, 3)
+	for idx, v := range validators {
+		assert.Equal(t, fmt.Sprintf("validator-%d", idx), v.Name())
+		assert.Equal(t, instance.StateCommitted, v.State())
+		assert.Equal(t, instance.StatefulSetWorkload, v.Execution().WorkloadType())
+	}
+
+	bridges := deployment.Instances("bridge")
+	require.Len(t, bridges, 1)
+	assert.Equal(t, "bridge", bridges[0].Name())
+	assert.Len(t, deployment.All(), 4)
+}